@@ -0,0 +1,73 @@
+// dfacheck - validasi file spesifikasi DFA (format dfa.txt) sebelum dipakai lexer
+//
+// Cara pakai: go run ./cmd/dfacheck [--json] [--strict] <file_dfa.txt>...
+// Exit code 1 jika ada error (atau warning dengan --strict), 2 jika file tidak bisa dibaca
+package main
+
+import (
+	"compiler/diagnostic"
+	"compiler/milestone1"
+	"fmt"
+	"os"
+)
+
+func main() {
+	jsonOutput := false
+	strict := false
+	var files []string
+	for _, arg := range os.Args[1:] {
+		switch arg {
+		case "--json":
+			jsonOutput = true
+		case "--strict":
+			strict = true
+		default:
+			files = append(files, arg)
+		}
+	}
+	if len(files) == 0 {
+		fmt.Println("Cara pakai: go run ./cmd/dfacheck [--json] [--strict] <file_dfa.txt>...")
+		os.Exit(2)
+	}
+
+	exitCode := 0
+	for _, file := range files {
+		diagnostics, err := checkFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			exitCode = 2
+			continue
+		}
+
+		if jsonOutput {
+			diagnostic.RenderJSON(os.Stdout, file, diagnostics)
+		} else {
+			diagnostic.RenderText(os.Stdout, file, diagnostics)
+			errors := diagnostic.Count(diagnostics, diagnostic.Error)
+			warnings := diagnostic.Count(diagnostics, diagnostic.Warning)
+			fmt.Printf("%s: %d error(s), %d warning(s)\n", file, errors, warnings)
+		}
+
+		failed := diagnostic.Count(diagnostics, diagnostic.Error) > 0 ||
+			(strict && diagnostic.Count(diagnostics, diagnostic.Warning) > 0)
+		if failed && exitCode == 0 {
+			exitCode = 1
+		}
+	}
+	os.Exit(exitCode)
+}
+
+// Load dan validasi satu file DFA
+func checkFile(file string) ([]diagnostic.Diagnostic, error) {
+	reader, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("error opening DFA file: %v", err)
+	}
+	defer reader.Close()
+
+	dfa, err := milestone1.LoadDFA(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error reading DFA file %s: %v", file, err)
+	}
+	return dfa.Validate(), nil
+}
@@ -0,0 +1,58 @@
+// dfagen - bangkitkan file DFA (format dfa.txt) dari definisi token berbentuk regex
+//
+// Cara pakai: go run ./cmd/dfagen <file_definisi_token> [file_output]
+// Tanpa file_output, hasil ditulis ke stdout
+package main
+
+import (
+	"compiler/diagnostic"
+	"compiler/milestone1"
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println("Cara pakai: go run ./cmd/dfagen <file_definisi_token> [file_output]")
+		os.Exit(2)
+	}
+
+	reader, err := os.Open(os.Args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: error opening token definition file: %v\n", err)
+		os.Exit(2)
+	}
+	definitions, err := milestone1.LoadTokenDefinitions(reader)
+	reader.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+
+	dfa, err := milestone1.GenerateDFA(definitions)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+	// DFA hasil generator seharusnya selalu valid; tetap dicek supaya bug generator ketahuan
+	if problems := dfa.Validate(); diagnostic.Count(problems, diagnostic.Error) > 0 {
+		diagnostic.RenderText(os.Stderr, "", problems)
+		os.Exit(1)
+	}
+
+	var writer io.Writer = os.Stdout
+	if len(os.Args) > 2 {
+		file, err := os.Create(os.Args[2])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: error creating output file: %v\n", err)
+			os.Exit(2)
+		}
+		defer file.Close()
+		writer = file
+	}
+	if err := milestone1.WriteDFA(writer, dfa); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: error writing DFA: %v\n", err)
+		os.Exit(1)
+	}
+}
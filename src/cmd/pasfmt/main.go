@@ -0,0 +1,126 @@
+// pasfmt - rapikan source Pascal-S ke format kanonik (indentasi, satu statement per baris,
+// keyword huruf kecil, spasi operator seragam). Komentar tetap dipertahankan.
+//
+// Cara pakai: go run ./cmd/pasfmt [--check] [--write] [--dialect=indonesian|english|<file>] <file_dfa.txt> <file_program.txt>...
+// Tanpa opsi hasil format ditulis ke stdout; --write menimpa file, --check hanya mendaftar file
+// yang belum rapi. Exit code 1 jika ada file belum rapi (--check) atau error sintaks, 2 jika file tidak bisa dibaca
+package main
+
+import (
+	"bytes"
+	"compiler/diagnostic"
+	"compiler/formatter"
+	"compiler/milestone1"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const usage = "Cara pakai: go run ./cmd/pasfmt [--check] [--write] [--dialect=indonesian|english|<file>] <file_dfa.txt> <file_program.txt>..."
+
+func main() {
+	check := false
+	write := false
+	dialectSpec := "indonesian"
+	var args []string
+	for _, arg := range os.Args[1:] {
+		switch {
+		case arg == "--check":
+			check = true
+		case arg == "--write":
+			write = true
+		case strings.HasPrefix(arg, "--dialect="):
+			dialectSpec = strings.TrimPrefix(arg, "--dialect=")
+		default:
+			args = append(args, arg)
+		}
+	}
+	if len(args) < 2 || (check && write) {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	dfa, err := loadDFA(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(2)
+	}
+	dialect, err := loadDialect(dialectSpec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: error loading dialect %s: %v\n", dialectSpec, err)
+		os.Exit(2)
+	}
+
+	exitCode := 0
+	fail := func(code int) {
+		if code > exitCode {
+			exitCode = code
+		}
+	}
+	for _, file := range args[1:] {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: error reading source file: %v\n", err)
+			fail(2)
+			continue
+		}
+
+		formatted, diagnostics, err := formatter.FormatSource(source, *dfa, dialect)
+		if len(diagnostics) > 0 {
+			diagnostic.RenderText(os.Stderr, file, diagnostics)
+			fail(1)
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %s: %v\n", file, err)
+			fail(1)
+			continue
+		}
+
+		switch {
+		case check:
+			if !bytes.Equal(source, formatted) {
+				fmt.Println(file)
+				fail(1)
+			}
+		case write:
+			if bytes.Equal(source, formatted) {
+				continue
+			}
+			if err := os.WriteFile(file, formatted, 0644); err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR: error writing %s: %v\n", file, err)
+				fail(2)
+			}
+		default:
+			os.Stdout.Write(formatted)
+		}
+	}
+	os.Exit(exitCode)
+}
+
+func loadDFA(file string) (*milestone1.DFA, error) {
+	reader, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("error opening DFA file: %v", err)
+	}
+	defer reader.Close()
+
+	dfa, err := milestone1.LoadDFA(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error reading DFA file %s: %v", file, err)
+	}
+	return dfa, nil
+}
+
+// Dialek bawaan berdasarkan nama, selain itu dibaca sebagai file pemetaan keyword
+func loadDialect(spec string) (*milestone1.Dialect, error) {
+	if dialect, ok := milestone1.DialectByName(spec); ok {
+		return dialect, nil
+	}
+	file, err := os.Open(spec)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return milestone1.LoadDialect(file, spec)
+}
@@ -0,0 +1,57 @@
+package diagnostic
+
+// Code - stable identifier of a kind of diagnostic
+// E00xx lexical, E005x syntax, E01xx names/scopes, E02xx types, E03xx declarations,
+// E04xx translation to standard Pascal, E09xx DFA specification files, Wxxxx warnings
+type Code string
+
+const (
+	// Lexical
+	InvalidToken        Code = "E0001"
+	UnterminatedString  Code = "E0002"
+	UnterminatedComment Code = "E0003"
+
+	// Syntax
+	ExpectedToken   Code = "E0050"
+	UnexpectedToken Code = "E0051"
+
+	// Names and scopes
+	MalformedTree   Code = "E0100"
+	DuplicateDecl   Code = "E0101"
+	UndeclaredIdent Code = "E0102"
+	WrongIdentKind  Code = "E0103"
+
+	// Types
+	TypeMismatch     Code = "E0201"
+	InvalidOperand   Code = "E0202"
+	NonBooleanCond   Code = "E0203"
+	InvalidIndex     Code = "E0204"
+	InvalidField     Code = "E0205"
+	InvalidForLoop   Code = "E0206"
+	ArgumentCount    Code = "E0207"
+	ArgumentMismatch Code = "E0208"
+	InvalidSelector  Code = "E0209"
+	InvalidCaseLabel Code = "E0210"
+	DuplicateLabel   Code = "E0211"
+	OutOfRange       Code = "E0212"
+
+	// Declarations
+	InvalidArrayBound Code = "E0301"
+	NotConstant       Code = "E0302"
+	InvalidRange      Code = "E0303"
+
+	// Translation to standard Pascal (translator)
+	NotStandardPascal Code = "E0401"
+
+	// DFA specification (milestone1.DFA.Validate)
+	MissingStartState   Code = "E0901"
+	NondeterministicDFA Code = "E0902"
+	UndefinedFinalState Code = "E0903"
+	MalformedDFALine    Code = "E0904"
+
+	// Warnings
+	MissingResult       Code = "W0101"
+	DuplicateTransition Code = "W0901"
+	UnreachableState    Code = "W0902"
+	DeadState           Code = "W0903"
+)
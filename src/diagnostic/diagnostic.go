@@ -0,0 +1,147 @@
+package diagnostic
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Severity - how serious a diagnostic is
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+	Info
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+func (s Severity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// Position - location in the source (line and column start at 1, 0 means unknown)
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Span - source range; End is the position just after the last character
+type Span struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Point span for a single position (used when the end is unknown)
+func At(pos Position) Span {
+	return Span{Start: pos, End: pos}
+}
+
+// Span covering length characters on one line
+func Range(pos Position, length int) Span {
+	return Span{Start: pos, End: Position{Line: pos.Line, Column: pos.Column + length}}
+}
+
+// Note - additional information attached to a diagnostic
+type Note struct {
+	Message string `json:"message"`
+	Span    *Span  `json:"span,omitempty"`
+}
+
+// Diagnostic - error or warning reported by the lexer, parser or semantic analyzer
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Code     Code     `json:"code"`
+	Message  string   `json:"message"`
+	Span     Span     `json:"span"`
+	Notes    []Note   `json:"notes,omitempty"`
+}
+
+// Create a new error diagnostic
+func Errorf(code Code, span Span, format string, args ...interface{}) *Diagnostic {
+	return &Diagnostic{Severity: Error, Code: code, Message: fmt.Sprintf(format, args...), Span: span}
+}
+
+// Create a new warning diagnostic
+func Warningf(code Code, span Span, format string, args ...interface{}) *Diagnostic {
+	return &Diagnostic{Severity: Warning, Code: code, Message: fmt.Sprintf(format, args...), Span: span}
+}
+
+// WithNote attaches a note and returns the diagnostic (for chaining)
+func (d *Diagnostic) WithNote(span *Span, format string, args ...interface{}) *Diagnostic {
+	d.Notes = append(d.Notes, Note{Message: fmt.Sprintf(format, args...), Span: span})
+	return d
+}
+
+// Single-line text form: "line:col: severity[code]: message"
+func (d Diagnostic) String() string {
+	var sb strings.Builder
+	if d.Span.Start.Line > 0 {
+		sb.WriteString(d.Span.Start.String() + ": ")
+	}
+	fmt.Fprintf(&sb, "%s[%s]: %s", d.Severity, d.Code, d.Message)
+	return sb.String()
+}
+
+// Diagnostic also satisfies error so it can travel through error returns
+func (d *Diagnostic) Error() string {
+	return d.String()
+}
+
+// RenderText writes diagnostics in "file:line:col: severity[code]: message" form, notes indented below
+func RenderText(writer io.Writer, file string, diagnostics []Diagnostic) {
+	for _, d := range diagnostics {
+		if file != "" && d.Span.Start.Line > 0 {
+			fmt.Fprintf(writer, "%s:", file)
+		} else if file != "" {
+			fmt.Fprintf(writer, "%s: ", file)
+		}
+		fmt.Fprintln(writer, d.String())
+		for _, note := range d.Notes {
+			if note.Span != nil && note.Span.Start.Line > 0 {
+				fmt.Fprintf(writer, "    %s: note: %s\n", note.Span.Start, note.Message)
+			} else {
+				fmt.Fprintf(writer, "    note: %s\n", note.Message)
+			}
+		}
+	}
+}
+
+// RenderJSON writes diagnostics as a JSON object {"file": ..., "diagnostics": [...]}
+func RenderJSON(writer io.Writer, file string, diagnostics []Diagnostic) error {
+	if diagnostics == nil {
+		diagnostics = []Diagnostic{}
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		File        string       `json:"file,omitempty"`
+		Diagnostics []Diagnostic `json:"diagnostics"`
+	}{file, diagnostics})
+}
+
+// Count diagnostics with the given severity
+func Count(diagnostics []Diagnostic, severity Severity) int {
+	count := 0
+	for _, d := range diagnostics {
+		if d.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,86 @@
+package dot
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Minimal Graphviz DOT writer shared by the parse tree, decorated AST and DFA exports.
+// Nodes and edges are written in the order they were added, so the output is stable.
+
+// Graph - directed graph under construction
+type Graph struct {
+	name  string
+	attrs []string
+	nodes []string
+	edges []string
+	next  int
+}
+
+func NewGraph(name string) *Graph {
+	return &Graph{name: name}
+}
+
+// Attr adds a graph-level statement such as `rankdir=LR` or `node [shape=box]`
+func (g *Graph) Attr(statement string) {
+	g.attrs = append(g.attrs, statement)
+}
+
+// Node adds a node with a generated id (n0, n1, ...) and returns the id
+// attrs are extra `key=value` pairs; values must already be valid DOT (use Quote for text)
+func (g *Graph) Node(label string, attrs ...string) string {
+	id := fmt.Sprintf("n%d", g.next)
+	g.next++
+	g.NamedNode(id, label, attrs...)
+	return id
+}
+
+// NamedNode adds a node with a caller-chosen id (quoted if needed)
+func (g *Graph) NamedNode(id, label string, attrs ...string) {
+	list := append([]string{"label=" + Quote(label)}, attrs...)
+	g.nodes = append(g.nodes, fmt.Sprintf("%s [%s];", ID(id), strings.Join(list, ", ")))
+}
+
+// Edge adds from -> to, with a label unless it is empty
+func (g *Graph) Edge(from, to, label string) {
+	edge := fmt.Sprintf("%s -> %s", ID(from), ID(to))
+	if label != "" {
+		edge += " [label=" + Quote(label) + "]"
+	}
+	g.edges = append(g.edges, edge+";")
+}
+
+// Write the graph in DOT syntax
+func (g *Graph) Write(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "digraph %s {\n", ID(g.name))
+	for _, list := range [][]string{g.attrs, g.nodes, g.edges} {
+		for _, line := range list {
+			sb.WriteString("  " + line + "\n")
+		}
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// Quote makes a DOT string literal; newlines become line breaks in the label
+func Quote(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + replacer.Replace(text) + `"`
+}
+
+// ID returns id unchanged if it is a plain DOT identifier, quoted otherwise
+func ID(id string) string {
+	if id == "" {
+		return Quote(id)
+	}
+	for i, c := range id {
+		letter := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		if !letter && !(i > 0 && c >= '0' && c <= '9') {
+			return Quote(id)
+		}
+	}
+	return id
+}
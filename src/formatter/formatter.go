@@ -0,0 +1,463 @@
+package formatter
+
+import (
+	"bytes"
+	"compiler/diagnostic"
+	"compiler/milestone1"
+	"compiler/milestone2"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Pretty-printer for Pascal-S. The parse tree already holds every token, so formatting only
+// decides the whitespace between tokens; comments are kept as trivia and placed by their
+// source position. Canonical layout:
+//   - two spaces of indentation per block level, one statement or declaration per line
+//   - 'mulai' after 'maka'/'lakukan'/'selain_itu' on its own line at the statement's level
+//   - keywords in lower case in the source dialect, identifiers and literals unchanged
+//   - one space around binary operators and ':=', none inside brackets or before ';' ',' ':'
+//   - at most one blank line kept between declarations, statements and own-line comments
+
+const indentUnit = "  "
+
+type printer struct {
+	out      strings.Builder
+	dialect  *milestone1.Dialect
+	comments []milestone1.Token // Comments not emitted yet, in source order
+	level    int
+
+	pendingBreak bool   // Next token starts a new line
+	breakBlank   bool   // That line may be preceded by a blank line kept from the source
+	atStart      bool   // Nothing written yet
+	lastLine     int    // Source line where the last emitted token or comment ends
+	prev         string // Text of the last token on the current line ("" at line start)
+	prevType     milestone1.TokenKind
+	glue         bool // No space before the next token (after a unary sign)
+}
+
+// Format writes tree as canonical Pascal-S; comments are the COMMENT tokens of the same source
+// (see Lexer.KeepComments). Trees with syntax errors cannot be formatted.
+func Format(w io.Writer, tree *milestone2.AbstractSyntaxTree, comments []milestone1.Token, dialect *milestone1.Dialect) error {
+	if tree == nil || hasErrorNode(tree) {
+		return fmt.Errorf("cannot format a program with syntax errors")
+	}
+	p := &printer{dialect: dialect, atStart: true}
+	for _, c := range comments {
+		if c.Kind == milestone1.KindComment {
+			p.comments = append(p.comments, c)
+		}
+	}
+
+	p.program(tree)
+	for len(p.comments) > 0 {
+		p.comment(p.comments[0])
+		p.comments = p.comments[1:]
+	}
+	p.out.WriteByte('\n')
+
+	_, err := io.WriteString(w, p.out.String())
+	return err
+}
+
+// FormatSource lexes, parses and formats one source file
+// Lexical and syntax errors are returned as diagnostics; err is set if the formatter would change
+// the program itself or the result is not stable under formatting again (a formatter bug)
+func FormatSource(src []byte, dfa milestone1.DFA, dialect *milestone1.Dialect) ([]byte, []diagnostic.Diagnostic, error) {
+	formatted, tokens, diagnostics, err := format(src, dfa, dialect)
+	if err != nil || len(diagnostics) > 0 {
+		return nil, diagnostics, err
+	}
+
+	again, formattedTokens, problems, err := format(formatted, dfa, dialect)
+	if err != nil || len(problems) > 0 {
+		return nil, nil, fmt.Errorf("formatted output does not parse")
+	}
+	if !sameTokens(tokens, formattedTokens) {
+		return nil, nil, fmt.Errorf("formatting changed the token sequence")
+	}
+	if !bytes.Equal(formatted, again) {
+		return nil, nil, fmt.Errorf("formatting is not idempotent")
+	}
+	return formatted, nil, nil
+}
+
+func format(src []byte, dfa milestone1.DFA, dialect *milestone1.Dialect) ([]byte, []milestone1.Token, []diagnostic.Diagnostic, error) {
+	lexer := milestone1.NewLexer(dfa)
+	lexer.KeepComments(true)
+	lexer.SetDialect(dialect)
+	tokens, err := lexer.Scan(bytes.NewReader(src))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(lexer.Diagnostics()) > 0 {
+		return nil, nil, lexer.Diagnostics(), nil
+	}
+
+	parser := milestone2.NewParser(tokens)
+	parser.SetDialect(dialect)
+	tree, err := parser.ParseProgram()
+	if err != nil {
+		return nil, nil, parser.Errors(), nil
+	}
+
+	var out bytes.Buffer
+	if err := Format(&out, tree, tokens, dialect); err != nil {
+		return nil, nil, nil, err
+	}
+	return out.Bytes(), tokens, nil, nil
+}
+
+// Same tokens in the same order; keywords compare by kind since their case is normalized
+func sameTokens(a, b []milestone1.Token) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Kind != b[i].Kind || a[i].Keyword != b[i].Keyword {
+			return false
+		}
+		if a[i].Keyword == "" && a[i].Lexeme != b[i].Lexeme {
+			return false
+		}
+	}
+	return true
+}
+
+func hasErrorNode(node *milestone2.AbstractSyntaxTree) bool {
+	if node.Kind == milestone2.NodeError {
+		return true
+	}
+	for _, child := range node.Children {
+		if hasErrorNode(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// --- Layout ---
+
+func (p *printer) program(n *milestone2.AbstractSyntaxTree) {
+	for _, child := range n.Children {
+		switch child.Kind {
+		case milestone2.NodeDeclarationPart:
+			p.declarations(child)
+		case milestone2.NodeCompoundStatement:
+			p.breakLine(true)
+			p.compound(child)
+		default:
+			p.node(child) // header and the final '.'
+		}
+	}
+}
+
+func (p *printer) declarations(n *milestone2.AbstractSyntaxTree) {
+	for _, child := range n.Children {
+		p.breakLine(true)
+		switch child.Kind {
+		case milestone2.NodeConstDeclaration:
+			p.leaf(child.Children[0])
+			p.level++
+			for _, def := range child.Children[1:] {
+				p.breakLine(true)
+				p.node(def)
+			}
+			p.level--
+		case milestone2.NodeTypeDeclaration, milestone2.NodeVarDeclaration:
+			p.leaf(child.Children[0])
+			p.level++
+			p.lines(child.Children[1:])
+			p.level--
+		case milestone2.NodeSubprogramDeclaration:
+			p.subprogram(child)
+		}
+	}
+}
+
+// Children forming ';'-terminated items, one item per line (variable groups, type definitions, fields)
+func (p *printer) lines(children []*milestone2.AbstractSyntaxTree) {
+	start := true
+	for _, child := range children {
+		if start {
+			p.breakLine(true)
+			start = false
+		}
+		p.node(child)
+		if child.IsToken(milestone1.KindSemicolon) {
+			start = true
+		}
+	}
+}
+
+func (p *printer) subprogram(n *milestone2.AbstractSyntaxTree) {
+	for _, child := range n.Children {
+		switch child.Kind {
+		case milestone2.NodeDeclarationPart:
+			// Local declarations and nested subprograms one level inside the header
+			p.level++
+			p.declarations(child)
+			p.level--
+		case milestone2.NodeCompoundStatement:
+			p.breakLine(true)
+			p.compound(child)
+		default:
+			p.node(child) // header tokens and the closing ';'
+		}
+	}
+}
+
+// Expressions, types and other tokens that stay on the current line
+func (p *printer) node(n *milestone2.AbstractSyntaxTree) {
+	if len(n.Children) == 0 {
+		if n.Kind == milestone2.NodeToken {
+			p.leaf(n)
+		}
+		return
+	}
+	switch n.Kind {
+	case milestone2.NodeRecordType:
+		p.leaf(n.Children[0])
+		p.level++
+		p.lines(n.Children[1].Children)
+		p.level--
+		p.breakLine(false)
+		p.leaf(n.Children[2])
+		return
+	case milestone2.NodeSimpleExpression, milestone2.NodeCaseLabel:
+		// Unary sign sticks to its operand
+		if first := n.Children[0]; first.IsText(milestone1.KindArithmeticOperator, "+") || first.IsText(milestone1.KindArithmeticOperator, "-") {
+			p.leaf(first)
+			p.glue = true
+			for _, child := range n.Children[1:] {
+				p.node(child)
+			}
+			return
+		}
+	}
+	for _, child := range n.Children {
+		p.node(child)
+	}
+}
+
+func (p *printer) statement(n *milestone2.AbstractSyntaxTree) {
+	switch n.Kind {
+	case milestone2.NodeCompoundStatement:
+		p.compound(n)
+	case milestone2.NodeIfStatement:
+		for i, child := range n.Children {
+			switch {
+			case i == 3 || i == 5:
+				p.body(child)
+			case i == 4:
+				p.breakLine(false)
+				p.leaf(child)
+			default:
+				p.node(child)
+			}
+		}
+	case milestone2.NodeWhileStatement, milestone2.NodeForStatement:
+		last := len(n.Children) - 1
+		for _, child := range n.Children[:last] {
+			p.node(child)
+		}
+		p.body(n.Children[last])
+	case milestone2.NodeRepeatStatement:
+		p.leaf(n.Children[0])
+		p.statementList(n.Children[1])
+		p.breakLine(false)
+		p.leaf(n.Children[2])
+		p.node(n.Children[3])
+	case milestone2.NodeCaseStatement:
+		p.caseStatement(n)
+	case milestone2.NodeEmptyStatement:
+	default:
+		p.node(n) // assignment, procedure call
+	}
+}
+
+// Statement after maka/lakukan/selain_itu: a compound stays at the same level, others are indented
+func (p *printer) body(n *milestone2.AbstractSyntaxTree) {
+	switch n.Kind {
+	case milestone2.NodeEmptyStatement:
+	case milestone2.NodeCompoundStatement:
+		p.breakLine(false)
+		p.compound(n)
+	default:
+		p.level++
+		p.breakLine(false)
+		p.statement(n)
+		p.level--
+	}
+}
+
+func (p *printer) compound(n *milestone2.AbstractSyntaxTree) {
+	p.leaf(n.Children[0])
+	p.statementList(n.Children[1])
+	p.breakLine(false)
+	p.leaf(n.Children[2])
+}
+
+func (p *printer) statementList(n *milestone2.AbstractSyntaxTree) {
+	p.level++
+	for _, child := range n.Children {
+		if child.IsToken(milestone1.KindSemicolon) {
+			p.leaf(child)
+			continue
+		}
+		p.breakLine(true)
+		p.statement(child)
+	}
+	p.level--
+}
+
+// kasus expr dari, one element per line, selain_itu and selesai at the level of 'kasus'
+func (p *printer) caseStatement(n *milestone2.AbstractSyntaxTree) {
+	for _, child := range n.Children[:3] {
+		p.node(child)
+	}
+	p.level++
+	for _, child := range n.Children[3:] {
+		switch {
+		case child.Kind == milestone2.NodeCaseElement:
+			p.breakLine(true)
+			p.caseElement(child)
+		case child.Kind == milestone2.NodeStatementList:
+			p.defaultBranch(child)
+		case child.IsToken(milestone1.KindKeyword):
+			// selain_itu or the closing selesai
+			p.level--
+			p.breakLine(false)
+			p.leaf(child)
+			p.level++
+		default:
+			p.leaf(child) // ';' between elements
+		}
+	}
+	p.level--
+}
+
+// Statements of selain_itu, one level below 'kasus' like the elements; a compound statement
+// goes one level deeper, at the same indent as after a label
+func (p *printer) defaultBranch(n *milestone2.AbstractSyntaxTree) {
+	for _, child := range n.Children {
+		if child.IsToken(milestone1.KindSemicolon) {
+			p.leaf(child)
+			continue
+		}
+		p.breakLine(true)
+		if child.Kind == milestone2.NodeCompoundStatement {
+			p.level++
+			p.compound(child)
+			p.level--
+			continue
+		}
+		p.statement(child)
+	}
+}
+
+// labels: statement (a compound statement goes on the next lines, one level deeper)
+func (p *printer) caseElement(n *milestone2.AbstractSyntaxTree) {
+	p.node(n.Children[0])
+	p.leaf(n.Children[1])
+	stmt := n.Children[2]
+	if stmt.Kind == milestone2.NodeCompoundStatement {
+		p.level++
+		p.breakLine(false)
+		p.compound(stmt)
+		p.level--
+		return
+	}
+	p.statement(stmt)
+}
+
+// --- Tokens and whitespace ---
+
+func (p *printer) breakLine(blank bool) {
+	p.pendingBreak = true
+	p.breakBlank = blank
+}
+
+// Begin a new output line for something at source line `line`
+func (p *printer) startLine(line int, blank bool) {
+	if !p.atStart {
+		p.out.WriteByte('\n')
+		if blank && line > p.lastLine+1 {
+			p.out.WriteByte('\n')
+		}
+		p.out.WriteString(strings.Repeat(indentUnit, p.level))
+	}
+	p.atStart = false
+	p.pendingBreak = false
+	p.prev, p.prevType = "", ""
+}
+
+func (p *printer) leaf(n *milestone2.AbstractSyntaxTree) {
+	p.flushComments(n.Line, n.Column)
+
+	text := n.Text
+	if n.Keyword != "" {
+		text = p.dialect.Spell(n.Keyword)
+	}
+
+	if p.pendingBreak || p.atStart {
+		p.startLine(n.Line, p.breakBlank)
+	} else if p.needsSpace(text) {
+		p.out.WriteByte(' ')
+	}
+	p.out.WriteString(text)
+	p.prev, p.prevType = text, n.Token
+	p.lastLine = n.Line
+	p.glue = false
+}
+
+func (p *printer) needsSpace(text string) bool {
+	if p.prev == "" || p.glue {
+		return false
+	}
+	switch text {
+	case ";", ",", ")", "]", ".", "..", ":":
+		return false
+	}
+	switch p.prev {
+	case "(", "[", ".", "..":
+		return false
+	}
+	// Call, parameter list and index brackets stick to the name before them
+	if (text == "(" || text == "[") && p.prevType == milestone1.KindIdentifier {
+		return false
+	}
+	if text == "[" && p.prev == "]" {
+		return false
+	}
+	return true
+}
+
+// Emit the comments that come before source position (line, column)
+func (p *printer) flushComments(line, column int) {
+	for len(p.comments) > 0 {
+		c := p.comments[0]
+		if c.Pos.Line > line || (c.Pos.Line == line && c.Pos.Column > column) {
+			return
+		}
+		p.comment(c)
+		p.comments = p.comments[1:]
+	}
+}
+
+// A comment that starts its own source line keeps its own line; others stay after the previous token
+func (p *printer) comment(c milestone1.Token) {
+	if p.atStart || c.Pos.Line > p.lastLine {
+		wasPending, blank := p.pendingBreak, p.breakBlank
+		p.startLine(c.Pos.Line, true)
+		p.out.WriteString(c.Lexeme)
+		p.pendingBreak = true
+		p.breakBlank = wasPending && blank
+	} else {
+		p.out.WriteByte(' ')
+		p.out.WriteString(c.Lexeme)
+		p.prev, p.prevType = c.Lexeme, milestone1.KindComment
+		p.glue = false
+	}
+	p.lastLine = c.Pos.Line + strings.Count(c.Lexeme, "\n")
+}
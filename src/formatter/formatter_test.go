@@ -0,0 +1,33 @@
+package formatter
+
+import (
+	"compiler/internal/testprogram"
+	"compiler/milestone1"
+	"testing"
+)
+
+// Every program in test/format formats to <name>.out, and formatting that again changes nothing
+func TestFormatPrograms(t *testing.T) {
+	dfa := testprogram.DFA(t)
+	dialect, _ := milestone1.DialectByName("indonesian")
+	format := func(t *testing.T, source string) string {
+		t.Helper()
+		formatted, diagnostics, err := FormatSource([]byte(source), dfa, dialect)
+		if err != nil || len(diagnostics) > 0 {
+			t.Fatalf("format: %v %v", err, diagnostics)
+		}
+		return string(formatted)
+	}
+
+	for _, c := range testprogram.Cases(t, "format") {
+		t.Run(c.Name, func(t *testing.T) {
+			got := format(t, c.Source)
+			if want, _ := c.Companion(t, ".out"); got != want {
+				t.Errorf("formatted:\n%s\nwant:\n%s", got, want)
+			}
+			if again := format(t, got); again != got {
+				t.Errorf("formatting again changed the output:\n%s", again)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+// Package testprogram runs Pascal-S test programs through the front end (lexer with the shared
+// DFA, parser, semantic analyzer) for the tests of the other packages.
+//
+// Test programs live in test/<dir>/<name>.txt at the repository root. Companion files with the
+// same name hold what a test expects, for example <name>.out for the program output.
+package testprogram
+
+import (
+	"compiler/milestone1"
+	"compiler/milestone2"
+	"compiler/milestone3"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// Repository root, found from this file so tests work from any package directory
+var root = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "..")
+}()
+
+// Program - one source after the front end; Analyzer and Decorated are nil after Parse
+type Program struct {
+	Source    string
+	Tokens    []milestone1.Token
+	Tree      *milestone2.AbstractSyntaxTree
+	Analyzer  *milestone3.SemanticAnalyzer
+	Decorated milestone3.DecoratedNode
+}
+
+// DFA - the lexer DFA of src/milestone1/dfa.txt
+func DFA(t testing.TB) milestone1.DFA {
+	t.Helper()
+	file, err := os.Open(filepath.Join(root, "src", "milestone1", "dfa.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	dfa, err := milestone1.LoadDFA(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *dfa
+}
+
+// Parse - lex and parse a source that must be syntactically valid
+func Parse(t testing.TB, source string) *Program {
+	t.Helper()
+	tokens, err := milestone1.NewLexer(DFA(t)).Scan(strings.NewReader(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := milestone2.NewParser(tokens).ParseProgram()
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	return &Program{Source: source, Tokens: tokens, Tree: tree}
+}
+
+// Analyze - Parse plus semantic analysis; semantic errors are left in Analyzer
+func Analyze(t testing.TB, source string) *Program {
+	t.Helper()
+	p := Parse(t, source)
+	p.Analyzer = milestone3.NewSemanticAnalyzer()
+	p.Decorated, _ = p.Analyzer.Analyze(p.Tree)
+	return p
+}
+
+// Compile - Analyze for a source that must also be semantically valid
+func Compile(t testing.TB, source string) *Program {
+	t.Helper()
+	p := Analyze(t, source)
+	if len(p.Analyzer.GetErrors()) > 0 {
+		t.Fatalf("analyze: %v", p.Analyzer.GetErrors())
+	}
+	return p
+}
+
+// Case - test program test/<dir>/<Name>.txt
+type Case struct {
+	Name   string
+	Source string
+	base   string // Path without the .txt extension
+}
+
+// Cases - every program in test/<dir>, sorted by name
+func Cases(t testing.TB, dir string) []Case {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(root, "test", dir, "*.txt"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no test programs in test/%s", dir)
+	}
+	sort.Strings(paths)
+	cases := make([]Case, 0, len(paths))
+	for _, path := range paths {
+		source, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		base := strings.TrimSuffix(path, ".txt")
+		cases = append(cases, Case{Name: filepath.Base(base), Source: string(source), base: base})
+	}
+	return cases
+}
+
+// Load - the test program test/<dir>/<name>.txt
+func Load(t testing.TB, dir, name string) Case {
+	t.Helper()
+	for _, c := range Cases(t, dir) {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("no test program test/%s/%s.txt", dir, name)
+	return Case{}
+}
+
+// Companion - contents of the file next to the program with extension ext (".out", ".err", ...)
+func (c Case) Companion(t testing.TB, ext string) (string, bool) {
+	t.Helper()
+	content, err := os.ReadFile(c.base + ext)
+	if os.IsNotExist(err) {
+		return "", false
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content), true
+}
+
+// Backend - runs a compiled program with the given standard input and output
+type Backend func(p *Program, input io.Reader, output io.Writer) error
+
+// RunCases runs every program in test/runtime on one backend. <name>.in is the standard input,
+// <name>.out the expected output and <name>.err a text the run error must contain (without it
+// the run must succeed). Both backends run the same cases, so they stay interchangeable.
+func RunCases(t *testing.T, run Backend) {
+	for _, c := range Cases(t, "runtime") {
+		t.Run(c.Name, func(t *testing.T) {
+			p := Compile(t, c.Source)
+			input, _ := c.Companion(t, ".in")
+			var output strings.Builder
+			err := run(p, strings.NewReader(input), &output)
+
+			if want, ok := c.Companion(t, ".err"); ok {
+				if want = strings.TrimSpace(want); err == nil || !strings.Contains(err.Error(), want) {
+					t.Errorf("run error = %v, want %q", err, want)
+				}
+			} else if err != nil {
+				t.Errorf("run: %v", err)
+			}
+			if want, ok := c.Companion(t, ".out"); ok && output.String() != want {
+				t.Errorf("output = %q, want %q", output.String(), want)
+			}
+		})
+	}
+}
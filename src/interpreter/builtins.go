@@ -0,0 +1,231 @@
+package interpreter
+
+import (
+	"compiler/milestone3"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ========== BUILT-IN PROCEDURES ==========
+
+func (it *Interpreter) callBuiltIn(n *milestone3.ProcCallNode) {
+	switch n.Name {
+	case "write", "writeln":
+		for _, arg := range n.Arguments {
+			it.out.WriteString(formatValue(it.eval(arg)))
+		}
+		if n.Name == "writeln" {
+			it.out.WriteString("\n")
+		}
+	case "read", "readln":
+		// Flush pending output so prompts appear before waiting for input
+		it.out.Flush()
+		for i, arg := range n.Arguments {
+			target, ok := arg.(*milestone3.VarNode)
+			if !ok {
+				it.fail("argument %d of '%s' must be a variable", i+1, n.Name)
+			}
+			addr := it.addressOf(target)
+			value := it.readValue(target.Type, target.Name)
+			it.checkRange(value, target.Type, target.Ref, target.Name)
+			addr.store(value)
+		}
+		if n.Name == "readln" {
+			it.skipLine()
+		}
+	default:
+		it.fail("unknown built-in procedure '%s'", n.Name)
+	}
+}
+
+// ========== STANDARD FUNCTIONS ==========
+
+func (it *Interpreter) callStdFunction(n *milestone3.ProcCallNode) Value {
+	switch n.Func {
+	case milestone3.StdEof:
+		_, err := it.in.Peek(1)
+		return boolValue(err != nil)
+	case milestone3.StdEoln:
+		b, err := it.in.Peek(1)
+		return boolValue(err != nil || b[0] == '\r' || b[0] == '\n')
+	}
+
+	if len(n.Arguments) != 1 {
+		it.fail("'%s' expects 1 argument", n.Name)
+	}
+	arg := it.eval(n.Arguments[0])
+
+	switch n.Func {
+	case milestone3.StdAbs:
+		if arg.Int < 0 {
+			arg.Int = -arg.Int
+		}
+		return Value{Type: milestone3.TypeInteger, Int: arg.Int}
+	case milestone3.StdAbsReal:
+		return Value{Type: milestone3.TypeReal, Real: math.Abs(arg.Real)}
+	case milestone3.StdSqr:
+		return Value{Type: milestone3.TypeInteger, Int: arg.Int * arg.Int}
+	case milestone3.StdSqrReal:
+		return Value{Type: milestone3.TypeReal, Real: arg.Real * arg.Real}
+	case milestone3.StdOdd:
+		return boolValue(arg.Int%2 != 0)
+	case milestone3.StdChr:
+		if arg.Int < 0 || arg.Int > 255 {
+			it.fail("chr(%d): character code out of range [0..255]", arg.Int)
+		}
+		return Value{Type: milestone3.TypeChar, Int: arg.Int}
+	case milestone3.StdOrd:
+		return Value{Type: milestone3.TypeInteger, Int: arg.Int}
+	case milestone3.StdSucc, milestone3.StdPred:
+		result := Value{Type: n.Type, Int: arg.Int + 1}
+		if n.Func == milestone3.StdPred {
+			result.Int = arg.Int - 1
+		}
+		// The result must still be a value of the enumeration (or a character)
+		if low, high, ok := it.symTable.HostBounds(n.Type, n.Ref); ok && (result.Int < low || result.Int > high) {
+			it.fail("%s(%s): result out of range", n.Name, it.symTable.OrdinalName(arg.Int, n.Type, n.Ref))
+		}
+		return result
+	case milestone3.StdRound:
+		return Value{Type: milestone3.TypeInteger, Int: int(math.Round(toReal(arg)))}
+	case milestone3.StdTrunc:
+		return Value{Type: milestone3.TypeInteger, Int: int(toReal(arg))}
+	}
+
+	x := toReal(arg)
+	switch n.Func {
+	case milestone3.StdSin:
+		x = math.Sin(x)
+	case milestone3.StdCos:
+		x = math.Cos(x)
+	case milestone3.StdExp:
+		x = math.Exp(x)
+	case milestone3.StdLn:
+		if x <= 0 {
+			it.fail("ln(%s): argument must be positive", formatValue(arg))
+		}
+		x = math.Log(x)
+	case milestone3.StdSqrt:
+		if x < 0 {
+			it.fail("sqrt(%s): argument must not be negative", formatValue(arg))
+		}
+		x = math.Sqrt(x)
+	default:
+		it.fail("unknown standard function '%s'", n.Name)
+	}
+	return Value{Type: milestone3.TypeReal, Real: x}
+}
+
+// Text representation used by write/writeln
+func formatValue(v Value) string {
+	switch v.Type {
+	case milestone3.TypeInteger, milestone3.TypeEnum:
+		// Enumeration values are written as their ordinal
+		return strconv.Itoa(v.Int)
+	case milestone3.TypeReal:
+		return strconv.FormatFloat(v.Real, 'f', -1, 64)
+	case milestone3.TypeBoolean:
+		if v.Int != 0 {
+			return "true"
+		}
+		return "false"
+	case milestone3.TypeChar:
+		return string(rune(v.Int))
+	default:
+		return v.Str
+	}
+}
+
+// Read one value of the given type from input
+func (it *Interpreter) readValue(typ milestone3.TypeKind, name string) Value {
+	switch typ {
+	case milestone3.TypeChar:
+		b, err := it.in.ReadByte()
+		if err != nil {
+			it.fail("unexpected end of input while reading '%s'", name)
+		}
+		if b == '\r' || b == '\n' {
+			// End of line is read as a blank, like standard Pascal
+			if b == '\r' {
+				it.in.ReadByte()
+			}
+			b = ' '
+		}
+		return Value{Type: milestone3.TypeChar, Int: int(b)}
+	case milestone3.TypeInteger:
+		word := it.readWord()
+		value, err := strconv.Atoi(word)
+		if err != nil {
+			it.fail("invalid integer input '%s' for '%s'", word, name)
+		}
+		return Value{Type: milestone3.TypeInteger, Int: value}
+	case milestone3.TypeReal:
+		word := it.readWord()
+		value, err := strconv.ParseFloat(word, 64)
+		if err != nil {
+			it.fail("invalid real input '%s' for '%s'", word, name)
+		}
+		return Value{Type: milestone3.TypeReal, Real: value}
+	case milestone3.TypeString:
+		return Value{Type: milestone3.TypeString, Str: it.readRestOfLine()}
+	}
+
+	it.fail("cannot read a value of type %s into '%s'", typ, name)
+	return Value{}
+}
+
+// Read the characters up to (not including) the end of the current line
+func (it *Interpreter) readRestOfLine() string {
+	var sb strings.Builder
+	for {
+		b, err := it.in.ReadByte()
+		if err != nil {
+			break
+		}
+		if b == '\r' || b == '\n' {
+			it.in.UnreadByte()
+			break
+		}
+		sb.WriteByte(b)
+	}
+	return sb.String()
+}
+
+// Skip leading whitespace and read the next whitespace-delimited word
+func (it *Interpreter) readWord() string {
+	var sb strings.Builder
+	for {
+		b, err := it.in.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			it.fail("error reading input: %v", err)
+		}
+		if unicode.IsSpace(rune(b)) {
+			if sb.Len() == 0 {
+				continue
+			}
+			it.in.UnreadByte()
+			break
+		}
+		sb.WriteByte(b)
+	}
+	if sb.Len() == 0 {
+		it.fail("unexpected end of input")
+	}
+	return sb.String()
+}
+
+// Discard the rest of the current input line (readln)
+func (it *Interpreter) skipLine() {
+	for {
+		b, err := it.in.ReadByte()
+		if err != nil || b == '\n' {
+			return
+		}
+	}
+}
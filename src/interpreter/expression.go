@@ -0,0 +1,203 @@
+package interpreter
+
+import (
+	"compiler/milestone3"
+	"strings"
+)
+
+// ========== EXPRESSIONS ==========
+
+func (it *Interpreter) eval(node milestone3.DecoratedNode) Value {
+	switch n := node.(type) {
+	case *milestone3.NumberNode:
+		return Value{Type: milestone3.TypeInteger, Int: n.Value}
+	case *milestone3.RealNode:
+		return Value{Type: milestone3.TypeReal, Real: n.Value}
+	case *milestone3.CharNode:
+		return Value{Type: milestone3.TypeChar, Int: int(n.Value)}
+	case *milestone3.StringNode:
+		return Value{Type: milestone3.TypeString, Str: n.Value}
+	case *milestone3.BooleanNode:
+		return boolValue(n.Value)
+	case *milestone3.VarNode:
+		return it.evalVar(n)
+	case *milestone3.UnaryOpNode:
+		return it.evalUnaryOp(n)
+	case *milestone3.BinOpNode:
+		return it.evalBinOp(n)
+	case *milestone3.ProcCallNode:
+		if n.IsBuiltIn {
+			return it.callStdFunction(n)
+		}
+		return it.call(n)
+	default:
+		it.fail("unsupported expression %T", node)
+	}
+	return Value{}
+}
+
+// Evaluate a condition that must be boolean
+func (it *Interpreter) evalCondition(node milestone3.DecoratedNode) bool {
+	v := it.eval(node)
+	if v.Type != milestone3.TypeBoolean {
+		it.fail("condition is not boolean")
+	}
+	return v.Int != 0
+}
+
+// Variable or constant reference
+func (it *Interpreter) evalVar(n *milestone3.VarNode) Value {
+	entry, err := it.symTable.GetEntry(n.TabIndex)
+	if err != nil {
+		it.fail("undefined identifier '%s'", n.Name)
+	}
+	if entry.Obj == milestone3.ObjConstant {
+		// Constant value is stored in adr (an RCONST/STAB index for reals/strings)
+		if entry.Type == milestone3.TypeReal {
+			value, err := it.symTable.GetReal(entry.Adr)
+			if err != nil {
+				it.fail("invalid real constant '%s'", n.Name)
+			}
+			return Value{Type: milestone3.TypeReal, Real: value}
+		}
+		if entry.Type == milestone3.TypeString {
+			value, err := it.symTable.GetString(entry.Adr)
+			if err != nil {
+				it.fail("invalid string constant '%s'", n.Name)
+			}
+			return Value{Type: milestone3.TypeString, Str: value}
+		}
+		return Value{Type: entry.Type, Int: entry.Adr}
+	}
+	return it.addressOf(n).load()
+}
+
+func (it *Interpreter) evalUnaryOp(n *milestone3.UnaryOpNode) Value {
+	operand := it.eval(n.Operand)
+	switch n.Operator {
+	case "tidak", "not":
+		return boolValue(operand.Int == 0)
+	case "-":
+		if operand.Type == milestone3.TypeReal {
+			return Value{Type: milestone3.TypeReal, Real: -operand.Real}
+		}
+		return Value{Type: milestone3.TypeInteger, Int: -operand.Int}
+	case "+":
+		return operand
+	}
+	it.fail("unknown unary operator '%s'", n.Operator)
+	return Value{}
+}
+
+func (it *Interpreter) evalBinOp(n *milestone3.BinOpNode) Value {
+	left := it.eval(n.Left)
+	right := it.eval(n.Right)
+
+	switch n.Operator {
+	case "dan", "and":
+		return boolValue(left.Int != 0 && right.Int != 0)
+	case "atau", "or":
+		return boolValue(left.Int != 0 || right.Int != 0)
+	case "=", "<>", "<", ">", "<=", ">=":
+		return boolValue(compare(n.Operator, left, right))
+	case "/":
+		divisor := toReal(right)
+		if divisor == 0 {
+			it.fail("division by zero")
+		}
+		return Value{Type: milestone3.TypeReal, Real: toReal(left) / divisor}
+	case "bagi", "div", "mod":
+		if right.Int == 0 {
+			it.fail("division by zero")
+		}
+		if n.Operator == "mod" {
+			return Value{Type: milestone3.TypeInteger, Int: left.Int % right.Int}
+		}
+		return Value{Type: milestone3.TypeInteger, Int: left.Int / right.Int}
+	case "+", "-", "*":
+		if n.Operator == "+" && (left.Type == milestone3.TypeString || right.Type == milestone3.TypeString) {
+			return Value{Type: milestone3.TypeString, Str: toString(left) + toString(right)}
+		}
+		if left.Type == milestone3.TypeReal || right.Type == milestone3.TypeReal {
+			a, b := toReal(left), toReal(right)
+			switch n.Operator {
+			case "+":
+				return Value{Type: milestone3.TypeReal, Real: a + b}
+			case "-":
+				return Value{Type: milestone3.TypeReal, Real: a - b}
+			default:
+				return Value{Type: milestone3.TypeReal, Real: a * b}
+			}
+		}
+		switch n.Operator {
+		case "+":
+			return Value{Type: milestone3.TypeInteger, Int: left.Int + right.Int}
+		case "-":
+			return Value{Type: milestone3.TypeInteger, Int: left.Int - right.Int}
+		default:
+			return Value{Type: milestone3.TypeInteger, Int: left.Int * right.Int}
+		}
+	}
+
+	it.fail("unknown operator '%s'", n.Operator)
+	return Value{}
+}
+
+// Relational comparison between two values of compatible types
+func compare(op string, left, right Value) bool {
+	var cmp int
+	switch {
+	case left.Type == milestone3.TypeString || right.Type == milestone3.TypeString:
+		cmp = strings.Compare(toString(left), toString(right))
+	case left.Type == milestone3.TypeReal || right.Type == milestone3.TypeReal:
+		a, b := toReal(left), toReal(right)
+		if a < b {
+			cmp = -1
+		} else if a > b {
+			cmp = 1
+		}
+	default:
+		if left.Int < right.Int {
+			cmp = -1
+		} else if left.Int > right.Int {
+			cmp = 1
+		}
+	}
+
+	switch op {
+	case "=":
+		return cmp == 0
+	case "<>":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case ">":
+		return cmp > 0
+	case "<=":
+		return cmp <= 0
+	default:
+		return cmp >= 0
+	}
+}
+
+func toReal(v Value) float64 {
+	if v.Type == milestone3.TypeReal {
+		return v.Real
+	}
+	return float64(v.Int)
+}
+
+// String form of a string or char value (used for concatenation and comparison)
+func toString(v Value) string {
+	if v.Type == milestone3.TypeChar {
+		return string(rune(v.Int))
+	}
+	return v.Str
+}
+
+func boolValue(b bool) Value {
+	if b {
+		return Value{Type: milestone3.TypeBoolean, Int: 1}
+	}
+	return Value{Type: milestone3.TypeBoolean, Int: 0}
+}
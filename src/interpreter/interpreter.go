@@ -0,0 +1,342 @@
+package interpreter
+
+import (
+	"bufio"
+	"compiler/milestone3"
+	"fmt"
+	"io"
+)
+
+// Maximum depth of nested procedure/function calls before reporting a stack overflow
+const maxCallDepth = 5000
+
+// Interpreter executes a decorated AST by walking it directly
+// Memory layout (levels, offsets, frame sizes) is taken from the symbol table built by the analyzer
+type Interpreter struct {
+	symTable    *milestone3.SymbolTable
+	in          *bufio.Reader
+	out         *bufio.Writer
+	display     []*Frame                               // Display register: active frame per lexical level
+	subprograms map[int]*milestone3.SubprogramDeclNode // Subprogram declarations by TAB index
+	callDepth   int
+}
+
+// RuntimeError - error raised while the program is running
+type RuntimeError struct {
+	Message string
+}
+
+func (e *RuntimeError) Error() string {
+	return e.Message
+}
+
+// Create new interpreter
+func NewInterpreter(symTable *milestone3.SymbolTable, in io.Reader, out io.Writer) *Interpreter {
+	return &Interpreter{
+		symTable:    symTable,
+		in:          bufio.NewReader(in),
+		out:         bufio.NewWriter(out),
+		display:     make([]*Frame, len(symTable.Display)),
+		subprograms: make(map[int]*milestone3.SubprogramDeclNode),
+	}
+}
+
+// Run executes the program and returns the first runtime error (if any)
+func (it *Interpreter) Run(root milestone3.DecoratedNode) (err error) {
+	program, ok := root.(*milestone3.ProgramNode)
+	if !ok {
+		return fmt.Errorf("expected ProgramNode, got %T", root)
+	}
+
+	defer func() {
+		it.out.Flush()
+		if r := recover(); r != nil {
+			if rtErr, ok := r.(*RuntimeError); ok {
+				err = rtErr
+				return
+			}
+			panic(r)
+		}
+	}()
+
+	it.collectSubprograms(program.Declarations)
+
+	// Global frame: block 0 at level 0
+	it.display[0] = it.newFrame(0, 0)
+	it.execStatement(program.Block)
+
+	return nil
+}
+
+// Abort execution with a runtime error
+func (it *Interpreter) fail(format string, args ...interface{}) {
+	panic(&RuntimeError{Message: fmt.Sprintf(format, args...)})
+}
+
+// Range check for a value stored into a subrange/enumeration variable or parameter
+func (it *Interpreter) checkRange(v Value, typ milestone3.TypeKind, ref int, name string) {
+	low, high, ok := it.symTable.OrdinalBounds(typ, ref)
+	if ok && (v.Int < low || v.Int > high) {
+		it.fail("value %s out of range [%s..%s] for '%s'", it.symTable.OrdinalName(v.Int, typ, ref),
+			it.symTable.OrdinalName(low, typ, ref), it.symTable.OrdinalName(high, typ, ref), name)
+	}
+}
+
+// Index all subprogram declarations (including nested ones) by TAB index
+func (it *Interpreter) collectSubprograms(node milestone3.DecoratedNode) {
+	switch n := node.(type) {
+	case *milestone3.DeclarationListNode:
+		for _, decl := range n.Declarations {
+			it.collectSubprograms(decl)
+		}
+	case *milestone3.SubprogramDeclNode:
+		it.subprograms[n.TabIndex] = n
+		it.collectSubprograms(n.Declarations)
+	}
+}
+
+// ========== STATEMENTS ==========
+
+func (it *Interpreter) execStatement(node milestone3.DecoratedNode) {
+	switch n := node.(type) {
+	case nil:
+		return
+	case *milestone3.BlockNode:
+		for _, stmt := range n.Statements {
+			it.execStatement(stmt)
+		}
+	case *milestone3.AssignNode:
+		it.execAssign(n)
+	case *milestone3.ProcCallNode:
+		if n.IsBuiltIn {
+			it.callBuiltIn(n)
+		} else {
+			it.call(n)
+		}
+	case *milestone3.IfNode:
+		if it.evalCondition(n.Condition) {
+			it.execStatement(n.ThenStmt)
+		} else {
+			it.execStatement(n.ElseStmt)
+		}
+	case *milestone3.WhileNode:
+		for it.evalCondition(n.Condition) {
+			it.execStatement(n.Body)
+		}
+	case *milestone3.RepeatNode:
+		for {
+			it.execStatement(n.Body)
+			if it.evalCondition(n.Condition) {
+				break
+			}
+		}
+	case *milestone3.CaseNode:
+		it.execCase(n)
+	case *milestone3.ForNode:
+		it.execFor(n)
+	default:
+		it.fail("unsupported statement %T", node)
+	}
+}
+
+// Assignment: scalars are evaluated and converted, arrays/records are copied slot by slot
+func (it *Interpreter) execAssign(n *milestone3.AssignNode) {
+	target, ok := n.Target.(*milestone3.VarNode)
+	if !ok {
+		it.fail("invalid assignment target")
+	}
+	addr := it.addressOf(target)
+
+	if target.Type == milestone3.TypeArray || target.Type == milestone3.TypeRecord {
+		source, ok := n.Value.(*milestone3.VarNode)
+		if !ok {
+			it.fail("cannot assign expression to %s '%s'", target.Type, target.Name)
+		}
+		copySlots(addr, it.addressOf(source), it.symTable.GetTypeSize(target.Type, target.Ref))
+		return
+	}
+
+	value := coerce(it.eval(n.Value), target.Type)
+	it.checkRange(value, target.Type, target.Ref, target.Name)
+	addr.store(value)
+}
+
+// Case: run the arm whose label matches the selector, else the selain_itu arm
+// Like Pascal-S, a selector that matches no label without selain_itu is a runtime error
+func (it *Interpreter) execCase(n *milestone3.CaseNode) {
+	selector := it.eval(n.Selector).Int
+	for _, arm := range n.Arms {
+		for _, label := range arm.Labels {
+			if label == selector {
+				it.execStatement(arm.Body)
+				return
+			}
+		}
+	}
+	if n.Else == nil {
+		it.fail("no case label matches selector value %d", selector)
+	}
+	it.execStatement(n.Else)
+}
+
+// For loop: bounds are evaluated once, the counter steps by one towards the end value
+func (it *Interpreter) execFor(n *milestone3.ForNode) {
+	counter, ok := n.Variable.(*milestone3.VarNode)
+	if !ok {
+		it.fail("invalid for loop counter")
+	}
+	start := it.eval(n.StartValue).Int
+	end := it.eval(n.EndValue).Int
+	addr := it.addressOf(counter)
+
+	step := 1
+	if n.IsDownTo {
+		step = -1
+	}
+	if (step > 0 && start > end) || (step < 0 && start < end) {
+		return
+	}
+	// The loop runs: every counter value lies between start and end, so checking both once is enough
+	it.checkRange(Value{Type: milestone3.TypeInteger, Int: start}, counter.Type, counter.Ref, counter.Name)
+	it.checkRange(Value{Type: milestone3.TypeInteger, Int: end}, counter.Type, counter.Ref, counter.Name)
+
+	for i := start; ; i += step {
+		addr.store(coerce(Value{Type: milestone3.TypeInteger, Int: i}, counter.Type))
+		it.execStatement(n.Body)
+		if i == end {
+			break
+		}
+	}
+}
+
+// Call a user-defined procedure/function
+// Returns the function result (zero Value for procedures)
+func (it *Interpreter) call(n *milestone3.ProcCallNode) Value {
+	entry, err := it.symTable.GetEntry(n.TabIndex)
+	if err != nil {
+		it.fail("undefined subprogram '%s'", n.Name)
+	}
+	decl, ok := it.subprograms[n.TabIndex]
+	if !ok {
+		it.fail("no body for subprogram '%s'", n.Name)
+	}
+	if len(n.Arguments) != len(decl.Parameters) {
+		it.fail("'%s' expects %d argument(s) but got %d", n.Name, len(decl.Parameters), len(n.Arguments))
+	}
+
+	it.callDepth++
+	if it.callDepth > maxCallDepth {
+		it.fail("stack overflow in call to '%s'", n.Name)
+	}
+	defer func() { it.callDepth-- }()
+
+	level := entry.Lev + 1
+	frame := it.newFrame(entry.Ref, level)
+
+	// Bind parameters while the caller's display is still active
+	for i, param := range decl.Parameters {
+		paramEntry, err := it.symTable.GetEntry(param.GetTabIndex())
+		if err != nil {
+			continue
+		}
+		slot := Address{Frame: frame, Offset: paramEntry.Adr}
+		arg := n.Arguments[i]
+
+		switch {
+		case n.IsByRef(i):
+			// var parameter: pass the address of the actual argument
+			argVar, ok := arg.(*milestone3.VarNode)
+			if !ok {
+				it.fail("argument %d of '%s' must be a variable", i+1, n.Name)
+			}
+			ref := it.addressOf(argVar)
+			slot.store(Value{Type: paramEntry.Type, Ref: &ref})
+		case paramEntry.Type == milestone3.TypeArray || paramEntry.Type == milestone3.TypeRecord:
+			// value parameter of composite type: copy the whole value
+			argVar, ok := arg.(*milestone3.VarNode)
+			if !ok {
+				it.fail("argument %d of '%s' must be a variable", i+1, n.Name)
+			}
+			copySlots(slot, it.addressOf(argVar), it.symTable.GetTypeSize(paramEntry.Type, paramEntry.Ref))
+		default:
+			value := coerce(it.eval(arg), paramEntry.Type)
+			it.checkRange(value, paramEntry.Type, paramEntry.Ref, paramEntry.Identifier)
+			slot.store(value)
+		}
+	}
+
+	for level >= len(it.display) {
+		it.display = append(it.display, nil)
+	}
+	saved := it.display[level]
+	it.display[level] = frame
+	it.execStatement(decl.Body)
+	it.display[level] = saved
+
+	if !decl.IsFunction {
+		return Value{}
+	}
+	return it.functionResult(decl, frame)
+}
+
+// Read the implicit result variable (same name as the function) from the callee frame
+func (it *Interpreter) functionResult(decl *milestone3.SubprogramDeclNode, frame *Frame) Value {
+	idx := it.symTable.Btab[frame.Block].Last
+	for idx >= 0 && idx < len(it.symTable.Tab) {
+		entry := it.symTable.Tab[idx]
+		if entry.Identifier == decl.Name && entry.Obj == milestone3.ObjVariable {
+			return coerce(Address{Frame: frame, Offset: entry.Adr}.load(), decl.ReturnType)
+		}
+		idx = entry.Link
+	}
+	return Value{Type: decl.ReturnType}
+}
+
+// ========== VARIABLES ==========
+
+// Resolve the memory address of a variable reference (following var parameters and indices)
+func (it *Interpreter) addressOf(v *milestone3.VarNode) Address {
+	entry, err := it.symTable.GetEntry(v.TabIndex)
+	if err != nil {
+		it.fail("undefined variable '%s'", v.Name)
+	}
+	if entry.Obj != milestone3.ObjVariable {
+		it.fail("'%s' is not a variable", v.Name)
+	}
+	if entry.Lev >= len(it.display) || it.display[entry.Lev] == nil {
+		it.fail("variable '%s' is not accessible", v.Name)
+	}
+
+	addr := Address{Frame: it.display[entry.Lev], Offset: entry.Adr}
+
+	// var parameter: the slot holds the address of the actual argument
+	if entry.Nrm == 0 {
+		ref := addr.load().Ref
+		if ref == nil {
+			it.fail("unbound var parameter '%s'", v.Name)
+		}
+		addr = *ref
+	}
+
+	// Selector chain: leading field offsets are static, the rest is applied in order
+	addr.Offset += v.StaticOffset()
+	dim := 0
+	for _, sel := range v.DynamicSelectors() {
+		if sel.Index == nil {
+			addr.Offset += sel.Offset
+			continue
+		}
+		dim++
+		atab, err := it.symTable.GetArrayEntry(sel.Ref)
+		if err != nil {
+			it.fail("'%s' is not an array", v.Name)
+		}
+		index := it.eval(sel.Index).Int
+		if index < atab.Low || index > atab.High {
+			it.fail("index %d out of range [%d..%d] for '%s' (dimension %d)", index, atab.Low, atab.High, v.Name, dim)
+		}
+		addr.Offset += (index - atab.Low) * atab.Elsz
+	}
+
+	return addr
+}
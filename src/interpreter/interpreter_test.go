@@ -0,0 +1,13 @@
+package interpreter
+
+import (
+	"compiler/internal/testprogram"
+	"io"
+	"testing"
+)
+
+func TestRuntimePrograms(t *testing.T) {
+	testprogram.RunCases(t, func(p *testprogram.Program, input io.Reader, output io.Writer) error {
+		return NewInterpreter(p.Analyzer.GetSymbolTable(), input, output).Run(p.Decorated)
+	})
+}
@@ -0,0 +1,131 @@
+package interpreter
+
+import "compiler/milestone3"
+
+// Value - content of a single memory slot
+type Value struct {
+	Type milestone3.TypeKind
+	Int  int      // integer, boolean (0/1) and char (character code)
+	Real float64  // real
+	Str  string   // string
+	Ref  *Address // address of the actual argument for var (by-reference) parameters
+}
+
+// Address - location of a slot: activation record + offset
+type Address struct {
+	Frame  *Frame
+	Offset int
+}
+
+// Frame - activation record of a block (program, procedure, function)
+// Slot layout follows the symbol table: header (0..4), parameters, local variables
+type Frame struct {
+	Block int // Index in BTAB
+	Level int // Lexical level of the variables stored in this frame
+	Slots []Value
+}
+
+// Header size of a stack frame, same as the analyzer's starting offset
+const frameHeaderSize = 5
+
+// Create a new frame for a block and zero-initialise its variables
+func (it *Interpreter) newFrame(block, level int) *Frame {
+	size := frameHeaderSize
+	if entry, err := it.symTable.GetBlockEntry(block); err == nil {
+		size += entry.Psze + entry.Vsze
+	}
+
+	frame := &Frame{
+		Block: block,
+		Level: level,
+		Slots: make([]Value, size),
+	}
+
+	// Walk the block's identifier chain and lay out every variable
+	idx := it.symTable.Btab[block].Last
+	for idx >= 0 && idx < len(it.symTable.Tab) {
+		entry := it.symTable.Tab[idx]
+		if entry.Obj == milestone3.ObjVariable && entry.Lev == level {
+			it.initSlots(Address{Frame: frame, Offset: entry.Adr}, entry.Type, entry.Ref)
+		}
+		idx = entry.Link
+	}
+
+	return frame
+}
+
+// Initialise the slots of a (possibly composite) variable with zero values
+func (it *Interpreter) initSlots(addr Address, typ milestone3.TypeKind, ref int) {
+	switch typ {
+	case milestone3.TypeArray:
+		atab, err := it.symTable.GetArrayEntry(ref)
+		if err != nil {
+			return
+		}
+		for i := 0; i <= atab.High-atab.Low; i++ {
+			elem := Address{Frame: addr.Frame, Offset: addr.Offset + i*atab.Elsz}
+			it.initSlots(elem, milestone3.TypeKind(atab.Etyp), atab.Eref)
+		}
+	case milestone3.TypeRecord:
+		btab, err := it.symTable.GetBlockEntry(ref)
+		if err != nil {
+			return
+		}
+		idx := btab.Last
+		for idx >= 0 && idx < len(it.symTable.Tab) {
+			field := it.symTable.Tab[idx]
+			if field.Obj == milestone3.ObjField {
+				it.initSlots(Address{Frame: addr.Frame, Offset: addr.Offset + field.Adr}, field.Type, field.Ref)
+			}
+			idx = field.Link
+		}
+	default:
+		*addr.slot() = Value{Type: typ}
+	}
+}
+
+// Pointer to the slot at an address (grows the frame if needed)
+func (a Address) slot() *Value {
+	if a.Offset >= len(a.Frame.Slots) {
+		grown := make([]Value, a.Offset+1)
+		copy(grown, a.Frame.Slots)
+		a.Frame.Slots = grown
+	}
+	return &a.Frame.Slots[a.Offset]
+}
+
+// Load the value stored at an address
+func (a Address) load() Value {
+	return *a.slot()
+}
+
+// Store a value at an address
+func (a Address) store(v Value) {
+	*a.slot() = v
+}
+
+// Copy a composite value (array/record) slot by slot
+func copySlots(dst, src Address, size int) {
+	values := make([]Value, size)
+	for i := 0; i < size; i++ {
+		values[i] = Address{Frame: src.Frame, Offset: src.Offset + i}.load()
+	}
+	for i := 0; i < size; i++ {
+		Address{Frame: dst.Frame, Offset: dst.Offset + i}.store(values[i])
+	}
+}
+
+// Convert a value to the declared type of its destination (integer -> real widening, char <-> integer, char -> string)
+func coerce(v Value, typ milestone3.TypeKind) Value {
+	switch typ {
+	case milestone3.TypeReal:
+		if v.Type != milestone3.TypeReal {
+			return Value{Type: milestone3.TypeReal, Real: float64(v.Int)}
+		}
+	case milestone3.TypeString:
+		return Value{Type: milestone3.TypeString, Str: toString(v)}
+	case milestone3.TypeInteger, milestone3.TypeChar, milestone3.TypeBoolean, milestone3.TypeEnum:
+		v.Type = typ
+	}
+	return v
+}
@@ -2,13 +2,17 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"compiler/diagnostic"
+	"compiler/interpreter"
 	"compiler/milestone1"
 	"compiler/milestone2"
 	"compiler/milestone3"
+	"compiler/pcode"
+	"compiler/translator"
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
-	"runtime"
 	"strings"
 )
 
@@ -22,57 +26,65 @@ func main() {
 
 	// Cek argumen input
 	if len(os.Args) < 3 {
-		fmt.Printf("Cara pakai: go run ./src <file_dfa.txt> <file_program.txt>\n")
+		fmt.Printf("Cara pakai: go run ./src <file_dfa.txt> <file_program.txt> [--pcode] [--json] [--comments] [--dialect=indonesian|english|<file>] [--translate] [--export] [--tokens]\n")
 		return
 	}
 
 	dfa_file := os.Args[1]
 	srcFile := os.Args[2]
 
+	// Opsi tambahan
+	usePCode := false
+	jsonDiagnostics := false
+	keepComments := false
+	translate := false
+	export := false
+	dumpTokens := false
+	dialect := milestone1.Indonesian
+	for _, arg := range os.Args[3:] {
+		switch {
+		case arg == "--pcode":
+			usePCode = true
+		case arg == "--json":
+			jsonDiagnostics = true
+		case arg == "--comments":
+			keepComments = true
+		case arg == "--translate":
+			translate = true
+		case arg == "--export":
+			export = true
+		case arg == "--tokens":
+			dumpTokens = true
+		case strings.HasPrefix(arg, "--dialect="):
+			d, err := loadDialect(strings.TrimPrefix(arg, "--dialect="))
+			if err != nil {
+				fmt.Printf("ERROR: error loading dialect: %v\n", err)
+				return
+			}
+			dialect = d
+		}
+	}
+
+	// Semua error/warning dari ketiga milestone dikumpulkan di sini
+	var diagnostics []diagnostic.Diagnostic
+	defer func() {
+		writeDiagnostics(srcFile, diagnostics, jsonDiagnostics)
+	}()
+
 	// 1. LOAD DFA
 	dfaReference, err := os.Open(dfa_file)
 	if err != nil {
 		fmt.Printf("ERROR: error opening DFA file: %v\n", err)
 		return
 	}
-	defer dfaReference.Close()
-
-	dfaScanner := bufio.NewScanner(dfaReference)
-	dfa := &milestone1.DFA{
-		Transition: make(map[milestone1.TransitionKey]string),
-	}
-
-	for dfaScanner.Scan() {
-		line := strings.TrimSpace(dfaScanner.Text())
-		// Skip komentar dan baris kosong
-		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
-			continue
-		}
-
-		if strings.Contains(line, "Start_state") {
-			dfa.StartState = strings.TrimSpace(strings.TrimPrefix(line, "Start_state = "))
-		} else if strings.Contains(line, "Final_state") {
-			finalStatesStr := strings.TrimSpace(strings.TrimPrefix(line, "Final_state = "))
-			finalStates := strings.Split(finalStatesStr, ", ")
-			for i := range finalStates {
-				finalStates[i] = strings.TrimSpace(finalStates[i])
-			}
-			dfa.FinalState = finalStates
-		} else {
-			// Baca transisi state
-			elements := strings.Fields(line)
-			if len(elements) >= 3 {
-				transitionVal := milestone1.TransitionKey{
-					State: elements[0],
-					Input: elements[1],
-				}
-				dfa.Transition[transitionVal] = elements[2]
-			}
-		}
+	dfa, err := milestone1.LoadDFA(dfaReference)
+	dfaReference.Close()
+	if err != nil {
+		fmt.Printf("ERROR: error reading DFA file: %v\n", err)
+		return
 	}
 
 	// 2. LEXICAL ANALYZER
-	currentState := dfa.StartState
 	srcReference, err := os.Open(srcFile)
 	if err != nil {
 		fmt.Printf("ERROR: error opening source file: %v\n", err)
@@ -80,63 +92,47 @@ func main() {
 	}
 	defer srcReference.Close()
 
-	// Pastikan folder output ada
-	os.MkdirAll("../test/output", os.ModePerm)
-	tokenReference, err := os.Create("../test/output/tokens.txt")
+	// Token langsung disimpan di memori
+	lexer := milestone1.NewLexer(*dfa)
+	lexer.KeepComments(keepComments)
+	lexer.SetDialect(dialect)
+	tokens, err := lexer.Scan(srcReference)
 	if err != nil {
-		fmt.Printf("ERROR: error creating token file: %v\n", err)
+		fmt.Printf("ERROR: error reading source file: %v\n", err)
 		return
 	}
-
-	srcScanner := bufio.NewScanner(srcReference)
-	tokenWriter := bufio.NewWriter(tokenReference)
-
-	// Jalankan Lexer baris per baris
-	for srcScanner.Scan() {
-		line := srcScanner.Text()
-		// Panggil Lexer
-		milestone1.LexicalAnalyzer(line, *dfa, &currentState, tokenWriter)
+	if dumpTokens {
+		for _, token := range tokens {
+			fmt.Println(token)
+		}
+	}
+	if len(lexer.Diagnostics()) > 0 {
+		fmt.Println("\nLexical errors:")
+		diagnostic.RenderText(os.Stdout, srcFile, lexer.Diagnostics())
+		diagnostics = append(diagnostics, lexer.Diagnostics()...)
 	}
 
-	tokenWriter.Flush()
-	tokenReference.Close()
+	// Pastikan folder output ada
+	os.MkdirAll("../test/output", os.ModePerm)
 
-	// 3. SYNTAX ANALYZER
-	// Inisialisasi root node untuk tree
-	var root = milestone2.AbstractSyntaxTree{
-		Value:    "<program>",
-		Children: []*milestone2.AbstractSyntaxTree{},
+	// Ekspor DFA (DOT untuk diagram, JSON untuk tools)
+	if export {
+		exportFile("dfa.dot", func(w io.Writer) error { return milestone1.WriteDFADOT(w, dfa) })
+		exportFile("dfa.json", func(w io.Writer) error { return milestone1.WriteDFAJSON(w, dfa) })
 	}
 
-	// Tentukan path file tokens.txt secara absolut
-	_, filename, _, _ := runtime.Caller(0)
-	base := filepath.Dir(filename)
-	path := filepath.Join(base, "..", "test", "output", "tokens.txt")
-
-	lexResultReferenceBytes, err := os.ReadFile(path)
-	if err != nil {
-		// Fallback path manual jika runtime caller gagal
-		path = "../test/output/tokens.txt"
-		lexResultReferenceBytes, err = os.ReadFile(path)
+	// Dump token ke tokens.txt (hanya untuk dilihat, tidak dibaca lagi)
+	if dumpTokens {
+		tokenReference, err := os.Create("../test/output/tokens.txt")
 		if err != nil {
-			fmt.Printf("ERROR: Gagal membaca file tokens.txt: %v\n", err)
+			fmt.Printf("ERROR: error creating token file: %v\n", err)
 			return
 		}
+		milestone1.WriteTokens(tokenReference, tokens)
+		tokenReference.Close()
 	}
 
-	// Convert isi file jadi array string token
-	lexResultStr := string(lexResultReferenceBytes)
-	lexResultStr = strings.ReplaceAll(lexResultStr, "\r", "") // Bersihkan windows newline
-	lexResult := strings.Split(lexResultStr, "\n")
-
-	// Bersihkan string kosong dari array
-	var cleanLexResult []string
-	for _, s := range lexResult {
-		if strings.TrimSpace(s) != "" {
-			cleanLexResult = append(cleanLexResult, s)
-		}
-	}
-
+	// 3. SYNTAX ANALYZER
 	// Siapkan file output untuk Tree
 	treeReference, err := os.Create("../test/output/abstract-syntax-tree.txt")
 	if err != nil {
@@ -148,23 +144,50 @@ func main() {
 
 	// Jalankan Syntax Analyzer
 	fmt.Println("Menjalankan Syntax Analysis...")
-	result := milestone2.SyntaxAnalyzer(cleanLexResult, &root)
+	parser := milestone2.NewParser(tokens)
+	parser.SetDialect(dialect)
+	root, err := parser.ParseProgram()
+	syntaxOK := err == nil
+	if !syntaxOK {
+		// Parser melanjutkan parsing setelah error, jadi semua error sintaks dilaporkan sekaligus
+		// Token ERROR tidak dilaporkan ulang (sudah ada di error leksikal)
+		if len(parser.Errors()) > 0 {
+			fmt.Println("\n[Syntax Error]")
+			diagnostic.RenderText(os.Stdout, srcFile, parser.Errors())
+			diagnostics = append(diagnostics, parser.Errors()...)
+		}
+		fmt.Println("Syntax Analysis Gagal.")
+		treeWriter.WriteString("Syntax error found.\n")
+	}
 
-	if result == 0 { // 0 = Sukses
-		fmt.Println("Syntax Analysis Berhasil! Tree dicetak ke file & terminal.")
+	// Tree parsial (dengan node <error>) tetap dianalisis supaya error semantik ikut terlihat
+	if root != nil {
+		if syntaxOK {
+			fmt.Println("Syntax Analysis Berhasil! Tree dicetak ke file & terminal.")
+		} else {
+			fmt.Println("Tree parsial dicetak ke file & terminal.")
+		}
 
 		// Print ke Terminal
-		milestone2.PrintAbstractSyntaxTree(&root, os.Stdout, "", true)
+		milestone2.PrintAbstractSyntaxTree(root, os.Stdout, "", true)
 
 		// Print ke File
-		milestone2.PrintAbstractSyntaxTree(&root, treeWriter, "", true)
+		milestone2.PrintAbstractSyntaxTree(root, treeWriter, "", true)
+
+		if export {
+			exportFile("parse-tree.dot", func(w io.Writer) error { return milestone2.WriteTreeDOT(w, root) })
+			exportFile("parse-tree.json", func(w io.Writer) error { return milestone2.WriteTreeJSON(w, root) })
+		}
 
 		fmt.Println("\n========== MILESTONE 3: SEMANTIC ANALYSIS ==========")
 
 		// Perform semantic analysis
 		fmt.Println("Performing semantic analysis...")
-		analyzer := milestone3.NewSemanticAnalyzer()
-		decoratedAST, err := analyzer.Analyze(&root)
+		analyzer := milestone3.NewSemanticAnalyzerWithDialect(dialect)
+		decoratedAST, err := analyzer.Analyze(root)
+		semanticOK := err == nil
+		diagnostics = append(diagnostics, analyzer.GetErrors()...)
+		diagnostics = append(diagnostics, analyzer.GetWarnings()...)
 
 		if err != nil {
 			fmt.Printf("Semantic analysis failed: %v\n", err)
@@ -172,17 +195,13 @@ func main() {
 			// Print errors
 			if len(analyzer.GetErrors()) > 0 {
 				fmt.Println("\nSemantic errors:")
-				for i, errMsg := range analyzer.GetErrors() {
-					fmt.Printf("  %d. %s\n", i+1, errMsg)
-				}
+				diagnostic.RenderText(os.Stdout, srcFile, analyzer.GetErrors())
 			}
 
 			// Print warnings
 			if len(analyzer.GetWarnings()) > 0 {
 				fmt.Println("\nSemantic warnings:")
-				for i, warnMsg := range analyzer.GetWarnings() {
-					fmt.Printf("  %d. %s\n", i+1, warnMsg)
-				}
+				diagnostic.RenderText(os.Stdout, srcFile, analyzer.GetWarnings())
 			}
 		} else {
 			fmt.Println("Semantic analysis completed successfully")
@@ -190,9 +209,7 @@ func main() {
 			// Print warnings if any
 			if len(analyzer.GetWarnings()) > 0 {
 				fmt.Println("\nSemantic warnings:")
-				for i, warnMsg := range analyzer.GetWarnings() {
-					fmt.Printf("  %d. %s\n", i+1, warnMsg)
-				}
+				diagnostic.RenderText(os.Stdout, srcFile, analyzer.GetWarnings())
 			}
 		}
 
@@ -206,7 +223,7 @@ func main() {
 		// Print decorated AST
 		if decoratedAST != nil {
 			fmt.Println("\n========== DECORATED AST ==========")
-			milestone3.PrintDecoratedAST(decoratedAST, "", true)
+			milestone3.PrintDecoratedAST(os.Stdout, decoratedAST, "", true)
 		}
 
 		// Save symbol table to file
@@ -244,34 +261,127 @@ func main() {
 			if err == nil {
 				defer decoratedASTFile.Close()
 				decoratedASTWriter := bufio.NewWriter(decoratedASTFile)
+				milestone3.PrintDecoratedAST(decoratedASTWriter, decoratedAST, "", true)
+				decoratedASTWriter.Flush()
+				fmt.Println("Decorated AST saved to ../test/output/decorated-ast.txt")
+			}
+			if export {
+				exportFile("decorated-ast.dot", func(w io.Writer) error { return milestone3.WriteDecoratedDOT(w, decoratedAST) })
+				exportFile("decorated-ast.json", func(w io.Writer) error { return milestone3.WriteDecoratedJSON(w, decoratedAST) })
+			}
+		}
 
-				oldStdout := os.Stdout
-				r, w, _ := os.Pipe()
-				os.Stdout = w
-
-				milestone3.PrintDecoratedAST(decoratedAST, "", true)
+		// Terjemahkan ke Pascal standar (hanya jika analisis semantik bersih)
+		if syntaxOK && semanticOK && translate {
+			diagnostics = append(diagnostics, translateProgram(srcFile, tokens, root, analyzer)...)
+		}
 
-				w.Close()
-				os.Stdout = oldStdout
+		// Jalankan program (hanya jika analisis semantik bersih)
+		if syntaxOK && semanticOK && decoratedAST != nil && usePCode {
+			// Generate P-code lalu jalankan di stack machine
+			generator := pcode.NewCodeGenerator(symTable)
+			program, genErr := generator.Generate(decoratedAST)
+			if genErr != nil {
+				fmt.Printf("\nCode generation failed: %v\n", genErr)
+				for i, errMsg := range generator.GetErrors() {
+					fmt.Printf("  %d. %s\n", i+1, errMsg)
+				}
+			} else {
+				fmt.Println("\n========== P-CODE ==========")
+				program.PrintCode(os.Stdout)
+
+				pcodeFile, err := os.Create("../test/output/pcode.txt")
+				if err == nil {
+					pcodeWriter := bufio.NewWriter(pcodeFile)
+					program.PrintCode(pcodeWriter)
+					pcodeWriter.Flush()
+					pcodeFile.Close()
+					fmt.Println("P-code saved to ../test/output/pcode.txt")
+				}
 
-				buf := make([]byte, 1024)
-				for {
-					n, err := r.Read(buf)
-					if n > 0 {
-						decoratedASTWriter.Write(buf[:n])
-					}
-					if err != nil {
-						break
-					}
+				fmt.Println("\n========== PROGRAM OUTPUT ==========")
+				machine := pcode.NewMachine(program, symTable, os.Stdin, os.Stdout)
+				if runErr := machine.Run(); runErr != nil {
+					fmt.Printf("\nRuntime error: %v\n", runErr)
 				}
-				decoratedASTWriter.Flush()
-				fmt.Println("Decorated AST saved to ../test/output/decorated-ast.txt")
+			}
+		} else if syntaxOK && semanticOK && decoratedAST != nil {
+			fmt.Println("\n========== PROGRAM OUTPUT ==========")
+			interp := interpreter.NewInterpreter(symTable, os.Stdin, os.Stdout)
+			if runErr := interp.Run(decoratedAST); runErr != nil {
+				fmt.Printf("\nRuntime error: %v\n", runErr)
 			}
 		}
-	} else {
-		fmt.Println("Syntax Analysis Gagal.")
-		treeWriter.WriteString("Syntax error found.")
 	}
 
 	treeWriter.Flush()
 }
+
+// Simpan semua diagnostic ke diagnostics.json (dan cetak JSON ke terminal jika diminta)
+func writeDiagnostics(srcFile string, diagnostics []diagnostic.Diagnostic, toStdout bool) {
+	if toStdout {
+		fmt.Println("\n========== DIAGNOSTICS (JSON) ==========")
+		diagnostic.RenderJSON(os.Stdout, srcFile, diagnostics)
+	}
+
+	diagnosticsFile, err := os.Create("../test/output/diagnostics.json")
+	if err != nil {
+		return
+	}
+	defer diagnosticsFile.Close()
+	diagnostic.RenderJSON(diagnosticsFile, srcFile, diagnostics)
+}
+
+// Tulis hasil ekspor (DOT/JSON) ke ../test/output/<name>
+func exportFile(name string, write func(io.Writer) error) {
+	path := "../test/output/" + name
+	file, err := os.Create(path)
+	if err != nil {
+		fmt.Printf("ERROR: error creating %s: %v\n", path, err)
+		return
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+	if err := write(writer); err != nil {
+		fmt.Printf("ERROR: error writing %s: %v\n", path, err)
+		return
+	}
+	writer.Flush()
+	fmt.Printf("Ekspor disimpan ke %s\n", path)
+}
+
+// Dialek keyword: nama dialek bawaan atau path file pemetaan keyword
+func loadDialect(spec string) (*milestone1.Dialect, error) {
+	if dialect, ok := milestone1.DialectByName(spec); ok {
+		return dialect, nil
+	}
+	file, err := os.Open(spec)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return milestone1.LoadDialect(file, spec)
+}
+
+// Tulis program sebagai Pascal standar ke ../test/output/translated.pas
+// Program yang tidak bisa dinyatakan dalam ISO Pascal tidak ditulis; diagnostic-nya dikembalikan
+func translateProgram(srcFile string, tokens []milestone1.Token, root *milestone2.AbstractSyntaxTree, analyzer *milestone3.SemanticAnalyzer) []diagnostic.Diagnostic {
+	source, err := os.ReadFile(srcFile)
+	if err != nil {
+		fmt.Printf("ERROR: error reading source file: %v\n", err)
+		return nil
+	}
+	t := translator.NewTranslator(source, tokens, analyzer)
+	var translated bytes.Buffer
+	if err := t.Translate(&translated, root); err != nil {
+		fmt.Printf("\nTranslation failed: %v\n", err)
+		diagnostic.RenderText(os.Stdout, srcFile, t.Diagnostics())
+		return t.Diagnostics()
+	}
+	if err := os.WriteFile("../test/output/translated.pas", translated.Bytes(), 0644); err != nil {
+		fmt.Printf("ERROR: error writing translated file: %v\n", err)
+		return nil
+	}
+	fmt.Println("Program Pascal standar disimpan ke ../test/output/translated.pas")
+	return nil
+}
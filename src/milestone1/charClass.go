@@ -0,0 +1,253 @@
+package milestone1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CharSet - himpunan byte input sebuah transisi (bitset 256 bit)
+type CharSet [4]uint64
+
+func (s *CharSet) Add(c byte) {
+	s[c/64] |= 1 << (c % 64)
+}
+
+func (s *CharSet) AddRange(low, high byte) {
+	for c := int(low); c <= int(high); c++ {
+		s.Add(byte(c))
+	}
+}
+
+func (s CharSet) Has(c byte) bool {
+	return s[c/64]&(1<<(c%64)) != 0
+}
+
+func (s CharSet) Negate() CharSet {
+	return CharSet{^s[0], ^s[1], ^s[2], ^s[3]}
+}
+
+func (s CharSet) Intersect(other CharSet) CharSet {
+	return CharSet{s[0] & other[0], s[1] & other[1], s[2] & other[2], s[3] & other[3]}
+}
+
+func (s CharSet) IsEmpty() bool {
+	return s == CharSet{}
+}
+
+// Teks ringkas isi himpunan untuk pesan error, misal "a-z, _"
+func (s CharSet) String() string {
+	var parts []string
+	for c := 0; c < 256; c++ {
+		if !s.Has(byte(c)) {
+			continue
+		}
+		end := c
+		for end+1 < 256 && s.Has(byte(end+1)) {
+			end++
+		}
+		switch {
+		case end == c:
+			parts = append(parts, charName(byte(c)))
+		default:
+			parts = append(parts, charName(byte(c))+"-"+charName(byte(end)))
+		}
+		c = end
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (s CharSet) Count() int {
+	count := 0
+	for c := 0; c < 256; c++ {
+		if s.Has(byte(c)) {
+			count++
+		}
+	}
+	return count
+}
+
+// Input - teks kolom input di file DFA untuk himpunan ini (kebalikan parseInput)
+// Satu karakter ditulis langsung, selebihnya sebagai kelas (kelas negasi jika lebih pendek)
+func (s CharSet) Input() string {
+	if s.Count() == 1 {
+		for c := 0; c < 256; c++ {
+			if s.Has(byte(c)) && c > ' ' && c < 0x7f {
+				return string(rune(c))
+			}
+		}
+		for name, value := range namedInputs {
+			if s.Has(value) {
+				return name
+			}
+		}
+	}
+	class := "[" + s.classBody() + "]"
+	if negated := "[^" + s.Negate().classBody() + "]"; s.Count() < 256 && len(negated) < len(class) {
+		return negated
+	}
+	return class
+}
+
+// Isi kelas karakter dengan range, karakter khusus di-escape
+func (s CharSet) classBody() string {
+	var sb strings.Builder
+	for c := 0; c < 256; c++ {
+		if !s.Has(byte(c)) {
+			continue
+		}
+		end := c
+		for end+1 < 256 && s.Has(byte(end+1)) {
+			end++
+		}
+		sb.WriteString(classChar(byte(c)))
+		if end > c+1 {
+			sb.WriteByte('-')
+		}
+		if end > c {
+			sb.WriteString(classChar(byte(end)))
+		}
+		c = end
+	}
+	return sb.String()
+}
+
+func classChar(c byte) string {
+	switch {
+	case c == ' ':
+		return "\\s"
+	case c == '\t':
+		return "\\t"
+	case c == '\n':
+		return "\\n"
+	case c == ']' || c == '\\' || c == '-' || c == '^':
+		return "\\" + string(c)
+	case c < 0x20 || c >= 0x7f:
+		return fmt.Sprintf("\\x%02x", c)
+	}
+	return string(c)
+}
+
+// Nama khusus input yang tidak bisa ditulis langsung di file DFA
+var namedInputs = map[string]byte{
+	"SPACE":   ' ',
+	"TAB":     '\t',
+	"NEWLINE": '\n',
+}
+
+func charName(c byte) string {
+	for name, value := range namedInputs {
+		if value == c {
+			return name
+		}
+	}
+	if c < 0x20 || c >= 0x7f {
+		return fmt.Sprintf("\\x%02x", c)
+	}
+	return string(c)
+}
+
+// parseInput baca kolom input sebuah transisi:
+//
+//	a          satu karakter
+//	SPACE      nama khusus (SPACE, TAB, NEWLINE)
+//	[a-zA-Z_]  kelas karakter dengan range
+//	[^']       kelas negasi (semua karakter kecuali isi kelas)
+//	ANY        transisi default, dipakai jika tidak ada transisi lain yang cocok
+//
+// Di dalam kelas, \s = spasi, \t = tab, \n = newline, \xHH = byte heksadesimal,
+// dan \] \\ \- \^ untuk karakter itu sendiri
+func parseInput(input string) (chars CharSet, any bool, err error) {
+	if input == "ANY" {
+		return CharSet{}, true, nil
+	}
+	if c, ok := namedInputs[input]; ok {
+		chars.Add(c)
+		return chars, false, nil
+	}
+	if len(input) == 1 {
+		chars.Add(input[0])
+		return chars, false, nil
+	}
+	if !strings.HasPrefix(input, "[") || !strings.HasSuffix(input, "]") || len(input) < 3 {
+		return chars, false, fmt.Errorf("invalid input '%s': expected a single character, SPACE, TAB, NEWLINE, ANY or a [class]", input)
+	}
+
+	chars, err = parseClass(input[1 : len(input)-1])
+	if err != nil {
+		return chars, false, fmt.Errorf("invalid input '%s': %v", input, err)
+	}
+	return chars, false, nil
+}
+
+// parseClass baca isi kelas karakter (tanpa kurung siku), termasuk ^ di awal untuk negasi
+func parseClass(body string) (CharSet, error) {
+	var chars CharSet
+	negate := strings.HasPrefix(body, "^") && len(body) > 1
+	if negate {
+		body = body[1:]
+	}
+
+	// Uraikan escape dulu supaya range dan karakter literal bisa dibedakan
+	type classChar struct {
+		c       byte
+		literal bool // hasil escape, jadi '-' tidak dianggap operator range
+	}
+	var items []classChar
+	for i := 0; i < len(body); i++ {
+		if body[i] != '\\' {
+			items = append(items, classChar{c: body[i]})
+			continue
+		}
+		c, size, err := parseEscape(body[i:])
+		if err != nil {
+			return chars, err
+		}
+		items = append(items, classChar{c: c, literal: true})
+		i += size - 1
+	}
+
+	for i := 0; i < len(items); i++ {
+		if i+2 < len(items) && items[i+1].c == '-' && !items[i+1].literal {
+			low, high := items[i].c, items[i+2].c
+			if low > high {
+				return chars, fmt.Errorf("range %s-%s is reversed", charName(low), charName(high))
+			}
+			chars.AddRange(low, high)
+			i += 2
+			continue
+		}
+		chars.Add(items[i].c)
+	}
+
+	if negate {
+		chars = chars.Negate()
+	}
+	return chars, nil
+}
+
+// parseEscape baca escape di awal s (s[0] == '\\'), kembalikan karakter dan panjang escape
+// \s = spasi, \t = tab, \n = newline, \xHH = byte heksadesimal, selain itu karakter itu sendiri
+func parseEscape(s string) (byte, int, error) {
+	if len(s) < 2 {
+		return 0, 0, fmt.Errorf("dangling '\\'")
+	}
+	switch s[1] {
+	case 's':
+		return ' ', 2, nil
+	case 't':
+		return '\t', 2, nil
+	case 'n':
+		return '\n', 2, nil
+	case 'x':
+		if len(s) < 4 {
+			return 0, 0, fmt.Errorf("incomplete escape '%s'", s)
+		}
+		value, err := strconv.ParseUint(s[2:4], 16, 8)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid escape '%s'", s[:4])
+		}
+		return byte(value), 4, nil
+	}
+	return s[1], 2, nil
+}
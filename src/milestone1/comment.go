@@ -0,0 +1,57 @@
+package milestone1
+
+import (
+	"compiler/diagnostic"
+	"strings"
+)
+
+// commentState - komentar blok yang sedang dibaca, bisa melewati beberapa baris
+// Komentar { ... } hanya ditutup oleh }, komentar (* ... *) hanya ditutup oleh *)
+type commentState struct {
+	closer string // "}" atau "*)", kosong jika tidak sedang di dalam komentar
+	start  Position
+	text   strings.Builder
+}
+
+func (c *commentState) open() bool {
+	return c.closer != ""
+}
+
+// Mulai komentar jika line[i:] diawali pembuka komentar, kembalikan posisi setelah pembuka
+func (c *commentState) begin(line string, i int, pos Position) (int, bool) {
+	var opener string
+	switch {
+	case strings.HasPrefix(line[i:], "{"):
+		opener, c.closer = "{", "}"
+	case strings.HasPrefix(line[i:], "(*"):
+		opener, c.closer = "(*", "*)"
+	default:
+		return i, false
+	}
+	c.start = pos
+	c.text.Reset()
+	c.text.WriteString(opener)
+	return i + len(opener), true
+}
+
+// Baca isi komentar mulai line[i:] sampai penutup atau akhir baris
+// Kembalikan posisi setelah bagian yang dibaca dan token COMMENT jika komentar sudah ditutup
+func (c *commentState) consume(line string, i int) (int, *Token) {
+	end := strings.Index(line[i:], c.closer)
+	if end == -1 {
+		// Komentar berlanjut ke baris berikutnya
+		c.text.WriteString(line[i:])
+		c.text.WriteByte('\n')
+		return len(line), nil
+	}
+	end += i + len(c.closer)
+	c.text.WriteString(line[i:end])
+	c.closer = ""
+	return end, &Token{Kind: KindComment, Lexeme: c.text.String(), Pos: c.start}
+}
+
+// Diagnostic untuk komentar yang belum ditutup sampai akhir file (span = pembuka komentar)
+func (c *commentState) unterminated() *diagnostic.Diagnostic {
+	return diagnostic.Errorf(diagnostic.UnterminatedComment, diagnostic.Range(c.start, len(c.closer)),
+		"unterminated comment: missing '%s' before end of file", c.closer)
+}
@@ -1,12 +1,121 @@
 package milestone1
 
-type TransitionKey struct {
-	State string
-	Input string
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Transition - satu baris transisi di file DFA: <state> <input> <next state>
+type Transition struct {
+	From  string
+	Input string  // Teks input seperti ditulis di file (a, SPACE, [a-z], ANY)
+	Chars CharSet // Byte yang diterima (kosong untuk ANY)
+	Any   bool    // Transisi default
+	To    string
+	Line  int // Baris di file DFA (0 jika tidak dibaca dari file)
 }
 
 type DFA struct {
-	StartState string
-	FinalState []string
-	Transition map[TransitionKey]string
+	StartState  string
+	FinalState  []string
+	Transitions []Transition
+
+	table *transitionTable // Tabel ringkas yang dipakai lexer, dibuat dari Transitions
+	spec  dfaSpec          // Lokasi definisi di file DFA, dipakai Validate
+}
+
+// dfaSpec - catatan LoadDFA tentang file DFA (baris definisi dan baris rusak)
+type dfaSpec struct {
+	startLine int
+	finalLine int
+	malformed []specLine
+}
+
+type specLine struct {
+	Text    string
+	Message string
+	Line    int
+}
+
+// LoadDFA baca definisi DFA (format dfa.txt)
+// Input boleh berupa kelas karakter (lihat parseInput); jika beberapa baris menerima karakter
+// yang sama dari state yang sama, baris terakhir yang berlaku dan Validate melaporkannya
+func LoadDFA(reader io.Reader) (*DFA, error) {
+	dfa := &DFA{}
+
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		// Skip komentar dan baris kosong
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		if strings.Contains(line, "Start_state") {
+			dfa.StartState = strings.TrimSpace(strings.TrimPrefix(line, "Start_state = "))
+			dfa.spec.startLine = lineNumber
+		} else if strings.Contains(line, "Final_state") {
+			finalStatesStr := strings.TrimSpace(strings.TrimPrefix(line, "Final_state = "))
+			finalStates := strings.Split(finalStatesStr, ", ")
+			for i := range finalStates {
+				finalStates[i] = strings.TrimSpace(finalStates[i])
+			}
+			dfa.FinalState = finalStates
+			dfa.spec.finalLine = lineNumber
+		} else {
+			// Baca transisi state
+			elements := strings.Fields(line)
+			if len(elements) != 3 {
+				dfa.spec.malformed = append(dfa.spec.malformed, specLine{
+					Text: line, Message: "expected '<state> <input> <next state>'", Line: lineNumber,
+				})
+			}
+			if len(elements) >= 3 {
+				chars, any, err := parseInput(elements[1])
+				if err != nil {
+					dfa.spec.malformed = append(dfa.spec.malformed, specLine{Text: line, Message: err.Error(), Line: lineNumber})
+					continue
+				}
+				dfa.Transitions = append(dfa.Transitions, Transition{
+					From: elements[0], Input: elements[1], Chars: chars, Any: any, To: elements[2], Line: lineNumber,
+				})
+			}
+		}
+	}
+
+	dfa.table = buildTransitionTable(dfa)
+	return dfa, scanner.Err()
+}
+
+// Tabel transisi ringkas (sudah dibuat oleh LoadDFA dan GenerateDFA; DFA lain mendapatkannya di sini)
+// Panggil sekali lalu simpan hasilnya (seperti NewLexer), karena DFA sering disalin by value
+func (dfa *DFA) compiled() *transitionTable {
+	if dfa.table == nil {
+		dfa.table = buildTransitionTable(dfa)
+	}
+	return dfa.table
+}
+
+// WriteDFA tulis DFA dalam format dfa.txt (bisa dibaca lagi oleh LoadDFA)
+func WriteDFA(writer io.Writer, dfa *DFA) error {
+	bufWriter := bufio.NewWriter(writer)
+	fmt.Fprintf(bufWriter, "Start_state = %s\n", dfa.StartState)
+	fmt.Fprintf(bufWriter, "Final_state = %s\n", strings.Join(dfa.FinalState, ", "))
+	previous := ""
+	for _, tr := range dfa.Transitions {
+		if tr.From != previous {
+			bufWriter.WriteString("\n")
+			previous = tr.From
+		}
+		input := tr.Input
+		if input == "" {
+			input = tr.Chars.Input()
+		}
+		fmt.Fprintf(bufWriter, "%s %s %s\n", tr.From, input, tr.To)
+	}
+	return bufWriter.Flush()
 }
@@ -0,0 +1,107 @@
+package milestone1
+
+import (
+	"compiler/dot"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// Ekspor DFA ke Graphviz DOT dan JSON
+// State dan transisi ditulis dalam urutan kemunculan di file DFA supaya output stabil
+
+type jsonDFA struct {
+	Start       string           `json:"start"`
+	Final       []string         `json:"final"`
+	States      []string         `json:"states"`
+	Transitions []jsonTransition `json:"transitions"`
+}
+
+type jsonTransition struct {
+	From  string `json:"from"`
+	Input string `json:"input"` // Seperti di dfa.txt: a, SPACE, [a-z], ANY
+	To    string `json:"to"`
+	Line  int    `json:"line,omitempty"`
+}
+
+// WriteDFAJSON tulis DFA sebagai JSON
+func WriteDFAJSON(writer io.Writer, dfa *DFA) error {
+	result := jsonDFA{
+		Start:       dfa.StartState,
+		Final:       append([]string{}, dfa.FinalState...),
+		States:      orderedStates(dfa),
+		Transitions: make([]jsonTransition, 0, len(dfa.Transitions)),
+	}
+	for _, tr := range dfa.Transitions {
+		result.Transitions = append(result.Transitions, jsonTransition{From: tr.From, Input: transitionInput(tr), To: tr.To, Line: tr.Line})
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// WriteDFADOT tulis DFA sebagai graf DOT
+// Transisi antar pasangan state yang sama digabung jadi satu panah berlabel semua inputnya
+func WriteDFADOT(writer io.Writer, dfa *DFA) error {
+	graph := dot.NewGraph("DFA")
+	graph.Attr("rankdir=LR")
+	graph.Attr("node [shape=circle]")
+	graph.NamedNode("__start", "", "shape=point")
+
+	finals := make(map[string]bool)
+	for _, state := range dfa.FinalState {
+		finals[state] = true
+	}
+	for _, state := range orderedStates(dfa) {
+		if finals[state] {
+			graph.NamedNode(state, state, "shape=doublecircle")
+		} else {
+			graph.NamedNode(state, state)
+		}
+	}
+	graph.Edge("__start", dfa.StartState, "")
+
+	type statePair struct{ from, to string }
+	var pairs []statePair
+	inputs := make(map[statePair][]string)
+	for _, tr := range dfa.Transitions {
+		pair := statePair{tr.From, tr.To}
+		if _, seen := inputs[pair]; !seen {
+			pairs = append(pairs, pair)
+		}
+		inputs[pair] = append(inputs[pair], transitionInput(tr))
+	}
+	for _, pair := range pairs {
+		graph.Edge(pair.from, pair.to, strings.Join(inputs[pair], " "))
+	}
+	return graph.Write(writer)
+}
+
+// Semua state: start dulu, lalu urutan kemunculan di transisi, lalu final state yang belum muncul
+func orderedStates(dfa *DFA) []string {
+	seen := make(map[string]bool)
+	var states []string
+	add := func(state string) {
+		if state != "" && !seen[state] {
+			seen[state] = true
+			states = append(states, state)
+		}
+	}
+	add(dfa.StartState)
+	for _, tr := range dfa.Transitions {
+		add(tr.From)
+		add(tr.To)
+	}
+	for _, state := range dfa.FinalState {
+		add(state)
+	}
+	return states
+}
+
+// Teks input transisi (DFA hasil generator tidak menyimpan teks aslinya)
+func transitionInput(tr Transition) string {
+	if tr.Input != "" {
+		return tr.Input
+	}
+	return tr.Chars.Input()
+}
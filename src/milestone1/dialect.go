@@ -0,0 +1,227 @@
+package milestone1
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Keyword - jenis keyword kanonik, tidak bergantung dialek
+// Nilainya = ejaan dialek Indonesia, dan itu juga yang dipakai node daun parse tree
+type Keyword string
+
+const (
+	KwProgram   Keyword = "program"
+	KwVar       Keyword = "variabel"
+	KwBegin     Keyword = "mulai"
+	KwEnd       Keyword = "selesai"
+	KwIf        Keyword = "jika"
+	KwThen      Keyword = "maka"
+	KwElse      Keyword = "selain_itu"
+	KwWhile     Keyword = "selama"
+	KwDo        Keyword = "lakukan"
+	KwFor       Keyword = "untuk"
+	KwTo        Keyword = "ke"
+	KwDownTo    Keyword = "turun_ke"
+	KwInteger   Keyword = "integer"
+	KwReal      Keyword = "real"
+	KwBoolean   Keyword = "boolean"
+	KwChar      Keyword = "char"
+	KwString    Keyword = "string"
+	KwArray     Keyword = "larik"
+	KwOf        Keyword = "dari"
+	KwProcedure Keyword = "prosedur"
+	KwFunction  Keyword = "fungsi"
+	KwConst     Keyword = "konstanta"
+	KwType      Keyword = "tipe"
+	KwTrue      Keyword = "true"
+	KwFalse     Keyword = "false"
+	KwRepeat    Keyword = "ulangi"
+	KwUntil     Keyword = "sampai"
+	KwCase      Keyword = "kasus"
+	KwRecord    Keyword = "rekaman"
+	KwDiv       Keyword = "bagi"
+	KwMod       Keyword = "mod"
+	KwAnd       Keyword = "dan"
+	KwOr        Keyword = "atau"
+	KwNot       Keyword = "tidak"
+	KwPacked    Keyword = "padat" // Hanya reserved word di tabel simbol, tidak dikenali lexer
+)
+
+// Semua jenis keyword (urutan dipakai untuk daftar ejaan)
+var allKeywords = []Keyword{
+	KwProgram, KwVar, KwBegin, KwEnd, KwIf, KwThen, KwElse, KwWhile, KwDo, KwFor, KwTo, KwDownTo,
+	KwInteger, KwReal, KwBoolean, KwChar, KwString, KwArray, KwOf, KwProcedure, KwFunction,
+	KwConst, KwType, KwTrue, KwFalse, KwRepeat, KwUntil, KwCase, KwRecord,
+	KwDiv, KwMod, KwAnd, KwOr, KwNot, KwPacked,
+}
+
+// Jenis token untuk keyword yang sebenarnya operator
+func (kw Keyword) Kind() TokenKind {
+	switch kw {
+	case KwDiv, KwMod:
+		return KindArithmeticOperator
+	case KwAnd, KwOr, KwNot:
+		return KindLogicalOperator
+	}
+	return KindKeyword
+}
+
+// Dialect - pemetaan ejaan keyword di source ke jenis keyword kanonik
+type Dialect struct {
+	Name      string
+	keywords  map[string]Keyword // Ejaan (huruf kecil) -> jenis
+	spellings map[Keyword]string // Jenis -> ejaan utama (pesan error, tabel simbol)
+}
+
+var (
+	// Indonesian - Pascal-S dialek Indonesia (default)
+	Indonesian = newDialect("indonesian", nil)
+
+	// English - Pascal-S standar seperti di buku teks
+	English = newDialect("english", map[Keyword]string{
+		KwVar: "var", KwBegin: "begin", KwEnd: "end", KwIf: "if", KwThen: "then", KwElse: "else",
+		KwWhile: "while", KwDo: "do", KwFor: "for", KwTo: "to", KwDownTo: "downto",
+		KwArray: "array", KwOf: "of", KwProcedure: "procedure", KwFunction: "function",
+		KwConst: "const", KwType: "type", KwRepeat: "repeat", KwUntil: "until", KwCase: "case",
+		KwRecord: "record", KwDiv: "div", KwAnd: "and", KwOr: "or", KwNot: "not", KwPacked: "packed",
+	})
+)
+
+// Dialek bawaan berdasarkan nama
+var dialects = map[string]*Dialect{
+	Indonesian.Name: Indonesian,
+	English.Name:    English,
+}
+
+// DialectByName cari dialek bawaan ("indonesian" atau "english")
+func DialectByName(name string) (*Dialect, bool) {
+	dialect, ok := dialects[strings.ToLower(name)]
+	return dialect, ok
+}
+
+// Dialek dengan ejaan dari overrides; jenis yang tidak disebut memakai ejaan kanonik
+func newDialect(name string, overrides map[Keyword]string) *Dialect {
+	d := &Dialect{
+		Name:      name,
+		keywords:  make(map[string]Keyword),
+		spellings: make(map[Keyword]string),
+	}
+	for _, kw := range allKeywords {
+		spelling, ok := overrides[kw]
+		if !ok {
+			spelling = string(kw)
+		}
+		d.spellings[kw] = spelling
+		d.keywords[spelling] = kw
+	}
+	return d
+}
+
+// Keyword - jenis keyword untuk lexeme (tidak peka huruf besar/kecil)
+func (d *Dialect) Keyword(lexeme string) (Keyword, bool) {
+	kw, ok := d.keywords[strings.ToLower(lexeme)]
+	if !ok || kw == KwPacked {
+		return "", false
+	}
+	return kw, true
+}
+
+// Spell - ejaan utama jenis keyword di dialek ini
+func (d *Dialect) Spell(kw Keyword) string {
+	if spelling, ok := d.spellings[kw]; ok {
+		return spelling
+	}
+	return string(kw)
+}
+
+// Classify tentukan jenis token dari lexeme hasil DFA; keyword diisi untuk keyword dan operator kata
+func (d *Dialect) Classify(token string) (TokenKind, Keyword) {
+	if kw, ok := d.Keyword(token); ok {
+		return kw.Kind(), kw
+	}
+	return classifyLexeme(token), ""
+}
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// LoadDialect baca file pemetaan keyword:
+//
+//	# komentar
+//	base english          (opsional, dialek awal; default indonesian)
+//	mulai begin start     (<jenis kanonik> <ejaan> [ejaan lain...])
+//
+// Ejaan pertama jadi ejaan utama; ejaan lama jenis yang dipetakan tidak lagi menjadi keyword
+func LoadDialect(reader io.Reader, name string) (*Dialect, error) {
+	canonical := make(map[string]Keyword)
+	for _, kw := range allKeywords {
+		canonical[string(kw)] = kw
+	}
+
+	base := Indonesian
+	mapped := make(map[Keyword][]string)
+	var order []Keyword
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if fields[0] == "base" {
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("line %d: expected 'base <dialect>'", lineNumber)
+			}
+			dialect, ok := DialectByName(fields[1])
+			if !ok {
+				return nil, fmt.Errorf("line %d: unknown base dialect '%s'", lineNumber, fields[1])
+			}
+			base = dialect
+			continue
+		}
+
+		kw, ok := canonical[strings.ToLower(fields[0])]
+		if !ok {
+			return nil, fmt.Errorf("line %d: unknown keyword kind '%s'", lineNumber, fields[0])
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: expected '<keyword kind> <spelling>...'", lineNumber)
+		}
+		for _, spelling := range fields[1:] {
+			if !identifierPattern.MatchString(spelling) {
+				return nil, fmt.Errorf("line %d: spelling '%s' is not a valid identifier", lineNumber, spelling)
+			}
+		}
+		if _, seen := mapped[kw]; !seen {
+			order = append(order, kw)
+		}
+		mapped[kw] = append(mapped[kw], fields[1:]...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Mulai dari dialek dasar, lalu ganti ejaan jenis yang dipetakan
+	d := &Dialect{Name: name, keywords: make(map[string]Keyword), spellings: make(map[Keyword]string)}
+	for _, kw := range allKeywords {
+		if _, ok := mapped[kw]; !ok {
+			d.spellings[kw] = base.spellings[kw]
+			d.keywords[base.spellings[kw]] = kw
+		}
+	}
+	for _, kw := range order {
+		d.spellings[kw] = strings.ToLower(mapped[kw][0])
+		for _, spelling := range mapped[kw] {
+			spelling = strings.ToLower(spelling)
+			if other, exists := d.keywords[spelling]; exists && other != kw {
+				return nil, fmt.Errorf("spelling '%s' is used for both '%s' and '%s'", spelling, other, kw)
+			}
+			d.keywords[spelling] = kw
+		}
+	}
+	return d, nil
+}
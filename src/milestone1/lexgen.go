@@ -0,0 +1,336 @@
+package milestone1
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Generator DFA dari definisi token berbentuk regex:
+// regex -> NFA (Thompson) -> DFA (subset construction) -> DFA minimal (Hopcroft)
+
+// TokenDefinition - satu kelas token dan regex-nya; urutan definisi = prioritas
+type TokenDefinition struct {
+	Name  string
+	Regex string
+	Line  int
+}
+
+// LoadTokenDefinitions baca file definisi token: satu "<NAMA> <regex>" per baris
+// Baris kosong dan baris yang diawali # atau // diabaikan; spasi di dalam regex ditulis \s
+func LoadTokenDefinitions(reader io.Reader) ([]TokenDefinition, error) {
+	var definitions []TokenDefinition
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected '<NAME> <regex>', got '%s'", lineNumber, line)
+		}
+		definitions = append(definitions, TokenDefinition{Name: fields[0], Regex: fields[1], Line: lineNumber})
+	}
+	return definitions, scanner.Err()
+}
+
+// GenerateDFA bangun DFA minimal yang menerima semua definisi token
+// State akhir diberi nama kelas token yang diterimanya (prioritas tertinggi jika lebih dari satu),
+// state lain S0 (start), S1, S2, ...
+func GenerateDFA(definitions []TokenDefinition) (*DFA, error) {
+	if len(definitions) == 0 {
+		return nil, fmt.Errorf("no token definitions")
+	}
+
+	// Satu NFA: start baru dengan epsilon ke fragmen tiap definisi
+	automaton := &nfa{}
+	start := automaton.newState()
+	for i, definition := range definitions {
+		fragment, err := automaton.compileRegex(definition.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("line %d (%s): %v", definition.Line, definition.Name, err)
+		}
+		automaton.addEpsilon(start, fragment.start)
+		automaton.states[fragment.end].accept = i
+	}
+
+	subsets := automaton.determinize(start)
+	if a := subsets.accept[0]; a >= 0 {
+		return nil, fmt.Errorf("line %d (%s): regex matches the empty string", definitions[a].Line, definitions[a].Name)
+	}
+	minimal := subsets.minimize()
+	return minimal.toDFA(definitions), nil
+}
+
+// denseDFA - DFA hasil subset construction / minimisasi: state 0 = start, -1 = tidak ada transisi
+type denseDFA struct {
+	next   [][256]int
+	accept []int // Indeks definisi token yang diterima, -1 jika bukan state akhir
+}
+
+// Epsilon closure sekumpulan state NFA (hasil terurut, dipakai sebagai kunci subset)
+func (n *nfa) closure(states []int) []int {
+	visited := make(map[int]bool)
+	stack := append([]int(nil), states...)
+	for len(stack) > 0 {
+		state := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[state] {
+			continue
+		}
+		visited[state] = true
+		stack = append(stack, n.states[state].epsilon...)
+	}
+	result := make([]int, 0, len(visited))
+	for state := range visited {
+		result = append(result, state)
+	}
+	sort.Ints(result)
+	return result
+}
+
+// Subset construction
+func (n *nfa) determinize(start int) *denseDFA {
+	dfa := &denseDFA{}
+	index := make(map[string]int)
+	var subsets [][]int
+
+	add := func(subset []int) int {
+		key := subsetKey(subset)
+		if i, ok := index[key]; ok {
+			return i
+		}
+		index[key] = len(subsets)
+		subsets = append(subsets, subset)
+		accept := -1
+		for _, state := range subset {
+			if a := n.states[state].accept; a >= 0 && (accept < 0 || a < accept) {
+				accept = a
+			}
+		}
+		var row [256]int
+		for c := range row {
+			row[c] = -1
+		}
+		dfa.next = append(dfa.next, row)
+		dfa.accept = append(dfa.accept, accept)
+		return len(subsets) - 1
+	}
+
+	add(n.closure([]int{start}))
+	for i := 0; i < len(subsets); i++ {
+		for c := 0; c < 256; c++ {
+			var moved []int
+			for _, state := range subsets[i] {
+				for _, edge := range n.states[state].edges {
+					if edge.chars.Has(byte(c)) {
+						moved = append(moved, edge.to)
+					}
+				}
+			}
+			if len(moved) > 0 {
+				target := add(n.closure(moved))
+				dfa.next[i][c] = target
+			}
+		}
+	}
+	return dfa
+}
+
+func subsetKey(subset []int) string {
+	var sb strings.Builder
+	for _, state := range subset {
+		sb.WriteString(strconv.Itoa(state))
+		sb.WriteByte(',')
+	}
+	return sb.String()
+}
+
+// Minimisasi Hopcroft; partisi awal = state bukan akhir dan state akhir per kelas token
+// Transisi yang tidak ada diarahkan ke state mati (indeks n) supaya fungsi transisi total
+func (d *denseDFA) minimize() *denseDFA {
+	n := len(d.next)
+	dead := n
+	target := func(state, c int) int {
+		if state == dead || d.next[state][c] < 0 {
+			return dead
+		}
+		return d.next[state][c]
+	}
+
+	// Transisi balik: inverse[c][t] = state yang ke t dengan input c
+	inverse := make([][][]int, 256)
+	for c := 0; c < 256; c++ {
+		inverse[c] = make([][]int, n+1)
+		for state := 0; state <= n; state++ {
+			t := target(state, c)
+			inverse[c][t] = append(inverse[c][t], state)
+		}
+	}
+
+	// Partisi awal
+	block := make([]int, n+1)
+	groups := make(map[int]int) // accept (-1 dan state mati = -2) -> nomor blok
+	var blocks [][]int
+	for state := 0; state <= n; state++ {
+		key := -2
+		if state < n {
+			key = d.accept[state]
+		}
+		b, ok := groups[key]
+		if !ok {
+			b = len(blocks)
+			groups[key] = b
+			blocks = append(blocks, nil)
+		}
+		block[state] = b
+		blocks[b] = append(blocks[b], state)
+	}
+
+	work := make([]int, 0, len(blocks))
+	inWork := make(map[int]bool)
+	for b := range blocks {
+		work = append(work, b)
+		inWork[b] = true
+	}
+
+	for len(work) > 0 {
+		splitter := work[len(work)-1]
+		work = work[:len(work)-1]
+		inWork[splitter] = false
+		members := append([]int(nil), blocks[splitter]...)
+
+		for c := 0; c < 256; c++ {
+			// X = state yang dengan input c masuk ke blok splitter
+			touched := make(map[int][]int)
+			for _, t := range members {
+				for _, state := range inverse[c][t] {
+					touched[block[state]] = append(touched[block[state]], state)
+				}
+			}
+			for _, b := range sortedKeys(touched) {
+				inside := touched[b]
+				if len(inside) == len(blocks[b]) {
+					continue
+				}
+				// Pisahkan blok b menjadi (b ∩ X) dan (b \ X)
+				isInside := make(map[int]bool, len(inside))
+				for _, state := range inside {
+					isInside[state] = true
+				}
+				var outside []int
+				for _, state := range blocks[b] {
+					if !isInside[state] {
+						outside = append(outside, state)
+					}
+				}
+				blocks[b] = outside
+				newBlock := len(blocks)
+				blocks = append(blocks, inside)
+				for _, state := range inside {
+					block[state] = newBlock
+				}
+				if inWork[b] || len(inside) <= len(outside) {
+					work = append(work, newBlock)
+					inWork[newBlock] = true
+				} else {
+					work = append(work, b)
+					inWork[b] = true
+				}
+			}
+		}
+	}
+
+	// Susun DFA minimal: blok start jadi state 0, blok state mati dibuang
+	number := make(map[int]int)
+	var order []int
+	visit := func(b int) {
+		if _, ok := number[b]; ok || b == block[dead] {
+			return
+		}
+		number[b] = len(order)
+		order = append(order, b)
+	}
+	visit(block[0])
+	result := &denseDFA{}
+	for i := 0; i < len(order); i++ {
+		representative := blocks[order[i]][0]
+		var row [256]int
+		for c := 0; c < 256; c++ {
+			t := block[target(representative, c)]
+			visit(t)
+			row[c] = -1
+			if t != block[dead] {
+				row[c] = number[t]
+			}
+		}
+		result.next = append(result.next, row)
+		result.accept = append(result.accept, d.accept[representative])
+	}
+	return result
+}
+
+func sortedKeys(m map[int][]int) []int {
+	keys := make([]int, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+// Ubah ke milestone1.DFA: satu Transition per pasangan (state, state tujuan) dengan kelas karakter
+func (d *denseDFA) toDFA(definitions []TokenDefinition) *DFA {
+	names := make([]string, len(d.next))
+	used := make(map[string]int)
+	intermediate := 0
+	for state := range d.next {
+		a := d.accept[state]
+		if a < 0 {
+			names[state] = "S" + strconv.Itoa(intermediate)
+			intermediate++
+			continue
+		}
+		name := definitions[a].Name
+		used[name]++
+		if used[name] > 1 {
+			name += "_" + strconv.Itoa(used[name])
+		}
+		names[state] = name
+	}
+
+	dfa := &DFA{StartState: names[0]}
+	for state := range d.next {
+		if d.accept[state] >= 0 {
+			dfa.FinalState = append(dfa.FinalState, names[state])
+		}
+		var targets []int
+		inputs := make(map[int]*CharSet)
+		for c := 0; c < 256; c++ {
+			t := d.next[state][c]
+			if t < 0 {
+				continue
+			}
+			if inputs[t] == nil {
+				inputs[t] = &CharSet{}
+				targets = append(targets, t)
+			}
+			inputs[t].Add(byte(c))
+		}
+		for _, t := range targets {
+			chars := *inputs[t]
+			dfa.Transitions = append(dfa.Transitions, Transition{
+				From: names[state], Input: chars.Input(), Chars: chars, To: names[t],
+			})
+		}
+	}
+	// Langsung siap dipakai lexer, sama seperti hasil LoadDFA
+	dfa.table = buildTransitionTable(dfa)
+	return dfa
+}
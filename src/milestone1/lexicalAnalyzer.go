@@ -9,21 +9,128 @@ package milestone1
 
 import (
 	"bufio"
+	"compiler/diagnostic"
 	"fmt"
+	"io"
 	"strings"
 	"unicode"
 )
 
-func LexicalAnalyzer(line string, dfa DFA, currentState *string, tokenWriter *bufio.Writer) {
+// Lexer - analisis leksikal berbasis DFA, hasilnya token di memori (tanpa file)
+// Setiap Lexer punya state sendiri, jadi aman dipakai bersamaan
+type Lexer struct {
+	dfa          DFA
+	table        *transitionTable // Dibuat sekali di NewLexer, dipakai untuk setiap token
+	dialect      *Dialect
+	comment      commentState // Komentar blok yang belum ditutup di akhir baris sebelumnya
+	keepComments bool
+	diagnostics  []diagnostic.Diagnostic
+}
+
+func NewLexer(dfa DFA) *Lexer {
+	return &Lexer{dfa: dfa, table: dfa.compiled(), dialect: Indonesian}
+}
+
+// SetDialect pilih ejaan keyword yang dikenali (default Indonesian)
+func (l *Lexer) SetDialect(dialect *Dialect) {
+	l.dialect = dialect
+}
+
+// KeepComments: komentar ikut dikembalikan sebagai token COMMENT (untuk formatter/dokumentasi)
+// Parser mengabaikan token COMMENT
+func (l *Lexer) KeepComments(keep bool) {
+	l.keepComments = keep
+}
+
+// Scan tokenisasi seluruh source dari reader
+func (l *Lexer) Scan(src io.Reader) ([]Token, error) {
+	var tokens []Token
+	scanner := bufio.NewScanner(src)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		tokens = append(tokens, l.ScanLine(scanner.Text(), lineNumber)...)
+	}
+	l.Finish()
+	return tokens, scanner.Err()
+}
+
+// ScanLine tokenisasi satu baris source; komentar blok boleh berlanjut ke baris berikutnya
+// Token ERROR tetap dikembalikan (parser yang menolak), tapi juga dicatat sebagai diagnostic
+func (l *Lexer) ScanLine(line string, lineNumber int) []Token {
+	currentState := l.dfa.StartState
+	var tokens []Token
+	for _, token := range scanLine(line, lineNumber, l.table, l.dialect, &currentState, &l.comment) {
+		switch token.Kind {
+		case KindError:
+			l.diagnostics = append(l.diagnostics, *lexicalError(token))
+		case KindComment:
+			if !l.keepComments {
+				continue
+			}
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// Finish dipanggil setelah baris terakhir (Scan memanggilnya sendiri)
+// Komentar yang belum ditutup sampai akhir file dilaporkan sebagai error
+func (l *Lexer) Finish() {
+	if l.comment.open() {
+		l.diagnostics = append(l.diagnostics, *l.comment.unterminated())
+		l.comment = commentState{}
+	}
+}
+
+// Diagnostics error leksikal yang ditemukan selama scan
+func (l *Lexer) Diagnostics() []diagnostic.Diagnostic {
+	return l.diagnostics
+}
+
+// Diagnostic untuk token ERROR
+func lexicalError(token Token) *diagnostic.Diagnostic {
+	span := diagnostic.Range(token.Pos, len(token.Lexeme))
+	if strings.HasPrefix(token.Lexeme, "'") {
+		return diagnostic.Errorf(diagnostic.UnterminatedString, span, "unterminated string literal %s", token.Lexeme)
+	}
+	return diagnostic.Errorf(diagnostic.InvalidToken, span, "invalid token '%s'", token.Lexeme)
+}
+
+// LexicalAnalyzer versi lama: token ditulis ke tokenWriter dan dicetak ke terminal
+// Tidak menyimpan state antar baris, jadi komentar blok hanya berlaku sampai akhir baris (pakai Lexer)
+func LexicalAnalyzer(line string, lineNumber int, dfa DFA, currentState *string, tokenWriter *bufio.Writer) {
+	for _, token := range scanLine(line, lineNumber, dfa.compiled(), Indonesian, currentState, &commentState{}) {
+		if token.Kind == KindComment {
+			continue
+		}
+		tokenWriter.WriteString(token.String() + "\n")
+		fmt.Println(token)
+	}
+}
+
+// lineNumber dipakai untuk mencatat posisi (baris:kolom) setiap token
+// dialect menentukan lexeme mana yang keyword
+// comment menyimpan komentar blok yang masih terbuka dari baris sebelumnya
+func scanLine(line string, lineNumber int, table *transitionTable, dialect *Dialect, currentState *string, comment *commentState) []Token {
+	var tokens []Token
 
 	/*
 		1. check if current state is already at finish
 		2. if yes, then check the validity
 		3. if not, go into next state based on the current input (char) and current state*/
-	line = removeComments(line)
-
 	i := 0
 	for i < len(line) {
+		// lanjutkan komentar yang masih terbuka
+		if comment.open() {
+			var token *Token
+			i, token = comment.consume(line, i)
+			if token != nil {
+				tokens = append(tokens, *token)
+			}
+			continue
+		}
+
 		// skip whitespaces
 		for i < len(line) && unicode.IsSpace(rune(line[i])) {
 			i++
@@ -31,22 +138,24 @@ func LexicalAnalyzer(line string, dfa DFA, currentState *string, tokenWriter *bu
 		if i >= len(line) {
 			break
 		}
-		token, newPos := processToken(line, i, dfa, currentState)
+		pos := Position{Line: lineNumber, Column: i + 1}
+
+		// pembuka komentar hanya dikenali di awal token, jadi { dan (* di dalam string tidak terpengaruh
+		if next, ok := comment.begin(line, i, pos); ok {
+			i = next
+			continue
+		}
+		token, newPos := processToken(line, i, table, currentState)
 		i = newPos
 
 		if token != "" {
-			convertedToken := Tokenize(token)
-			if convertedToken != "" {
-				tokenWriter.WriteString(convertedToken + "\n")
-				fmt.Println(convertedToken)
-			}
+			kind, keyword := dialect.Classify(token)
+			tokens = append(tokens, Token{Kind: kind, Lexeme: token, Pos: pos, Keyword: keyword})
 		} else {
 			if i < len(line) && !unicode.IsSpace(rune(line[i])) {
 				errorToken := collectError(line, i)
 				if errorToken != "" {
-					convertedToken := "ERROR(" + errorToken + ")"
-					tokenWriter.WriteString(convertedToken + "\n")
-					fmt.Println(convertedToken)
+					tokens = append(tokens, Token{Kind: KindError, Lexeme: errorToken, Pos: pos})
 					i += len(errorToken) // skip karakter error
 				} else {
 					i++
@@ -55,6 +164,7 @@ func LexicalAnalyzer(line string, dfa DFA, currentState *string, tokenWriter *bu
 		}
 
 	}
+	return tokens
 }
 
 // collect error sampe spasi atau delimiter
@@ -69,6 +179,7 @@ func collectError(line string, start int) string {
 		'[': true, ']': true, '.': true, ':': true,
 		'+': true, '-': true, '*': true, '/': true,
 		'=': true, '<': true, '>': true,
+		'{': true,
 	}
 
 	for i < len(line) {
@@ -87,97 +198,34 @@ func collectError(line string, start int) string {
 	return errorToken
 }
 
-func processToken(line string, start int, dfa DFA, curr *string) (string, int) {
-	*curr = dfa.StartState
+func processToken(line string, start int, table *transitionTable, curr *string) (string, int) {
+	state := table.start
+	if state < 0 {
+		*curr = ""
+		return "", start
+	}
+	*curr = table.names[state]
 	currentPos := start
-	var currentToken string = ""
-	longestValidToken := ""
 	longestValidPos := start
 
 	// for each char in line started from start
 	for currentPos < len(line) {
-		char := line[currentPos]
-		// stop at whitespace unless string
-		if unicode.IsSpace(rune(char)) && !isReadingString(*curr) {
+		// whitespace hanya masuk token jika DFA punya transisinya (isi string)
+		next := table.step(state, line[currentPos])
+		if next < 0 {
 			break
 		}
-		currentToken += string(char)
-		// map special character
-		input := mapCharForDFA(byte(char))
-
-		transitionKey := TransitionKey{
-			State: *curr,
-			Input: input,
-		}
-		tmp, exists := dfa.Transition[transitionKey]
-		if !exists {
-			break
-		}
-		*curr = tmp
+		state = next
+		*curr = table.names[state]
 		currentPos++
 
 		// check if curr is final state
-		for _, fs := range dfa.FinalState {
-			if (*curr) == fs {
-				longestValidToken = currentToken
-				longestValidPos = currentPos
-				break
-			}
+		if table.final[state] {
+			longestValidPos = currentPos
 		}
 	}
-	if longestValidToken != "" {
-		return longestValidToken, longestValidPos
+	if longestValidPos > start {
+		return line[start:longestValidPos], longestValidPos
 	}
-	// if start < len(line) && !unicode.IsSpace(rune(line[start])) {
-	// 	return "", start + 1
-	// }
 	return "", start
 }
-
-// func check lg read string g
-func isReadingString(state string) bool {
-	return state == "STRING_START" || state == "STRING_CONTENT"
-}
-
-func removeComments(line string) string {
-	// handle block comments
-	for {
-		start := strings.Index(line, "{")
-		if start == -1 {
-			break
-		}
-		end := strings.Index(line[start:], "}")
-		if end == -1 {
-			line = line[:start]
-			break
-		}
-		line = line[:start] + line[start+end+1:]
-	}
-	// handle normal comments
-	for {
-		start := strings.Index(line, "(*")
-		if start == -1 {
-			break
-		}
-		end := strings.Index(line[start:], "*)")
-		if end == -1 {
-			line = line[:start]
-			break
-		}
-		line = line[:start] + line[start+end+2:]
-	}
-	return line
-}
-
-func mapCharForDFA(char byte) string {
-	switch char {
-	case ' ':
-		return "SPACE"
-	case '\t':
-		return "TAB"
-	case '\n':
-		return "NEWLINE"
-	default:
-		return string(char)
-	}
-}
@@ -0,0 +1,102 @@
+package milestone1
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func loadTestDFA(t testing.TB, path string) *DFA {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	dfa, err := LoadDFA(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dfa
+}
+
+func generateTestDFA(t testing.TB) *DFA {
+	t.Helper()
+	file, err := os.Open("tokenRegex.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	definitions, err := LoadTokenDefinitions(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dfa, err := GenerateDFA(definitions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dfa
+}
+
+func scanAll(t testing.TB, dfa *DFA, source string) []Token {
+	t.Helper()
+	tokens, err := NewLexer(*dfa).Scan(strings.NewReader(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokens
+}
+
+// DFA dari generator (tanpa lewat file dfa.txt) harus menghasilkan token yang sama dengan DFA tulisan tangan
+func TestGeneratedDFALexesLikeHandWritten(t *testing.T) {
+	source, err := os.ReadFile("../../test/milestone-3/test_case_var_params.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := scanAll(t, loadTestDFA(t, "dfa.txt"), string(source))
+	got := scanAll(t, generateTestDFA(t), string(source))
+	if len(got) != len(want) {
+		t.Fatalf("generated DFA: %d tokens, hand-written DFA: %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("token %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+// DFA dari GenerateDFA langsung punya tabel transisi, tanpa WriteDFA lalu LoadDFA
+func TestGenerateDFABuildsTable(t *testing.T) {
+	dfa := generateTestDFA(t)
+	if dfa.table == nil {
+		t.Fatal("GenerateDFA returned a DFA without a transition table")
+	}
+	if lexer := NewLexer(*dfa); lexer.table != dfa.table {
+		t.Error("NewLexer rebuilt the table of a generated DFA")
+	}
+}
+
+// Tabel transisi dibuat sekali per lexer, bukan per token
+func TestLexerBuildsTableOnce(t *testing.T) {
+	lexer := NewLexer(*generateTestDFA(t))
+	table := lexer.table
+	if table == nil {
+		t.Fatal("NewLexer did not build the transition table")
+	}
+	lexer.ScanLine("x := x + 1;", 1)
+	if lexer.table != table {
+		t.Error("transition table rebuilt while scanning")
+	}
+}
+
+func BenchmarkLexGeneratedDFA(b *testing.B) {
+	source, err := os.ReadFile("../../test/milestone-3/test_case_var_params.txt")
+	if err != nil {
+		b.Fatal(err)
+	}
+	dfa := generateTestDFA(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		scanAll(b, dfa, string(source))
+	}
+}
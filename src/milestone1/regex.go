@@ -0,0 +1,216 @@
+package milestone1
+
+import "fmt"
+
+// Regex dan NFA untuk generator DFA (lexgen.go)
+//
+// Sintaks regex:
+//
+//	a        karakter literal
+//	\.       escape (\s spasi, \t tab, \n newline, \xHH byte, selain itu karakter itu sendiri)
+//	[a-z_]   kelas karakter, [^'] kelas negasi (sama seperti file DFA)
+//	.        karakter apa saja kecuali newline
+//	(r)      grup
+//	rs       konkatenasi
+//	r|s      alternatif
+//	r* r+ r? pengulangan nol/satu atau lebih, opsional
+
+// nfaState - state NFA Thompson: transisi karakter dan transisi epsilon
+type nfaState struct {
+	edges   []nfaEdge
+	epsilon []int
+	accept  int // Indeks definisi token yang diterima, -1 jika bukan state akhir
+}
+
+type nfaEdge struct {
+	chars CharSet
+	to    int
+}
+
+// nfa - kumpulan state NFA (satu NFA untuk semua definisi token)
+type nfa struct {
+	states []nfaState
+}
+
+// nfaFragment - potongan NFA dengan satu state awal dan satu state akhir
+type nfaFragment struct {
+	start, end int
+}
+
+func (n *nfa) newState() int {
+	n.states = append(n.states, nfaState{accept: -1})
+	return len(n.states) - 1
+}
+
+func (n *nfa) addEpsilon(from, to int) {
+	n.states[from].epsilon = append(n.states[from].epsilon, to)
+}
+
+// regexParser - recursive descent parser regex yang langsung membangun fragmen NFA (konstruksi Thompson)
+type regexParser struct {
+	pattern string
+	pos     int
+	nfa     *nfa
+}
+
+// Bangun fragmen NFA untuk seluruh pattern
+func (n *nfa) compileRegex(pattern string) (nfaFragment, error) {
+	p := &regexParser{pattern: pattern, nfa: n}
+	fragment, err := p.parseAlternation()
+	if err != nil {
+		return nfaFragment{}, err
+	}
+	if p.pos < len(p.pattern) {
+		return nfaFragment{}, p.errorf("unexpected '%c'", p.pattern[p.pos])
+	}
+	return fragment, nil
+}
+
+func (p *regexParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("regex '%s' at %d: %s", p.pattern, p.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *regexParser) peek() (byte, bool) {
+	if p.pos >= len(p.pattern) {
+		return 0, false
+	}
+	return p.pattern[p.pos], true
+}
+
+// alternation := concatenation ('|' concatenation)*
+func (p *regexParser) parseAlternation() (nfaFragment, error) {
+	left, err := p.parseConcatenation()
+	if err != nil {
+		return nfaFragment{}, err
+	}
+	for {
+		c, ok := p.peek()
+		if !ok || c != '|' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseConcatenation()
+		if err != nil {
+			return nfaFragment{}, err
+		}
+		start, end := p.nfa.newState(), p.nfa.newState()
+		p.nfa.addEpsilon(start, left.start)
+		p.nfa.addEpsilon(start, right.start)
+		p.nfa.addEpsilon(left.end, end)
+		p.nfa.addEpsilon(right.end, end)
+		left = nfaFragment{start, end}
+	}
+}
+
+// concatenation := repetition* (kosong = epsilon)
+func (p *regexParser) parseConcatenation() (nfaFragment, error) {
+	state := p.nfa.newState()
+	result := nfaFragment{state, state}
+	for {
+		c, ok := p.peek()
+		if !ok || c == '|' || c == ')' {
+			return result, nil
+		}
+		next, err := p.parseRepetition()
+		if err != nil {
+			return nfaFragment{}, err
+		}
+		p.nfa.addEpsilon(result.end, next.start)
+		result.end = next.end
+	}
+}
+
+// repetition := atom ('*' | '+' | '?')*
+func (p *regexParser) parseRepetition() (nfaFragment, error) {
+	atom, err := p.parseAtom()
+	if err != nil {
+		return nfaFragment{}, err
+	}
+	for {
+		c, ok := p.peek()
+		if !ok || (c != '*' && c != '+' && c != '?') {
+			return atom, nil
+		}
+		p.pos++
+		start, end := p.nfa.newState(), p.nfa.newState()
+		p.nfa.addEpsilon(start, atom.start)
+		p.nfa.addEpsilon(atom.end, end)
+		if c != '+' {
+			p.nfa.addEpsilon(start, end) // boleh dilewati
+		}
+		if c != '?' {
+			p.nfa.addEpsilon(atom.end, atom.start) // boleh diulang
+		}
+		atom = nfaFragment{start, end}
+	}
+}
+
+// atom := '(' alternation ')' | '[' class ']' | '.' | escape | karakter
+func (p *regexParser) parseAtom() (nfaFragment, error) {
+	c, _ := p.peek()
+	var chars CharSet
+	switch c {
+	case '(':
+		p.pos++
+		inner, err := p.parseAlternation()
+		if err != nil {
+			return nfaFragment{}, err
+		}
+		if c, ok := p.peek(); !ok || c != ')' {
+			return nfaFragment{}, p.errorf("missing ')'")
+		}
+		p.pos++
+		return inner, nil
+	case '[':
+		end := p.classEnd()
+		if end < 0 {
+			return nfaFragment{}, p.errorf("missing ']'")
+		}
+		class, err := parseClass(p.pattern[p.pos+1 : end])
+		if err != nil {
+			return nfaFragment{}, p.errorf("%v", err)
+		}
+		chars = class
+		p.pos = end + 1
+	case '.':
+		chars.Add('\n')
+		chars = chars.Negate()
+		p.pos++
+	case '\\':
+		value, size, err := parseEscape(p.pattern[p.pos:])
+		if err != nil {
+			return nfaFragment{}, p.errorf("%v", err)
+		}
+		chars.Add(value)
+		p.pos += size
+	case '*', '+', '?':
+		return nfaFragment{}, p.errorf("'%c' has nothing to repeat", c)
+	default:
+		chars.Add(c)
+		p.pos++
+	}
+
+	start, end := p.nfa.newState(), p.nfa.newState()
+	p.nfa.states[start].edges = append(p.nfa.states[start].edges, nfaEdge{chars: chars, to: end})
+	return nfaFragment{start, end}, nil
+}
+
+// Posisi ']' penutup kelas yang dibuka di p.pos (escape dilewati; ']' pertama setelah '[' atau '[^' literal)
+func (p *regexParser) classEnd() int {
+	i := p.pos + 1
+	if i < len(p.pattern) && p.pattern[i] == '^' {
+		i++
+	}
+	if i < len(p.pattern) && p.pattern[i] == ']' {
+		i++
+	}
+	for ; i < len(p.pattern); i++ {
+		switch p.pattern[i] {
+		case '\\':
+			i++
+		case ']':
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,68 @@
+package milestone1
+
+import (
+	"bufio"
+	"compiler/diagnostic"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// TokenKind - jenis token (nama sama dengan format teks tokens.txt)
+type TokenKind string
+
+const (
+	KindKeyword            TokenKind = "KEYWORD"
+	KindIdentifier         TokenKind = "IDENTIFIER"
+	KindNumber             TokenKind = "NUMBER"
+	KindReal               TokenKind = "REAL"
+	KindCharLiteral        TokenKind = "CHAR_LITERAL"
+	KindStringLiteral      TokenKind = "STRING_LITERAL"
+	KindArithmeticOperator TokenKind = "ARITHMETIC_OPERATOR"
+	KindRelationalOperator TokenKind = "RELATIONAL_OPERATOR"
+	KindLogicalOperator    TokenKind = "LOGICAL_OPERATOR"
+	KindAssignOperator     TokenKind = "ASSIGN_OPERATOR"
+	KindRangeOperator      TokenKind = "RANGE_OPERATOR"
+	KindSemicolon          TokenKind = "SEMICOLON"
+	KindComma              TokenKind = "COMMA"
+	KindColon              TokenKind = "COLON"
+	KindDot                TokenKind = "DOT"
+	KindLParenthesis       TokenKind = "LPARENTHESIS"
+	KindRParenthesis       TokenKind = "RPARENTHESIS"
+	KindLBracket           TokenKind = "LBRACKET"
+	KindRBracket           TokenKind = "RBRACKET"
+	KindComment            TokenKind = "COMMENT"
+	KindError              TokenKind = "ERROR"
+)
+
+// Position - posisi di source (baris dan kolom mulai dari 1)
+type Position = diagnostic.Position
+
+// Token hasil lexer
+type Token struct {
+	Kind    TokenKind
+	Lexeme  string
+	Pos     Position
+	Keyword Keyword // Jenis keyword kanonik untuk KEYWORD dan operator kata, kosong untuk token lain
+}
+
+// Format teks tokens.txt: TYPE(value) @baris:kolom
+// Komentar multi-baris ditulis dengan \n supaya tetap satu token per baris
+func (t Token) String() string {
+	lexeme := t.Lexeme
+	if t.Kind == KindComment {
+		lexeme = strings.ReplaceAll(lexeme, "\n", "\\n")
+	}
+	return fmt.Sprintf("%s(%s) @%s", t.Kind, lexeme, t.Pos)
+}
+
+// WriteTokens dump token ke writer, satu token per baris (format tokens.txt)
+func WriteTokens(writer io.Writer, tokens []Token) error {
+	bufWriter := bufio.NewWriter(writer)
+	for _, token := range tokens {
+		if _, err := bufWriter.WriteString(token.String() + "\n"); err != nil {
+			return err
+		}
+	}
+	return bufWriter.Flush()
+}
@@ -1,118 +1,76 @@
 package milestone1
 
-import "strings"
-
+// Tokenize ubah lexeme jadi format teks TYPE(value)
 func Tokenize(token string) string {
 	if token == "" {
 		return ""
 	}
-	if len(token) > 0 && token[0] == '\'' && (len(token) == 1 || token[len(token)-1] != '\'') {
-		return "ERROR(" + token + ")"
-	}
-	keywords := map[string]bool{
-		"program":    true,
-		"variabel":   true,
-		"mulai":      true,
-		"selesai":    true,
-		"jika":       true,
-		"maka":       true,
-		"selain_itu": true,
-		"selama":     true,
-		"lakukan":    true,
-		"untuk":      true,
-		"ke":         true,
-		"turun_ke":   true,
-		"integer":    true,
-		"real":       true,
-		"boolean":    true,
-		"char":       true,
-		"larik":      true,
-		"dari":       true,
-		"prosedur":   true,
-		"fungsi":     true,
-		"konstanta":  true,
-		"tipe":       true,
-		"true":       true,
-		"false":      true,
-		"ulangi":     true,
-		"sampai":     true,
-		"kasus":      true,
-		"rekaman":    true,
-		// "writeln":   true,
-		// "read":      true,
-		// "call":      true,
-	}
-	arithmetic := map[string]bool{
-		"bagi": true,
-		"mod":  true,
-	}
-	logical := map[string]bool{
-		"dan":   true,
-		"atau":  true,
-		"tidak": true,
-	}
+	return string(Classify(token)) + "(" + token + ")"
+}
 
-	if keywords[strings.ToLower(token)] {
-		return "KEYWORD(" + token + ")"
-	}
-	if arithmetic[strings.ToLower(token)] {
-		return "ARITHMETIC_OPERATOR(" + token + ")"
-	}
-	if logical[strings.ToLower(token)] {
-		return "LOGICAL_OPERATOR(" + token + ")"
+// Classify tentukan jenis token dari lexeme hasil DFA (keyword dialek Indonesia)
+func Classify(token string) TokenKind {
+	kind, _ := Indonesian.Classify(token)
+	return kind
+}
+
+// Jenis token selain keyword dan operator kata (keyword ditangani Dialect.Classify)
+func classifyLexeme(token string) TokenKind {
+	if len(token) > 0 && token[0] == '\'' && (len(token) == 1 || token[len(token)-1] != '\'') {
+		return KindError
 	}
 	if isNumber(token) {
-		return "NUMBER(" + token + ")"
+		return KindNumber
 	}
 	if isRealNumber(token) {
-		return "REAL(" + token + ")"
+		return KindReal
 	}
 	if len(token) >= 2 && token[0] == '\'' && token[len(token)-1] == '\'' {
 		content := token[1 : len(token)-1]
 
 		// char kosong
 		if len(content) == 0 {
-			return "CHAR_LITERAL(" + token + ")"
+			return KindCharLiteral
 		}
 
 		// len 1 = char literal
 		if len(content) == 1 {
-			return "CHAR_LITERAL(" + token + ")"
+			return KindCharLiteral
 		}
 
 		// else masuk ke string
-		return "STRING_LITERAL(" + token + ")"
+		return KindStringLiteral
 	}
 
 	// cek operator lain berdasarkan token
 	switch token {
 	case "+", "-", "*", "/":
-		return "ARITHMETIC_OPERATOR(" + token + ")"
+		return KindArithmeticOperator
 	case "=", "<>", "<", ">", "<=", ">=":
-		return "RELATIONAL_OPERATOR(" + token + ")"
+		return KindRelationalOperator
 	case ":=":
-		return "ASSIGN_OPERATOR(" + token + ")"
+		return KindAssignOperator
 	case ";":
-		return "SEMICOLON(" + token + ")"
+		return KindSemicolon
 	case ",":
-		return "COMMA(" + token + ")"
+		return KindComma
 	case ":":
-		return "COLON(" + token + ")"
+		return KindColon
 	case ".":
-		return "DOT(" + token + ")"
+		return KindDot
 	case "(":
-		return "LPARENTHESIS(" + token + ")"
+		return KindLParenthesis
 	case ")":
-		return "RPARENTHESIS(" + token + ")"
+		return KindRParenthesis
 	case "[":
-		return "LBRACKET(" + token + ")"
+		return KindLBracket
 	case "]":
-		return "RBRACKET(" + token + ")"
+		return KindRBracket
 	case "..":
-		return "RANGE_OPERATOR(" + token + ")"
+		return KindRangeOperator
 	}
 
-	return "IDENTIFIER(" + token + ")"
+	return KindIdentifier
 }
 
 func isNumber(token string) bool {
@@ -0,0 +1,104 @@
+package milestone1
+
+import (
+	"strconv"
+	"strings"
+)
+
+// transitionTable - tabel transisi ringkas yang dipakai lexer
+// State diberi nomor, dan byte input dikelompokkan ke kelas (byte dengan kolom transisi yang sama
+// di semua state), jadi satu langkah DFA = dua indeks array tanpa map
+type transitionTable struct {
+	names   []string       // Nama state berdasarkan nomor
+	start   int            // -1 jika DFA tidak punya start state
+	final   []bool         // final[state]
+	classOf [256]uint16    // Kelas tiap byte input
+	classes int            // Jumlah kelas
+	next    []int32        // next[state*classes + kelas], -1 = tidak ada transisi
+	numbers map[string]int // Nomor state berdasarkan nama (hanya untuk membangun tabel)
+}
+
+func buildTransitionTable(dfa *DFA) *transitionTable {
+	t := &transitionTable{numbers: make(map[string]int)}
+	t.number(dfa.StartState)
+	for _, tr := range dfa.Transitions {
+		t.number(tr.From)
+		t.number(tr.To)
+	}
+
+	// Baris lengkap per state: ANY diisi dulu sebagai default, lalu baris lain menimpa sesuai urutan di file
+	rows := make([][256]int32, len(t.names))
+	for i := range rows {
+		for c := range rows[i] {
+			rows[i][c] = -1
+		}
+	}
+	for _, any := range []bool{true, false} {
+		for _, tr := range dfa.Transitions {
+			if tr.Any != any {
+				continue
+			}
+			row := &rows[t.numbers[tr.From]]
+			for c := 0; c < 256; c++ {
+				if tr.Any || tr.Chars.Has(byte(c)) {
+					row[c] = int32(t.numbers[tr.To])
+				}
+			}
+		}
+	}
+
+	// Kelompokkan byte dengan kolom identik menjadi satu kelas
+	signatures := make(map[string]int)
+	var columns [][]int32
+	for c := 0; c < 256; c++ {
+		column := make([]int32, len(rows))
+		var key strings.Builder
+		for s := range rows {
+			column[s] = rows[s][c]
+			key.WriteString(strconv.Itoa(int(rows[s][c])) + ",")
+		}
+		class, ok := signatures[key.String()]
+		if !ok {
+			class = len(columns)
+			signatures[key.String()] = class
+			columns = append(columns, column)
+		}
+		t.classOf[c] = uint16(class)
+	}
+
+	t.classes = len(columns)
+	t.next = make([]int32, len(rows)*t.classes)
+	for class, column := range columns {
+		for s, target := range column {
+			t.next[s*t.classes+class] = target
+		}
+	}
+
+	t.final = make([]bool, len(t.names))
+	for _, name := range dfa.FinalState {
+		if s, ok := t.numbers[name]; ok {
+			t.final[s] = true
+		}
+	}
+	t.start = -1
+	if dfa.StartState != "" {
+		t.start = t.numbers[dfa.StartState]
+	}
+	t.numbers = nil
+	return t
+}
+
+func (t *transitionTable) number(name string) {
+	if name == "" {
+		return
+	}
+	if _, ok := t.numbers[name]; !ok {
+		t.numbers[name] = len(t.names)
+		t.names = append(t.names, name)
+	}
+}
+
+// State berikutnya dari state dengan input c, -1 jika tidak ada transisi
+func (t *transitionTable) step(state int, c byte) int {
+	return int(t.next[state*t.classes+int(t.classOf[c])])
+}
@@ -0,0 +1,174 @@
+package milestone1
+
+import (
+	"compiler/diagnostic"
+	"sort"
+)
+
+// Validate periksa spesifikasi DFA sebelum dipakai lexer
+// Error: start state tidak ada, transisi nondeterministik, final state tidak terdefinisi, baris rusak
+// Warning: transisi duplikat yang identik, state tidak tercapai dari start, state mati (tidak bisa mencapai final state)
+// ANY tidak dianggap bentrok dengan transisi lain karena hanya dipakai jika tidak ada transisi lain yang cocok
+func (dfa *DFA) Validate() []diagnostic.Diagnostic {
+	var diagnostics []diagnostic.Diagnostic
+	report := func(d *diagnostic.Diagnostic) {
+		diagnostics = append(diagnostics, *d)
+	}
+
+	for _, malformed := range dfa.spec.malformed {
+		report(diagnostic.Errorf(diagnostic.MalformedDFALine, specSpan(malformed.Line),
+			"malformed line '%s': %s", malformed.Text, malformed.Message))
+	}
+
+	// Dua transisi dari state yang sama yang menerima karakter yang sama:
+	// ke state berbeda = nondeterministik, ke state yang sama = duplikat
+	for j, later := range dfa.Transitions {
+		for _, earlier := range dfa.Transitions[:j] {
+			if earlier.From != later.From || earlier.Any != later.Any {
+				continue
+			}
+			overlap := earlier.Chars.Intersect(later.Chars)
+			if !later.Any && overlap.IsEmpty() {
+				continue
+			}
+			input := later.Input
+			if !later.Any && later.Chars != overlap {
+				input = "[" + overlap.String() + "]"
+			}
+			first := specSpan(earlier.Line)
+			if earlier.To != later.To {
+				report(diagnostic.Errorf(diagnostic.NondeterministicDFA, specSpan(later.Line),
+					"nondeterministic transition: (%s, %s) goes to both %s and %s", later.From, input, earlier.To, later.To).
+					WithNote(&first, "first defined here"))
+			} else {
+				report(diagnostic.Warningf(diagnostic.DuplicateTransition, specSpan(later.Line),
+					"duplicate transition (%s, %s) -> %s", later.From, input, later.To).
+					WithNote(&first, "first defined here"))
+			}
+		}
+	}
+
+	states := dfa.states()
+	if dfa.StartState == "" {
+		report(diagnostic.Errorf(diagnostic.MissingStartState, diagnostic.Span{}, "missing 'Start_state = <state>' line"))
+	} else if _, ok := states[dfa.StartState]; !ok {
+		report(diagnostic.Errorf(diagnostic.MissingStartState, specSpan(dfa.spec.startLine),
+			"start state '%s' has no transitions", dfa.StartState))
+	}
+
+	finals := make(map[string]bool)
+	for _, final := range dfa.FinalState {
+		if final == "" {
+			continue
+		}
+		finals[final] = true
+		if _, ok := states[final]; !ok && final != dfa.StartState {
+			report(diagnostic.Errorf(diagnostic.UndefinedFinalState, specSpan(dfa.spec.finalLine),
+				"final state '%s' does not appear in any transition", final))
+		}
+	}
+	if len(finals) == 0 {
+		report(diagnostic.Errorf(diagnostic.UndefinedFinalState, specSpan(dfa.spec.finalLine), "no final states defined"))
+	}
+
+	// Hanya dicek jika start state ada, supaya semua state tidak dilaporkan tidak tercapai
+	if _, ok := states[dfa.StartState]; ok {
+		reachable := dfa.reachableFrom(dfa.StartState)
+		productive := dfa.reachingFinal(finals)
+		for _, state := range sortedStates(states) {
+			switch {
+			case !reachable[state]:
+				report(diagnostic.Warningf(diagnostic.UnreachableState, specSpan(states[state]),
+					"state '%s' is not reachable from start state '%s'", state, dfa.StartState))
+			case !productive[state]:
+				report(diagnostic.Warningf(diagnostic.DeadState, specSpan(states[state]),
+					"dead state '%s': no final state can be reached from it", state))
+			}
+		}
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		return diagnostics[i].Span.Start.Line < diagnostics[j].Span.Start.Line
+	})
+	return diagnostics
+}
+
+// Semua state yang muncul di transisi, dengan baris pertama kemunculannya
+func (dfa *DFA) states() map[string]int {
+	states := make(map[string]int)
+	note := func(state string, line int) {
+		if first, ok := states[state]; !ok || line < first {
+			states[state] = line
+		}
+	}
+	for _, tr := range dfa.Transitions {
+		note(tr.From, tr.Line)
+		note(tr.To, tr.Line)
+	}
+	return states
+}
+
+// State yang bisa dicapai dari start
+func (dfa *DFA) reachableFrom(start string) map[string]bool {
+	next := make(map[string][]string)
+	for _, tr := range dfa.Transitions {
+		next[tr.From] = append(next[tr.From], tr.To)
+	}
+	return closure([]string{start}, next)
+}
+
+// State yang bisa mencapai salah satu final state
+func (dfa *DFA) reachingFinal(finals map[string]bool) map[string]bool {
+	previous := make(map[string][]string)
+	for _, tr := range dfa.Transitions {
+		previous[tr.To] = append(previous[tr.To], tr.From)
+	}
+	var start []string
+	for final := range finals {
+		start = append(start, final)
+	}
+	return closure(start, previous)
+}
+
+// BFS dari start mengikuti edges
+func closure(start []string, edges map[string][]string) map[string]bool {
+	visited := make(map[string]bool)
+	queue := append([]string(nil), start...)
+	for _, state := range start {
+		visited[state] = true
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for _, next := range edges[state] {
+			if !visited[next] {
+				visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return visited
+}
+
+// Urutkan state berdasarkan baris kemunculan pertama (lalu nama)
+func sortedStates(states map[string]int) []string {
+	names := make([]string, 0, len(states))
+	for state := range states {
+		names = append(names, state)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if states[names[i]] != states[names[j]] {
+			return states[names[i]] < states[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// Span satu baris di file DFA (baris 0 = tidak diketahui, misal DFA dibuat tanpa LoadDFA)
+func specSpan(line int) diagnostic.Span {
+	if line == 0 {
+		return diagnostic.Span{}
+	}
+	return diagnostic.At(diagnostic.Position{Line: line, Column: 1})
+}
@@ -1,15 +1,24 @@
 package milestone2
 
 import (
+	"compiler/milestone1"
 	"fmt"
 	"io"
 )
 
 // Struktur Node Tree
+// Jenis node dibaca dari Kind (dan Token/Keyword untuk daun), Value hanya label untuk dicetak
 type AbstractSyntaxTree struct {
-	Value          string
-	ProductionRule []string // (Tidak digunakan di Recursive Descent, tapi dibiarkan agar kompatibel)
+	Value          string   // "<if-statement>" atau "TYPE(teks)" untuk daun
+	Kind           NodeKind // Jenis non-terminal, NodeToken untuk daun
+	Token          milestone1.TokenKind
+	Text           string             // Teks token daun (keyword: ejaan kanonik)
+	Keyword        milestone1.Keyword // Jenis keyword daun, kosong untuk token lain
+	ProductionRule []string           // (Tidak digunakan di Recursive Descent, tapi dibiarkan agar kompatibel)
 	Children       []*AbstractSyntaxTree
+	Line           int // Posisi token (pertama) di source, 0 kalau tidak diketahui
+	Column         int
+	EndColumn      int // Kolom setelah karakter terakhir token (hanya daun)
 }
 
 // Fungsi Print Tree dengan format cantik (seperti command 'tree' di Linux)
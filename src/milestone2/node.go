@@ -0,0 +1,138 @@
+package milestone2
+
+import (
+	"compiler/diagnostic"
+	"compiler/milestone1"
+)
+
+// NodeKind - jenis node parse tree
+// Daun (terminal) selalu NodeToken, jenis tokennya ada di field Token
+type NodeKind int
+
+const (
+	NodeToken NodeKind = iota
+	NodeProgram
+	NodeProgramHeader
+	NodeDeclarationPart
+	NodeConstDeclaration
+	NodeConstDef
+	NodeTypeDeclaration
+	NodeVarDeclaration
+	NodeSubprogramDeclaration
+	NodeParameterList
+	NodeIdentifierList
+	NodeType
+	NodeArrayType
+	NodeIndexType
+	NodeEnumType
+	NodeSubrangeType
+	NodeRecordType
+	NodeFieldList
+	NodeCompoundStatement
+	NodeStatementList
+	NodeAssignmentStatement
+	NodeProcedureCall
+	NodeIfStatement
+	NodeWhileStatement
+	NodeForStatement
+	NodeRepeatStatement
+	NodeCaseStatement
+	NodeCaseElement
+	NodeCaseLabelList
+	NodeCaseLabel
+	NodeEmptyStatement
+	NodeExpression
+	NodeSimpleExpression
+	NodeTerm
+	NodeFactor
+	NodeVariable
+	NodeFunctionCall
+	NodeError // Token yang dilompati saat recovery
+)
+
+// Nama non-terminal (dipakai sebagai Value node dan di output tree)
+var nodeNames = [...]string{
+	NodeToken:                 "<token>",
+	NodeProgram:               "<program>",
+	NodeProgramHeader:         "<program-header>",
+	NodeDeclarationPart:       "<declaration-part>",
+	NodeConstDeclaration:      "<const-declaration>",
+	NodeConstDef:              "<const-def>",
+	NodeTypeDeclaration:       "<type-declaration>",
+	NodeVarDeclaration:        "<var-declaration>",
+	NodeSubprogramDeclaration: "<subprogram-declaration>",
+	NodeParameterList:         "<parameter-list>",
+	NodeIdentifierList:        "<identifier-list>",
+	NodeType:                  "<type>",
+	NodeArrayType:             "<array-type>",
+	NodeIndexType:             "<index-type>",
+	NodeEnumType:              "<enum-type>",
+	NodeSubrangeType:          "<subrange-type>",
+	NodeRecordType:            "<record-type>",
+	NodeFieldList:             "<field-list>",
+	NodeCompoundStatement:     "<compound-statement>",
+	NodeStatementList:         "<statement-list>",
+	NodeAssignmentStatement:   "<assignment-statement>",
+	NodeProcedureCall:         "<procedure-call>",
+	NodeIfStatement:           "<if-statement>",
+	NodeWhileStatement:        "<while-statement>",
+	NodeForStatement:          "<for-statement>",
+	NodeRepeatStatement:       "<repeat-statement>",
+	NodeCaseStatement:         "<case-statement>",
+	NodeCaseElement:           "<case-element>",
+	NodeCaseLabelList:         "<case-label-list>",
+	NodeCaseLabel:             "<case-label>",
+	NodeEmptyStatement:        "<empty-statement>",
+	NodeExpression:            "<expression>",
+	NodeSimpleExpression:      "<simple-expression>",
+	NodeTerm:                  "<term>",
+	NodeFactor:                "<factor>",
+	NodeVariable:              "<variable>",
+	NodeFunctionCall:          "<function-call>",
+	NodeError:                 "<error>",
+}
+
+func (k NodeKind) String() string {
+	if k >= 0 && int(k) < len(nodeNames) {
+		return nodeNames[k]
+	}
+	return "<unknown>"
+}
+
+// IsStatement - node yang bisa muncul sebagai statement di <statement-list>
+func (k NodeKind) IsStatement() bool {
+	switch k {
+	case NodeCompoundStatement, NodeAssignmentStatement, NodeProcedureCall, NodeIfStatement,
+		NodeWhileStatement, NodeForStatement, NodeRepeatStatement, NodeCaseStatement, NodeEmptyStatement:
+		return true
+	}
+	return false
+}
+
+// IsToken - daun dengan jenis token tertentu
+func (n *AbstractSyntaxTree) IsToken(kind milestone1.TokenKind) bool {
+	return n.Kind == NodeToken && n.Token == kind
+}
+
+// IsKeyword - daun keyword (atau operator kata) tertentu, tidak bergantung dialek
+func (n *AbstractSyntaxTree) IsKeyword(kw milestone1.Keyword) bool {
+	return n.Kind == NodeToken && n.Keyword == kw
+}
+
+// IsText - daun dengan jenis token dan teks tertentu, misalnya operator '-'
+func (n *AbstractSyntaxTree) IsText(kind milestone1.TokenKind, text string) bool {
+	return n.IsToken(kind) && n.Text == text
+}
+
+// Span - posisi node di source, dari token pertama sampai akhir token terakhir
+func (n *AbstractSyntaxTree) Span() diagnostic.Span {
+	start := diagnostic.Position{Line: n.Line, Column: n.Column}
+	last := n
+	for len(last.Children) > 0 {
+		last = last.Children[len(last.Children)-1]
+	}
+	if last.Kind != NodeToken || last.EndColumn == 0 {
+		return diagnostic.At(start)
+	}
+	return diagnostic.Span{Start: start, End: diagnostic.Position{Line: last.Line, Column: last.EndColumn}}
+}
@@ -1,18 +1,29 @@
 package milestone2
 
 import (
+	"compiler/diagnostic"
+	"compiler/milestone1"
+	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 )
 
-// Regex untuk M1 (TANPA line number)
-var tokenRegexSimple = regexp.MustCompile(`^([A-Z_]+)\((.*)\)$`)
+// Dikembalikan ParseProgram jika satu-satunya masalah adalah token ERROR dari lexer
+var ErrInvalidTokens = errors.New("source contains invalid tokens")
+
+// Regex untuk token M1: TYPE(value) dengan posisi opsional " @baris:kolom"
+var tokenRegexSimple = regexp.MustCompile(`^([A-Z_]+)\((.*)\)(?: @(\d+):(\d+))?$`)
 
 // (Struct Token ini cuma dipakai internal di M2)
+// Untuk keyword, Value = ejaan kanonik (dialek Indonesia) dan Lexeme = teks asli di source
 type Token struct {
-	Type  string
-	Value string
-	Line  int // (Akan 0, karena M1 apa adanya)
+	Type    string
+	Value   string
+	Lexeme  string
+	Keyword milestone1.Keyword // Jenis keyword, kosong untuk token lain
+	Line    int                // 0 kalau token tidak membawa posisi
+	Column  int
 }
 
 func (t Token) String() string {
@@ -23,11 +34,48 @@ func (t Token) String() string {
 type Parser struct {
 	tokens  []Token
 	current int
+	errors  []diagnostic.Diagnostic // Error sintaks yang sudah dicatat (recovery)
+	invalid bool                    // Parsing gagal di token ERROR (errornya sudah dilaporkan lexer)
+	dialect *milestone1.Dialect     // Ejaan keyword di pesan error
 }
 
 // (Fungsi NewParser ini yang dipanggil di main.go)
-func NewParser(tokenStrings []string) *Parser {
-	var tokens []Token
+// Token diterima langsung dari lexer milestone1, tidak lewat tokens.txt
+// Token COMMENT (jika lexer menyimpan komentar) dilewati
+// Keyword disimpan dengan ejaan kanonik, jadi grammar dan tree sama untuk semua dialek
+func NewParser(lexTokens []milestone1.Token) *Parser {
+	tokens := make([]Token, 0, len(lexTokens)+1)
+	for _, t := range lexTokens {
+		if t.Kind == milestone1.KindComment {
+			continue
+		}
+		value := t.Lexeme
+		if t.Keyword != "" {
+			value = string(t.Keyword)
+		}
+		tokens = append(tokens, Token{
+			Type: string(t.Kind), Value: value, Lexeme: t.Lexeme, Keyword: t.Keyword,
+			Line: t.Pos.Line, Column: t.Pos.Column,
+		})
+	}
+	// Tambah EOF sebagai penanda akhir
+	tokens = append(tokens, eofToken(tokens))
+
+	return &Parser{
+		tokens:  tokens,
+		current: 0,
+		dialect: milestone1.Indonesian,
+	}
+}
+
+// SetDialect pilih dialek untuk ejaan keyword di pesan error (samakan dengan lexer)
+func (p *Parser) SetDialect(dialect *milestone1.Dialect) {
+	p.dialect = dialect
+}
+
+// ParseTokenStrings baca ulang token dari dump tokens.txt (baris yang tidak dikenali di-skip)
+func ParseTokenStrings(tokenStrings []string) []milestone1.Token {
+	var tokens []milestone1.Token
 	for _, s := range tokenStrings {
 		if s == "" {
 			continue
@@ -35,27 +83,87 @@ func NewParser(tokenStrings []string) *Parser {
 
 		token, err := parseTokenString(s)
 		if err != nil {
-			fmt.Printf("Warning: Token M1 tidak dikenali/skip: %s\n", s)
 			continue
 		}
 		tokens = append(tokens, token)
 	}
-	// Tambah EOF sebagai penanda akhir
-	tokens = append(tokens, Token{Type: "EOF", Value: "EOF", Line: 0})
-
-	return &Parser{
-		tokens:  tokens,
-		current: 0,
-	}
+	return tokens
 }
 
 // Logic parsing string token dari file tokens.txt
-func parseTokenString(s string) (Token, error) {
+func parseTokenString(s string) (milestone1.Token, error) {
 	matches := tokenRegexSimple.FindStringSubmatch(s)
-	if matches != nil && len(matches) >= 3 {
-		return Token{Type: matches[1], Value: matches[2], Line: 0}, nil
+	if matches == nil {
+		return milestone1.Token{}, fmt.Errorf("invalid token format")
+	}
+	token := milestone1.Token{Kind: milestone1.TokenKind(matches[1]), Lexeme: matches[2]}
+	if kw, ok := milestone1.Indonesian.Keyword(token.Lexeme); ok && kw.Kind() == token.Kind {
+		token.Keyword = kw
+	}
+	if matches[3] != "" {
+		token.Pos.Line, _ = strconv.Atoi(matches[3])
+		token.Pos.Column, _ = strconv.Atoi(matches[4])
+	}
+	return token, nil
+}
+
+// Token EOF diletakkan tepat setelah token terakhir
+func eofToken(tokens []Token) Token {
+	eof := Token{Type: "EOF", Value: "EOF", Lexeme: "EOF"}
+	if len(tokens) > 0 {
+		last := tokens[len(tokens)-1]
+		eof.Line = last.Line
+		eof.Column = last.Column + len(last.Lexeme)
+	}
+	return eof
+}
+
+// Span token di source (untuk diagnostic)
+func (t Token) Span() diagnostic.Span {
+	pos := diagnostic.Position{Line: t.Line, Column: t.Column}
+	if t.Type == "EOF" {
+		return diagnostic.At(pos)
+	}
+	return diagnostic.Range(pos, len(t.Lexeme))
+}
+
+// Error sintaks sebagai diagnostic, lokasinya token yang sedang dilihat
+func (p *Parser) errorf(code diagnostic.Code, format string, args ...interface{}) error {
+	return diagnostic.Errorf(code, p.peek().Span(), p.spell(format), args...)
+}
+
+var quotedWord = regexp.MustCompile(`'[a-z_]+'`)
+
+// Keyword yang dikutip di pesan ('selesai') ditulis dengan ejaan dialek parser
+func (p *Parser) spell(message string) string {
+	if p.dialect == milestone1.Indonesian {
+		return message
 	}
-	return Token{}, fmt.Errorf("invalid token format")
+	return quotedWord.ReplaceAllStringFunc(message, func(quoted string) string {
+		kw, ok := milestone1.Indonesian.Keyword(quoted[1 : len(quoted)-1])
+		if !ok {
+			return quoted
+		}
+		return "'" + p.dialect.Spell(kw) + "'"
+	})
+}
+
+// Node daun (terminal) dari sebuah token, posisi ikut disalin
+func newLeaf(t Token) *AbstractSyntaxTree {
+	leaf := &AbstractSyntaxTree{
+		Value: t.String(), Kind: NodeToken, Token: milestone1.TokenKind(t.Type), Text: t.Value, Keyword: t.Keyword,
+		Line: t.Line, Column: t.Column,
+	}
+	if t.Line > 0 {
+		leaf.EndColumn = t.Column + len(t.Lexeme)
+	}
+	return leaf
+}
+
+// Node non-terminal, posisinya = posisi token pertama konstruksi tersebut
+func (p *Parser) newNode(kind NodeKind) *AbstractSyntaxTree {
+	t := p.peek()
+	return &AbstractSyntaxTree{Value: kind.String(), Kind: kind, Line: t.Line, Column: t.Column}
 }
 
 // --- Helper Functions ---
@@ -92,57 +200,81 @@ func (p *Parser) checkType(tType string) bool {
 func (p *Parser) consume(tType string, value string, msg string) (*AbstractSyntaxTree, error) {
 	if p.check(tType, value) {
 		t := p.advance()
-		return &AbstractSyntaxTree{Value: t.String()}, nil
+		return newLeaf(t), nil
 	}
-	return nil, fmt.Errorf("Syntax Error line %d: %s (Expected: %s, Got: %s(%s))", p.peek().Line, msg, value, p.peek().Type, p.peek().Value)
+	return nil, p.errorf(diagnostic.ExpectedToken, "%s (Expected: %s, Got: %s(%s))", p.spell(msg), value, p.peek().Type, p.peek().Lexeme)
+}
+
+// Cek jenis keyword (termasuk operator kata seperti bagi, dan, tidak), apa pun ejaannya di source
+func (p *Parser) checkKeyword(kw milestone1.Keyword) bool {
+	if p.isAtEnd() {
+		return false
+	}
+	return p.peek().Keyword == kw
+}
+
+func (p *Parser) consumeKeyword(kw milestone1.Keyword, msg string) (*AbstractSyntaxTree, error) {
+	if p.checkKeyword(kw) {
+		t := p.advance()
+		return newLeaf(t), nil
+	}
+	return nil, p.errorf(diagnostic.ExpectedToken, "%s (Expected: %s, Got: %s(%s))", p.spell(msg), p.dialect.Spell(kw), p.peek().Type, p.peek().Lexeme)
 }
 
 func (p *Parser) consumeType(tType string, msg string) (*AbstractSyntaxTree, error) {
 	if p.checkType(tType) {
 		t := p.advance()
-		return &AbstractSyntaxTree{Value: t.String()}, nil
+		return newLeaf(t), nil
 	}
-	return nil, fmt.Errorf("Syntax Error line %d: %s (Got: %s)", p.peek().Line, msg, p.peek().Type)
+	return nil, p.errorf(diagnostic.ExpectedToken, "%s (Got: %s)", p.spell(msg), p.peek().Type)
 }
 
 // --- Recursive Descent Rules (Sesuai Grammar Spek) ---
 
 // (Ini fungsi yang dipanggil di main.go)
 func (p *Parser) ParseProgram() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<program>"}
+	node := p.newNode(NodeProgram)
 
 	header, err := p.parseProgramHeader()
 	if err != nil {
-		return nil, err
+		header = p.synchronize(err, declarationSync...)
 	}
 	node.Children = append(node.Children, header)
 
 	decl, err := p.parseDeclarationPart()
 	if err != nil {
-		return nil, err
+		decl = p.synchronize(err, string(milestone1.KwBegin))
 	}
 	node.Children = append(node.Children, decl)
 
 	compound, err := p.parseCompoundStatement()
 	if err != nil {
-		return nil, err
+		compound = p.synchronize(err)
 	}
 	node.Children = append(node.Children, compound)
 
 	dot, err := p.consume("DOT", ".", "Expected '.' at end of program")
 	if err != nil {
-		return nil, err
+		p.report(err)
+	} else {
+		node.Children = append(node.Children, dot)
 	}
-	node.Children = append(node.Children, dot)
 
+	// Tree tetap dikembalikan (parsial) supaya bagian yang valid bisa dianalisis
+	if len(p.errors) > 0 {
+		return node, &p.errors[0]
+	}
+	if p.invalid {
+		return node, ErrInvalidTokens
+	}
 	return node, nil
 }
 
 // <program-header> -> program IDENTIFIER ;
 func (p *Parser) parseProgramHeader() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<program-header>"}
+	node := p.newNode(NodeProgramHeader)
 
-	prog, err := p.consume("KEYWORD", "program", "Expected 'program'")
+	prog, err := p.consumeKeyword(milestone1.KwProgram, "Expected 'program'")
 	if err != nil {
 		return nil, err
 	}
@@ -165,10 +297,10 @@ func (p *Parser) parseProgramHeader() (*AbstractSyntaxTree, error) {
 
 // <declaration-part> -> (const-decl)* (type-decl)* (var-decl)* (subprogram-decl)*
 func (p *Parser) parseDeclarationPart() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<declaration-part>"}
+	node := p.newNode(NodeDeclarationPart)
 
 	// (Loop untuk 'konstanta')
-	for p.check("KEYWORD", "konstanta") {
+	for p.checkKeyword(milestone1.KwConst) {
 		constDecl, err := p.parseConstDeclaration()
 		if err != nil {
 			return nil, err
@@ -176,7 +308,7 @@ func (p *Parser) parseDeclarationPart() (*AbstractSyntaxTree, error) {
 		node.Children = append(node.Children, constDecl)
 	}
 	// (Loop untuk 'tipe')
-	for p.check("KEYWORD", "tipe") {
+	for p.checkKeyword(milestone1.KwType) {
 		typeDecl, err := p.parseTypeDeclaration()
 		if err != nil {
 			return nil, err
@@ -184,7 +316,7 @@ func (p *Parser) parseDeclarationPart() (*AbstractSyntaxTree, error) {
 		node.Children = append(node.Children, typeDecl)
 	}
 	// (Loop untuk 'variabel')
-	for p.check("KEYWORD", "variabel") {
+	for p.checkKeyword(milestone1.KwVar) {
 		varDecl, err := p.parseVarDeclaration()
 		if err != nil {
 			return nil, err
@@ -192,7 +324,7 @@ func (p *Parser) parseDeclarationPart() (*AbstractSyntaxTree, error) {
 		node.Children = append(node.Children, varDecl)
 	}
 	// (Loop untuk 'prosedur'/'fungsi')
-	for p.check("KEYWORD", "prosedur") || p.check("KEYWORD", "fungsi") {
+	for p.checkKeyword(milestone1.KwProcedure) || p.checkKeyword(milestone1.KwFunction) {
 		subprogDecl, err := p.parseSubprogramDeclaration()
 		if err != nil {
 			return nil, err
@@ -202,80 +334,76 @@ func (p *Parser) parseDeclarationPart() (*AbstractSyntaxTree, error) {
 	return node, nil
 }
 
-// <const-declaration> -> konstanta (ID = value ;)+
+// <const-declaration> -> konstanta (ID = <expression> ;)+
 func (p *Parser) parseConstDeclaration() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<const-declaration>"}
+	node := p.newNode(NodeConstDeclaration)
 
-	kw, _ := p.consume("KEYWORD", "konstanta", "Expected 'konstanta'")
+	kw, _ := p.consumeKeyword(milestone1.KwConst, "Expected 'konstanta'")
 	node.Children = append(node.Children, kw)
 
 	// (Loop untuk (...)+)
 	for p.checkType("IDENTIFIER") {
-		id, err := p.consumeType("IDENTIFIER", "Expected constant name")
+		constDef, err := p.parseConstDef()
 		if err != nil {
-			return nil, err
+			constDef = p.synchronize(err, append([]string{";"}, declarationSync...)...)
+			if p.check("SEMICOLON", ";") {
+				constDef.Children = append(constDef.Children, newLeaf(p.advance()))
+			}
 		}
+		node.Children = append(node.Children, constDef)
+	}
+	return node, nil
+}
 
-		// (Spek minta '='. M1 kamu (tokenize.go) nge-token '=' sebagai RELATIONAL_OPERATOR)
-		eq, err := p.consume("RELATIONAL_OPERATOR", "=", "Expected '='")
-		if err != nil {
-			return nil, err
-		}
+// <const-def> -> ID = <expression> ;
+func (p *Parser) parseConstDef() (*AbstractSyntaxTree, error) {
+	// (Bikin sub-node biar rapi)
+	constDef := p.newNode(NodeConstDef)
 
-		// (Spek minta 'value', kita anggap NUMBER atau STRING)
-		var val *AbstractSyntaxTree
-		if p.checkType("NUMBER") {
-			val, _ = p.consumeType("NUMBER", "")
-		} else if p.checkType("STRING_LITERAL") {
-			val, _ = p.consumeType("STRING_LITERAL", "")
-		} else {
-			return nil, fmt.Errorf("Expected NUMBER or STRING_LITERAL for constant value")
-		}
+	id, err := p.consumeType("IDENTIFIER", "Expected constant name")
+	if err != nil {
+		return nil, err
+	}
 
-		semi, err := p.consume("SEMICOLON", ";", "Expected ';'")
-		if err != nil {
-			return nil, err
-		}
+	// (Spek minta '='. M1 kamu (tokenize.go) nge-token '=' sebagai RELATIONAL_OPERATOR)
+	eq, err := p.consume("RELATIONAL_OPERATOR", "=", "Expected '='")
+	if err != nil {
+		return nil, err
+	}
 
-		// (Bikin sub-node biar rapi)
-		constDef := &AbstractSyntaxTree{Value: "<const-def>"}
-		constDef.Children = append(constDef.Children, id, eq, val, semi)
-		node.Children = append(node.Children, constDef)
+	// (Nilainya ekspresi konstan, misal 10 atau N * 2; dievaluasi di semantic analyzer)
+	val, err := p.parseExpression()
+	if err != nil {
+		return nil, err
 	}
-	return node, nil
+
+	semi, err := p.consume("SEMICOLON", ";", "Expected ';'")
+	if err != nil {
+		return nil, err
+	}
+
+	constDef.Children = append(constDef.Children, id, eq, val, semi)
+	return constDef, nil
 }
 
 // <var-declaration> -> variabel (identifier-list : type ;)+
 func (p *Parser) parseVarDeclaration() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<var-declaration>"}
+	node := p.newNode(NodeVarDeclaration)
 
-	kw, _ := p.consume("KEYWORD", "variabel", "Expected 'variabel'")
+	kw, _ := p.consumeKeyword(milestone1.KwVar, "Expected 'variabel'")
 	node.Children = append(node.Children, kw)
 
 	for { // (Loop untuk ...)+
-		idList, err := p.parseIdentifierList()
-		if err != nil {
-			return nil, err
-		}
-		node.Children = append(node.Children, idList)
-
-		col, err := p.consume("COLON", ":", "Expected ':'")
+		group, err := p.parseVarGroup()
 		if err != nil {
-			return nil, err
-		}
-		node.Children = append(node.Children, col)
-
-		typ, err := p.parseType()
-		if err != nil {
-			return nil, err
-		}
-		node.Children = append(node.Children, typ)
-
-		semi, err := p.consume("SEMICOLON", ";", "Expected ';'")
-		if err != nil {
-			return nil, err
+			// Grup yang rusak diganti node <error>, lanjut ke grup berikutnya
+			errNode := p.synchronize(err, append([]string{";"}, declarationSync...)...)
+			if p.check("SEMICOLON", ";") {
+				errNode.Children = append(errNode.Children, newLeaf(p.advance()))
+			}
+			group = []*AbstractSyntaxTree{errNode}
 		}
-		node.Children = append(node.Children, semi)
+		node.Children = append(node.Children, group...)
 
 		// (Jika token selanjutnya bukan ID, stop loop var-decl)
 		if !p.checkType("IDENTIFIER") {
@@ -285,9 +413,34 @@ func (p *Parser) parseVarDeclaration() (*AbstractSyntaxTree, error) {
 	return node, nil
 }
 
+// Satu grup deklarasi variabel: <identifier-list> : <type> ;
+func (p *Parser) parseVarGroup() ([]*AbstractSyntaxTree, error) {
+	idList, err := p.parseIdentifierList()
+	if err != nil {
+		return nil, err
+	}
+
+	col, err := p.consume("COLON", ":", "Expected ':'")
+	if err != nil {
+		return nil, err
+	}
+
+	typ, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+
+	semi, err := p.consume("SEMICOLON", ";", "Expected ';'")
+	if err != nil {
+		return nil, err
+	}
+
+	return []*AbstractSyntaxTree{idList, col, typ, semi}, nil
+}
+
 // <identifier-list> -> IDENTIFIER (, IDENTIFIER)*
 func (p *Parser) parseIdentifierList() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<identifier-list>"}
+	node := p.newNode(NodeIdentifierList)
 
 	id, err := p.consumeType("IDENTIFIER", "Expected identifier")
 	if err != nil {
@@ -297,7 +450,7 @@ func (p *Parser) parseIdentifierList() (*AbstractSyntaxTree, error) {
 
 	for p.check("COMMA", ",") {
 		comma := p.advance()
-		node.Children = append(node.Children, &AbstractSyntaxTree{Value: comma.String()})
+		node.Children = append(node.Children, newLeaf(comma))
 
 		id2, err := p.consumeType("IDENTIFIER", "Expected identifier after comma")
 		if err != nil {
@@ -310,80 +463,104 @@ func (p *Parser) parseIdentifierList() (*AbstractSyntaxTree, error) {
 
 // <type> -> integer | boolean | real | char | <array-type> | IDENTIFIER
 func (p *Parser) parseType() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<type>"}
+	node := p.newNode(NodeType)
 
 	// Built-in types
-	if p.check("KEYWORD", "integer") ||
-		p.check("KEYWORD", "boolean") ||
-		// p.check("KEYWORD", "real") ||
-		p.check("KEYWORD", "char") {
+	if p.checkKeyword(milestone1.KwInteger) ||
+		p.checkKeyword(milestone1.KwBoolean) ||
+		p.checkKeyword(milestone1.KwReal) ||
+		p.checkKeyword(milestone1.KwChar) ||
+		p.checkKeyword(milestone1.KwString) {
 
 		t := p.advance()
-		node.Children = append(node.Children, &AbstractSyntaxTree{Value: t.String()})
+		node.Children = append(node.Children, newLeaf(t))
 		return node, nil
 	}
 
 	// Array type
-	if p.check("KEYWORD", "larik") {
+	if p.checkKeyword(milestone1.KwArray) {
 		return p.parseArrayType()
 	}
 
+	// Enumerated type: ( ID, ID, ... )
+	if p.check("LPARENTHESIS", "(") {
+		return p.parseEnumType()
+	}
+
+	// Subrange type: konstanta .. konstanta
+	if p.startsSubrange() {
+		return p.parseSubrangeType()
+	}
+
 	// Record type
-	if p.check("KEYWORD", "rekaman") {
+	if p.checkKeyword(milestone1.KwRecord) {
 		return p.parseRecordType()
 	}
 
 	// User-defined type (IDENTIFIER)
 	if p.checkType("IDENTIFIER") {
 		t := p.advance()
-		node.Children = append(node.Children, &AbstractSyntaxTree{Value: t.String()})
+		node.Children = append(node.Children, newLeaf(t))
 		return node, nil
 	}
 
-	return nil, fmt.Errorf("Unknown type at line %d", p.peek().Line)
+	return nil, p.errorf(diagnostic.UnexpectedToken, "Unknown type")
 }
 
 // <type-declaration> -> tipe ( ID = <type> ; )+
 func (p *Parser) parseTypeDeclaration() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<type-declaration>"}
+	node := p.newNode(NodeTypeDeclaration)
 
-	kw, _ := p.consume("KEYWORD", "tipe", "")
+	kw, _ := p.consumeKeyword(milestone1.KwType, "")
 	node.Children = append(node.Children, kw)
 
 	// One or more type definitions
 	for p.checkType("IDENTIFIER") {
-		id, err := p.consumeType("IDENTIFIER", "Expected type name")
+		group, err := p.parseTypeDef()
 		if err != nil {
-			return nil, err
+			errNode := p.synchronize(err, append([]string{";"}, declarationSync...)...)
+			if p.check("SEMICOLON", ";") {
+				errNode.Children = append(errNode.Children, newLeaf(p.advance()))
+			}
+			group = []*AbstractSyntaxTree{errNode}
 		}
-		node.Children = append(node.Children, id)
+		node.Children = append(node.Children, group...)
+	}
+	return node, nil
+}
 
-		eq, err := p.consume("RELATIONAL_OPERATOR", "=", "Expected '=' in type declaration")
-		if err != nil {
-			return nil, err
-		}
-		node.Children = append(node.Children, eq)
+// Satu definisi tipe: ID = <type> ;
+func (p *Parser) parseTypeDef() ([]*AbstractSyntaxTree, error) {
+	id, err := p.consumeType("IDENTIFIER", "Expected type name")
+	if err != nil {
+		return nil, err
+	}
 
-		t, err := p.parseType()
-		if err != nil {
-			return nil, err
-		}
-		node.Children = append(node.Children, t)
+	eq, err := p.consume("RELATIONAL_OPERATOR", "=", "Expected '=' in type declaration")
+	if err != nil {
+		return nil, err
+	}
 
-		semi, err := p.consume("SEMICOLON", ";", "Expected ';' after type declaration")
-		if err != nil {
-			return nil, err
-		}
-		node.Children = append(node.Children, semi)
+	t, err := p.parseType()
+	if err != nil {
+		return nil, err
 	}
-	return node, nil
+
+	semi, err := p.consume("SEMICOLON", ";", "Expected ';' after type declaration")
+	if err != nil {
+		return nil, err
+	}
+
+	return []*AbstractSyntaxTree{id, eq, t, semi}, nil
 }
 
-// <array-type> -> larik [ NUMBER .. NUMBER ] dari <type>
+// <array-type> -> larik [ index (, index)* ] dari <type>
+// index -> <expression> .. <expression> | <index-type>
+// Setiap index adalah satu dimensi (larik [1..3, 1..4] dari real = matriks 3x4, larik [Hari] dari integer)
 func (p *Parser) parseArrayType() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<array-type>"}
+	node := p.newNode(NodeArrayType)
 
-	larik, err := p.consume("KEYWORD", "larik", "Expected 'larik'")
+	larik, err := p.consumeKeyword(milestone1.KwArray, "Expected 'larik'")
 	if err != nil {
 		return nil, err
 	}
@@ -395,50 +572,130 @@ func (p *Parser) parseArrayType() (*AbstractSyntaxTree, error) {
 	}
 	node.Children = append(node.Children, lp)
 
-	lower, err := p.consumeType("NUMBER", "Expected lower bound for array")
+	if err := p.parseArrayIndex(node); err != nil {
+		return nil, err
+	}
+	for p.check("COMMA", ",") {
+		comma := p.advance()
+		node.Children = append(node.Children, newLeaf(comma))
+
+		if err := p.parseArrayIndex(node); err != nil {
+			return nil, err
+		}
+	}
+
+	rp, err := p.consume("RBRACKET", "]", "Expected ']' after array range")
 	if err != nil {
 		return nil, err
 	}
+	node.Children = append(node.Children, rp)
+
+	dari, err := p.consumeKeyword(milestone1.KwOf, "Expected 'dari' after array range")
+	if err != nil {
+		return nil, err
+	}
+	node.Children = append(node.Children, dari)
+
+	baseType, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	node.Children = append(node.Children, baseType)
+
+	return node, nil
+}
+
+// Satu indeks array: nama tipe ordinal (<index-type>) atau range <expression> .. <expression>
+func (p *Parser) parseArrayIndex(node *AbstractSyntaxTree) error {
+	next := p.tokens[p.current+1]
+	isTypeName := p.checkType("IDENTIFIER") || p.checkKeyword(milestone1.KwChar) || p.checkKeyword(milestone1.KwBoolean) || p.checkKeyword(milestone1.KwInteger)
+	if isTypeName && (next.Type == "COMMA" || next.Type == "RBRACKET") {
+		index := p.newNode(NodeIndexType)
+		index.Children = append(index.Children, newLeaf(p.advance()))
+		node.Children = append(node.Children, index)
+		return nil
+	}
+	return p.parseArrayRange(node)
+}
+
+// Satu range: <expression> .. <expression> (anak-anaknya langsung ditambahkan ke node)
+// Batasnya ekspresi konstan, misal [1..N] atau [0..N * 2 - 1]
+func (p *Parser) parseArrayRange(node *AbstractSyntaxTree) error {
+	lower, err := p.parseExpression()
+	if err != nil {
+		return err
+	}
 	node.Children = append(node.Children, lower)
 
 	rangeOp, err := p.consume("RANGE_OPERATOR", "..", "Expected '..' in array range")
 	if err != nil {
-		return nil, err
+		return err
 	}
 	node.Children = append(node.Children, rangeOp)
 
-	upper, err := p.consumeType("NUMBER", "Expected upper bound for array")
+	upper, err := p.parseExpression()
 	if err != nil {
-		return nil, err
+		return err
 	}
 	node.Children = append(node.Children, upper)
 
-	rp, err := p.consume("RBRACKET", "]", "Expected ']' after array range")
+	return nil
+}
+
+// <enum-type> -> ( <identifier-list> )
+func (p *Parser) parseEnumType() (*AbstractSyntaxTree, error) {
+	node := p.newNode(NodeEnumType)
+
+	lp, err := p.consume("LPARENTHESIS", "(", "Expected '('")
 	if err != nil {
 		return nil, err
 	}
-	node.Children = append(node.Children, rp)
+	node.Children = append(node.Children, lp)
 
-	dari, err := p.consume("KEYWORD", "dari", "Expected 'dari' after array range")
+	ids, err := p.parseIdentifierList()
 	if err != nil {
 		return nil, err
 	}
-	node.Children = append(node.Children, dari)
+	node.Children = append(node.Children, ids)
 
-	baseType, err := p.parseType()
+	rp, err := p.consume("RPARENTHESIS", ")", "Expected ')' after enumeration values")
 	if err != nil {
 		return nil, err
 	}
-	node.Children = append(node.Children, baseType)
+	node.Children = append(node.Children, rp)
+
+	return node, nil
+}
 
+// <subrange-type> -> <expression> .. <expression>
+func (p *Parser) parseSubrangeType() (*AbstractSyntaxTree, error) {
+	node := p.newNode(NodeSubrangeType)
+	if err := p.parseArrayRange(node); err != nil {
+		return nil, err
+	}
 	return node, nil
 }
 
+// Apakah tipe di posisi sekarang adalah subrange (ada '..' setelah ekspresi sederhana tanpa kurung)
+// Dipakai untuk membedakan "Nilai" (nama tipe) dari "N..M" atau "-5..5"
+func (p *Parser) startsSubrange() bool {
+	for i := p.current; i < len(p.tokens); i++ {
+		switch p.tokens[i].Type {
+		case "RANGE_OPERATOR":
+			return i > p.current
+		case "NUMBER", "CHAR_LITERAL", "IDENTIFIER", "ARITHMETIC_OPERATOR":
+			continue
+		}
+		return false
+	}
+	return false
+}
+
 // <record-type> -> rekaman <field-list> selesai
 func (p *Parser) parseRecordType() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<record-type>"}
+	node := p.newNode(NodeRecordType)
 
-	rekaman, err := p.consume("KEYWORD", "rekaman", "Expected 'rekaman'")
+	rekaman, err := p.consumeKeyword(milestone1.KwRecord, "Expected 'rekaman'")
 	if err != nil {
 		return nil, err
 	}
@@ -451,7 +708,7 @@ func (p *Parser) parseRecordType() (*AbstractSyntaxTree, error) {
 	}
 	node.Children = append(node.Children, fieldList)
 
-	selesai, err := p.consume("KEYWORD", "selesai", "Expected 'selesai' after record fields")
+	selesai, err := p.consumeKeyword(milestone1.KwEnd, "Expected 'selesai' after record fields")
 	if err != nil {
 		return nil, err
 	}
@@ -462,7 +719,7 @@ func (p *Parser) parseRecordType() (*AbstractSyntaxTree, error) {
 
 // <field-list> -> <identifier-list> : <type> (; <identifier-list> : <type>)*
 func (p *Parser) parseFieldList() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<field-list>"}
+	node := p.newNode(NodeFieldList)
 
 	// First field declaration
 	idList, err := p.parseIdentifierList()
@@ -486,10 +743,10 @@ func (p *Parser) parseFieldList() (*AbstractSyntaxTree, error) {
 	// Additional field declarations separated by semicolons
 	for p.check("SEMICOLON", ";") {
 		semi := p.advance()
-		node.Children = append(node.Children, &AbstractSyntaxTree{Value: semi.String()})
+		node.Children = append(node.Children, newLeaf(semi))
 
 		// Check if there's another field declaration or if we're at 'selesai'
-		if p.check("KEYWORD", "selesai") {
+		if p.checkKeyword(milestone1.KwEnd) {
 			break
 		}
 
@@ -518,9 +775,9 @@ func (p *Parser) parseFieldList() (*AbstractSyntaxTree, error) {
 // <variable> or <indexed-variable> -> ID ( [ expr ] )*
 func (p *Parser) parseVariableReference() (*AbstractSyntaxTree, error) {
 	if !p.checkType("IDENTIFIER") {
-		return nil, fmt.Errorf("Expected identifier for variable reference, got %s(%s)", p.peek().Type, p.peek().Value)
+		return nil, p.errorf(diagnostic.ExpectedToken, "Expected identifier for variable reference, got %s(%s)", p.peek().Type, p.peek().Lexeme)
 	}
-	node := &AbstractSyntaxTree{Value: "<variable>"}
+	node := p.newNode(NodeVariable)
 
 	name, err := p.consumeType("IDENTIFIER", "Expected variable name")
 	if err != nil {
@@ -528,8 +785,20 @@ func (p *Parser) parseVariableReference() (*AbstractSyntaxTree, error) {
 	}
 	node.Children = append(node.Children, name)
 
-	// zero or more indexing
-	for p.check("LBRACKET", "[") {
+	// zero or more selector: [index, ...] atau .field (boleh dicampur, misal data[i].nilai[j])
+	for p.check("LBRACKET", "[") || p.check("DOT", ".") {
+		if p.check("DOT", ".") {
+			dot := p.advance()
+			node.Children = append(node.Children, newLeaf(dot))
+
+			field, err := p.consumeType("IDENTIFIER", "Expected field name after '.'")
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, field)
+			continue
+		}
+
 		lb, _ := p.consume("LBRACKET", "[", "Expected '['")
 		node.Children = append(node.Children, lb)
 
@@ -539,6 +808,18 @@ func (p *Parser) parseVariableReference() (*AbstractSyntaxTree, error) {
 		}
 		node.Children = append(node.Children, expr)
 
+		// m[i, j] sama dengan m[i][j]
+		for p.check("COMMA", ",") {
+			comma := p.advance()
+			node.Children = append(node.Children, newLeaf(comma))
+
+			expr, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, expr)
+		}
+
 		rb, err := p.consume("RBRACKET", "]", "Expected ']' after index expression")
 		if err != nil {
 			return nil, err
@@ -551,35 +832,69 @@ func (p *Parser) parseVariableReference() (*AbstractSyntaxTree, error) {
 
 // <subprogram-declaration> -> (prosedur | fungsi) ID ( params ) (: type)? ; <declaration-part> <compound-statement> ;
 func (p *Parser) parseSubprogramDeclaration() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<subprogram-declaration>"}
+	node := p.newNode(NodeSubprogramDeclaration)
+
+	// Header rusak: lompati sampai ';' lalu tetap parse deklarasi lokal dan body
+	if err := p.parseSubprogramHeader(node); err != nil {
+		errNode := p.synchronize(err, append([]string{";"}, declarationSync...)...)
+		if p.check("SEMICOLON", ";") {
+			errNode.Children = append(errNode.Children, newLeaf(p.advance()))
+		}
+		node.Children = append(node.Children, errNode)
+	}
+
+	declPart, err := p.parseDeclarationPart()
+	if err != nil {
+		return nil, err
+	}
+	node.Children = append(node.Children, declPart)
+
+	// compound statement (body)
+	body, err := p.parseCompoundStatement()
+	if err != nil {
+		body = p.synchronize(err, ";", string(milestone1.KwProcedure), string(milestone1.KwFunction))
+	}
+	node.Children = append(node.Children, body)
+
+	semi2, err := p.consume("SEMICOLON", ";", "Expected ';' after subprogram body")
+	if err != nil {
+		p.report(err)
+	} else {
+		node.Children = append(node.Children, semi2)
+	}
 
+	return node, nil
+}
+
+// Header subprogram: (prosedur | fungsi) ID ( params ) (: type)? ;
+func (p *Parser) parseSubprogramHeader(node *AbstractSyntaxTree) error {
 	// prosedur or fungsi
 	var kw *AbstractSyntaxTree
 	var err error
 	var isFungsi bool
-	if p.check("KEYWORD", "fungsi") {
-		kw, err = p.consume("KEYWORD", "fungsi", "")
+	if p.checkKeyword(milestone1.KwFunction) {
+		kw, err = p.consumeKeyword(milestone1.KwFunction, "")
 		isFungsi = true
 	} else {
-		kw, err = p.consume("KEYWORD", "prosedur", "")
+		kw, err = p.consumeKeyword(milestone1.KwProcedure, "")
 		isFungsi = false
 	}
 	if err != nil {
-		return nil, err
+		return err
 	}
 	node.Children = append(node.Children, kw)
 
 	// function/procedure name
 	name, err := p.consumeType("IDENTIFIER", "Expected subprogram name")
 	if err != nil {
-		return nil, err
+		return err
 	}
 	node.Children = append(node.Children, name)
 
 	// parameters
 	lp, err := p.consume("LPARENTHESIS", "(", "Expected '(' for parameters")
 	if err != nil {
-		return nil, err
+		return err
 	}
 	node.Children = append(node.Children, lp)
 
@@ -587,14 +902,14 @@ func (p *Parser) parseSubprogramDeclaration() (*AbstractSyntaxTree, error) {
 	if !p.check("RPARENTHESIS", ")") {
 		params, err := p.parseParameterList()
 		if err != nil {
-			return nil, err
+			return err
 		}
 		node.Children = append(node.Children, params)
 	}
 
 	rp, err := p.consume("RPARENTHESIS", ")", "Expected ')' after parameters")
 	if err != nil {
-		return nil, err
+		return err
 	}
 	node.Children = append(node.Children, rp)
 
@@ -602,101 +917,82 @@ func (p *Parser) parseSubprogramDeclaration() (*AbstractSyntaxTree, error) {
 	if isFungsi {
 		colon, err := p.consume("COLON", ":", "Expected ':' before return type")
 		if err != nil {
-			return nil, err
+			return err
 		}
 		node.Children = append(node.Children, colon)
 
 		retType, err := p.parseType()
 		if err != nil {
-			return nil, err
+			return err
 		}
 		node.Children = append(node.Children, retType)
 	}
 
 	semi1, err := p.consume("SEMICOLON", ";", "Expected ';' after subprogram header")
 	if err != nil {
-		return nil, err
+		return err
 	}
 	node.Children = append(node.Children, semi1)
 
-	declPart, err := p.parseDeclarationPart()
-	if err != nil {
-		return nil, err
-	}
-	node.Children = append(node.Children, declPart)
+	return nil
+}
 
-	// compound statement (body)
-	body, err := p.parseCompoundStatement()
-	if err != nil {
+// <parameter-list> -> param-group (; param-group)*
+// <param-group> -> [variabel] identifier-list : type
+func (p *Parser) parseParameterList() (*AbstractSyntaxTree, error) {
+	node := p.newNode(NodeParameterList)
+
+	// first parameter group
+	if err := p.parseParameterGroup(node); err != nil {
 		return nil, err
 	}
-	node.Children = append(node.Children, body)
 
-	semi2, err := p.consume("SEMICOLON", ";", "Expected ';' after subprogram body")
-	if err != nil {
-		return nil, err
+	// additional parameter groups separated by ;
+	for p.check("SEMICOLON", ";") {
+		semi := p.advance()
+		node.Children = append(node.Children, newLeaf(semi))
+
+		if err := p.parseParameterGroup(node); err != nil {
+			return nil, err
+		}
 	}
-	node.Children = append(node.Children, semi2)
 
 	return node, nil
 }
 
-// <parameter-list> -> param-group (; param-group)*
-// <param-group> -> identifier-list : type
-func (p *Parser) parseParameterList() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<parameter-list>"}
+// Satu grup parameter, anak-anaknya langsung ditambahkan ke <parameter-list>
+// Keyword 'variabel' di depan menandakan parameter by-reference
+func (p *Parser) parseParameterGroup(node *AbstractSyntaxTree) error {
+	if p.checkKeyword(milestone1.KwVar) {
+		node.Children = append(node.Children, newLeaf(p.advance()))
+	}
 
-	// first parameter group
 	idList, err := p.parseIdentifierList()
 	if err != nil {
-		return nil, err
+		return err
 	}
 	node.Children = append(node.Children, idList)
 
 	colon, err := p.consume("COLON", ":", "Expected ':' in parameter")
 	if err != nil {
-		return nil, err
+		return err
 	}
 	node.Children = append(node.Children, colon)
 
 	typ, err := p.parseType()
 	if err != nil {
-		return nil, err
+		return err
 	}
 	node.Children = append(node.Children, typ)
 
-	// additional parameter groups separated by ;
-	for p.check("SEMICOLON", ";") {
-		semi := p.advance()
-		node.Children = append(node.Children, &AbstractSyntaxTree{Value: semi.String()})
-
-		idList2, err := p.parseIdentifierList()
-		if err != nil {
-			return nil, err
-		}
-		node.Children = append(node.Children, idList2)
-
-		colon2, err := p.consume("COLON", ":", "Expected ':' in parameter")
-		if err != nil {
-			return nil, err
-		}
-		node.Children = append(node.Children, colon2)
-
-		typ2, err := p.parseType()
-		if err != nil {
-			return nil, err
-		}
-		node.Children = append(node.Children, typ2)
-	}
-
-	return node, nil
+	return nil
 }
 
 // <compound-statement> -> mulai <statement-list> selesai
 func (p *Parser) parseCompoundStatement() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<compound-statement>"}
+	node := p.newNode(NodeCompoundStatement)
 
-	start, err := p.consume("KEYWORD", "mulai", "Expected 'mulai'")
+	start, err := p.consumeKeyword(milestone1.KwBegin, "Expected 'mulai'")
 	if err != nil {
 		return nil, err
 	}
@@ -708,9 +1004,14 @@ func (p *Parser) parseCompoundStatement() (*AbstractSyntaxTree, error) {
 	}
 	node.Children = append(node.Children, stmts)
 
-	end, err := p.consume("KEYWORD", "selesai", "Expected 'selesai'")
+	end, err := p.consumeKeyword(milestone1.KwEnd, "Expected 'selesai'")
 	if err != nil {
-		return nil, err
+		// Lompati sisa blok sampai 'selesai' penutupnya
+		node.Children = append(node.Children, p.synchronize(err, string(milestone1.KwEnd)))
+		end, err = p.consumeKeyword(milestone1.KwEnd, "Expected 'selesai'")
+		if err != nil {
+			return node, nil
+		}
 	}
 	node.Children = append(node.Children, end)
 
@@ -719,39 +1020,48 @@ func (p *Parser) parseCompoundStatement() (*AbstractSyntaxTree, error) {
 
 // <statement-list> -> statement (; statement)*
 func (p *Parser) parseStatementList() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<statement-list>"}
+	node := p.newNode(NodeStatementList)
 
-	// (Handle jika blok 'mulai' kosong)
-	if p.check("KEYWORD", "selesai") {
+	// (Handle jika blok 'mulai' / 'ulangi' kosong)
+	if p.checkKeyword(milestone1.KwEnd) || p.checkKeyword(milestone1.KwUntil) {
 		return node, nil // Boleh kosong
 	}
 
-	stmt, err := p.parseStatement()
-	if err != nil {
-		return nil, err
-	}
-	node.Children = append(node.Children, stmt)
+	node.Children = append(node.Children, p.parseStatementOrError())
 
-	for p.check("SEMICOLON", ";") {
-		semi := p.advance()
+	for !p.endsStatementList() {
+		if p.check("SEMICOLON", ";") {
+			semi := p.advance()
+			node.Children = append(node.Children, newLeaf(semi))
 
-		// (Handle semicolon sebelum 'selesai')
-		if p.check("KEYWORD", "selesai") {
-			node.Children = append(node.Children, &AbstractSyntaxTree{Value: semi.String()})
-			break
+			// (Handle semicolon sebelum 'selesai' / 'sampai')
+			if p.checkKeyword(milestone1.KwEnd) || p.checkKeyword(milestone1.KwUntil) {
+				break
+			}
+		} else if p.startsStatement() {
+			// ';' hilang di antara dua statement: catat, lalu anggap ada
+			p.report(p.errorf(diagnostic.ExpectedToken, "Expected ';' between statements (Got: %s(%s))", p.peek().Type, p.peek().Lexeme))
+		} else {
+			// Token asing: lompati sampai ';' atau 'selesai'
+			err := p.errorf(diagnostic.UnexpectedToken, "Expected ';', 'selesai' or 'sampai' (Got: %s(%s))", p.peek().Type, p.peek().Lexeme)
+			node.Children = append(node.Children, p.synchronize(err, statementSync...))
+			continue
 		}
 
-		node.Children = append(node.Children, &AbstractSyntaxTree{Value: semi.String()})
-
-		stmt2, err := p.parseStatement()
-		if err != nil {
-			return nil, err
-		}
-		node.Children = append(node.Children, stmt2)
+		node.Children = append(node.Children, p.parseStatementOrError())
 	}
 	return node, nil
 }
 
+// Parse satu statement; kalau gagal, hasilnya node <error> sampai ';' atau 'selesai'
+func (p *Parser) parseStatementOrError() *AbstractSyntaxTree {
+	stmt, err := p.parseStatement()
+	if err != nil {
+		return p.synchronize(err, statementSync...)
+	}
+	return stmt
+}
+
 // Router untuk statement
 func (p *Parser) parseStatement() (*AbstractSyntaxTree, error) {
 	// 1. Assignment (ID := ... or ID[...] := ...)
@@ -760,7 +1070,7 @@ func (p *Parser) parseStatement() (*AbstractSyntaxTree, error) {
 		// Lookahead to determine if it's assignment or procedure call
 		if p.current+1 < len(p.tokens) {
 			nextToken := p.tokens[p.current+1]
-			if nextToken.Type == "ASSIGN_OPERATOR" || nextToken.Value == "[" {
+			if nextToken.Type == "ASSIGN_OPERATOR" || nextToken.Value == "[" || nextToken.Value == "." {
 				return p.parseAssignment()
 			}
 			// 2. Procedure Call (ID (...) )
@@ -769,45 +1079,55 @@ func (p *Parser) parseStatement() (*AbstractSyntaxTree, error) {
 			}
 		}
 		// If identifier alone (e.g. empty statement or error)
-		return nil, fmt.Errorf("Unexpected identifier in statement: %s", p.peek().Value)
+		return nil, p.errorf(diagnostic.UnexpectedToken, "Unexpected identifier in statement: %s", p.peek().Lexeme)
 	}
 
 	// 3. If (jika)
-	if p.check("KEYWORD", "jika") {
+	if p.checkKeyword(milestone1.KwIf) {
 		return p.parseIf()
 	}
 
 	// 4. While (selama)
-	if p.check("KEYWORD", "selama") {
+	if p.checkKeyword(milestone1.KwWhile) {
 		return p.parseWhile()
 	}
 
 	// 5. For (untuk)
-	if p.check("KEYWORD", "untuk") {
+	if p.checkKeyword(milestone1.KwFor) {
 		return p.parseForStatement()
 	}
 
+	// 5b. Repeat (ulangi .. sampai)
+	if p.checkKeyword(milestone1.KwRepeat) {
+		return p.parseRepeat()
+	}
+
+	// 5c. Case (kasus .. dari .. selesai)
+	if p.checkKeyword(milestone1.KwCase) {
+		return p.parseCase()
+	}
+
 	// 6. Writeln (Keyword khusus)
 	if p.check("KEYWORD", "writeln") {
 		return p.parseProcedureCall()
 	}
 
 	// 7. Compound Nested (mulai..selesai)
-	if p.check("KEYWORD", "mulai") {
+	if p.checkKeyword(milestone1.KwBegin) {
 		return p.parseCompoundStatement()
 	}
 
-	// (Jika tidak ada, mungkin empty, tapi kita return error jika tidak terduga)
-	if p.check("KEYWORD", "selesai") {
-		return &AbstractSyntaxTree{Value: "<empty-statement>"}, nil
+	// Statement kosong: sebelum 'selesai', 'sampai', 'selain_itu' atau ';' (misalnya cabang kasus "'c': ;")
+	if p.checkKeyword(milestone1.KwEnd) || p.checkKeyword(milestone1.KwUntil) || p.checkKeyword(milestone1.KwElse) || p.check("SEMICOLON", ";") {
+		return p.newNode(NodeEmptyStatement), nil
 	}
 
-	return nil, fmt.Errorf("Unknown statement at line %d. Got: %s(%s)", p.peek().Line, p.peek().Type, p.peek().Value)
+	return nil, p.errorf(diagnostic.UnexpectedToken, "Unknown statement. Got: %s(%s)", p.peek().Type, p.peek().Lexeme)
 }
 
 // <assignment> -> ID := expression
 func (p *Parser) parseAssignment() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<assignment-statement>"}
+	node := p.newNode(NodeAssignmentStatement)
 
 	// left side can be variable or indexed variable
 	left, err := p.parseVariableReference()
@@ -830,7 +1150,7 @@ func (p *Parser) parseAssignment() (*AbstractSyntaxTree, error) {
 
 // <procedure-call> -> (ID | writeln) ( params )
 func (p *Parser) parseProcedureCall() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<procedure-call>"}
+	node := p.newNode(NodeProcedureCall)
 
 	var name *AbstractSyntaxTree
 	var err error
@@ -871,7 +1191,7 @@ func (p *Parser) parseProcedureCall() (*AbstractSyntaxTree, error) {
 
 // Helper untuk comma-separated expressions (untuk parameter list)
 func (p *Parser) parseExprList() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<parameter-list>"}
+	node := p.newNode(NodeParameterList)
 
 	e1, err := p.parseExpression()
 	if err != nil {
@@ -881,7 +1201,7 @@ func (p *Parser) parseExprList() (*AbstractSyntaxTree, error) {
 
 	for p.check("COMMA", ",") {
 		com := p.advance()
-		node.Children = append(node.Children, &AbstractSyntaxTree{Value: com.String()})
+		node.Children = append(node.Children, newLeaf(com))
 		e2, err := p.parseExpression()
 		if err != nil {
 			return nil, err
@@ -893,9 +1213,9 @@ func (p *Parser) parseExprList() (*AbstractSyntaxTree, error) {
 
 // <if-statement> -> jika expr maka stmt (selain_itu stmt)?
 func (p *Parser) parseIf() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<if-statement>"}
+	node := p.newNode(NodeIfStatement)
 
-	ifKw, _ := p.consume("KEYWORD", "jika", "")
+	ifKw, _ := p.consumeKeyword(milestone1.KwIf, "")
 	node.Children = append(node.Children, ifKw)
 
 	// (Bukan mockup lagi, panggil parseExpression)
@@ -905,7 +1225,7 @@ func (p *Parser) parseIf() (*AbstractSyntaxTree, error) {
 	}
 	node.Children = append(node.Children, expr)
 
-	thenKw, err := p.consume("KEYWORD", "maka", "Expected 'maka'")
+	thenKw, err := p.consumeKeyword(milestone1.KwThen, "Expected 'maka'")
 	if err != nil {
 		return nil, err
 	}
@@ -917,9 +1237,9 @@ func (p *Parser) parseIf() (*AbstractSyntaxTree, error) {
 	}
 	node.Children = append(node.Children, stmt)
 
-	if p.check("KEYWORD", "selain_itu") {
+	if p.checkKeyword(milestone1.KwElse) {
 		elseKw := p.advance()
-		node.Children = append(node.Children, &AbstractSyntaxTree{Value: elseKw.String()})
+		node.Children = append(node.Children, newLeaf(elseKw))
 		stmt2, err := p.parseStatement()
 		if err != nil {
 			return nil, err
@@ -932,9 +1252,9 @@ func (p *Parser) parseIf() (*AbstractSyntaxTree, error) {
 
 // <while-statement> -> selama expr lakukan stmt
 func (p *Parser) parseWhile() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<while-statement>"}
+	node := p.newNode(NodeWhileStatement)
 
-	wh, _ := p.consume("KEYWORD", "selama", "")
+	wh, _ := p.consumeKeyword(milestone1.KwWhile, "")
 	node.Children = append(node.Children, wh)
 
 	// (Bukan mockup lagi, panggil parseExpression)
@@ -944,7 +1264,7 @@ func (p *Parser) parseWhile() (*AbstractSyntaxTree, error) {
 	}
 	node.Children = append(node.Children, expr)
 
-	doKw, err := p.consume("KEYWORD", "lakukan", "Expected 'lakukan'")
+	doKw, err := p.consumeKeyword(milestone1.KwDo, "Expected 'lakukan'")
 	if err != nil {
 		return nil, err
 	}
@@ -959,11 +1279,164 @@ func (p *Parser) parseWhile() (*AbstractSyntaxTree, error) {
 	return node, nil
 }
 
+// <repeat-statement> -> ulangi <statement-list> sampai expr
+func (p *Parser) parseRepeat() (*AbstractSyntaxTree, error) {
+	node := p.newNode(NodeRepeatStatement)
+
+	kw, _ := p.consumeKeyword(milestone1.KwRepeat, "")
+	node.Children = append(node.Children, kw)
+
+	stmts, err := p.parseStatementList()
+	if err != nil {
+		return nil, err
+	}
+	node.Children = append(node.Children, stmts)
+
+	untilKw, err := p.consumeKeyword(milestone1.KwUntil, "Expected 'sampai'")
+	if err != nil {
+		return nil, err
+	}
+	node.Children = append(node.Children, untilKw)
+
+	expr, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	node.Children = append(node.Children, expr)
+
+	return node, nil
+}
+
+// <case-statement> -> kasus expr dari <case-element> (; <case-element>)* [;] [selain_itu <statement-list>] selesai
+func (p *Parser) parseCase() (*AbstractSyntaxTree, error) {
+	node := p.newNode(NodeCaseStatement)
+
+	kw, _ := p.consumeKeyword(milestone1.KwCase, "")
+	node.Children = append(node.Children, kw)
+
+	expr, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	node.Children = append(node.Children, expr)
+
+	ofKw, err := p.consumeKeyword(milestone1.KwOf, "Expected 'dari' after case selector")
+	if err != nil {
+		return nil, err
+	}
+	node.Children = append(node.Children, ofKw)
+
+	for {
+		element, err := p.parseCaseElement()
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, element)
+
+		if !p.check("SEMICOLON", ";") {
+			break
+		}
+		semi := p.advance()
+		node.Children = append(node.Children, newLeaf(semi))
+
+		// (Handle semicolon sebelum 'selain_itu' / 'selesai')
+		if p.checkKeyword(milestone1.KwElse) || p.checkKeyword(milestone1.KwEnd) {
+			break
+		}
+	}
+
+	// Cabang default
+	if p.checkKeyword(milestone1.KwElse) {
+		elseKw := p.advance()
+		node.Children = append(node.Children, newLeaf(elseKw))
+		stmts, err := p.parseStatementList()
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, stmts)
+	}
+
+	end, err := p.consumeKeyword(milestone1.KwEnd, "Expected 'selesai' to close case statement")
+	if err != nil {
+		return nil, err
+	}
+	node.Children = append(node.Children, end)
+
+	return node, nil
+}
+
+// <case-element> -> <case-label-list> : statement
+func (p *Parser) parseCaseElement() (*AbstractSyntaxTree, error) {
+	node := p.newNode(NodeCaseElement)
+
+	labels, err := p.parseCaseLabelList()
+	if err != nil {
+		return nil, err
+	}
+	node.Children = append(node.Children, labels)
+
+	colon, err := p.consume("COLON", ":", "Expected ':' after case label")
+	if err != nil {
+		return nil, err
+	}
+	node.Children = append(node.Children, colon)
+
+	stmt, err := p.parseStatement()
+	if err != nil {
+		return nil, err
+	}
+	node.Children = append(node.Children, stmt)
+
+	return node, nil
+}
+
+// <case-label-list> -> <case-label> (, <case-label>)*
+func (p *Parser) parseCaseLabelList() (*AbstractSyntaxTree, error) {
+	node := p.newNode(NodeCaseLabelList)
+
+	for {
+		label, err := p.parseCaseLabel()
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, label)
+
+		if !p.check("COMMA", ",") {
+			break
+		}
+		comma := p.advance()
+		node.Children = append(node.Children, newLeaf(comma))
+	}
+
+	return node, nil
+}
+
+// <case-label> -> [+|-] NUMBER | CHAR_LITERAL | STRING_LITERAL | true | false | [+|-] ID
+func (p *Parser) parseCaseLabel() (*AbstractSyntaxTree, error) {
+	node := p.newNode(NodeCaseLabel)
+
+	if p.check("ARITHMETIC_OPERATOR", "-") || p.check("ARITHMETIC_OPERATOR", "+") {
+		sign := p.advance()
+		node.Children = append(node.Children, newLeaf(sign))
+		if !p.checkType("NUMBER") && !p.checkType("IDENTIFIER") {
+			return nil, p.errorf(diagnostic.ExpectedToken, "Expected number or constant after sign in case label (Got: %s(%s))", p.peek().Type, p.peek().Lexeme)
+		}
+	}
+
+	if p.checkType("NUMBER") || p.checkType("CHAR_LITERAL") || p.checkType("STRING_LITERAL") || p.checkType("IDENTIFIER") ||
+		p.checkKeyword(milestone1.KwTrue) || p.checkKeyword(milestone1.KwFalse) {
+		node.Children = append(node.Children, newLeaf(p.advance()))
+		return node, nil
+	}
+
+	return nil, p.errorf(diagnostic.ExpectedToken, "Expected constant case label (Got: %s(%s))", p.peek().Type, p.peek().Lexeme)
+}
+
 // <for-statement> -> untuk ID := expr (ke|turun_ke) expr lakukan stmt
 func (p *Parser) parseForStatement() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<for-statement>"}
+	node := p.newNode(NodeForStatement)
 
-	kw, _ := p.consume("KEYWORD", "untuk", "")
+	kw, _ := p.consumeKeyword(milestone1.KwFor, "")
 	node.Children = append(node.Children, kw)
 
 	id, err := p.consumeType("IDENTIFIER", "Expected counter ID for 'for' loop")
@@ -985,11 +1458,11 @@ func (p *Parser) parseForStatement() (*AbstractSyntaxTree, error) {
 	node.Children = append(node.Children, startExpr)
 
 	// (ke | turun_ke)
-	if p.check("KEYWORD", "ke") || p.check("KEYWORD", "turun_ke") {
+	if p.checkKeyword(milestone1.KwTo) || p.checkKeyword(milestone1.KwDownTo) {
 		dir := p.advance()
-		node.Children = append(node.Children, &AbstractSyntaxTree{Value: dir.String()})
+		node.Children = append(node.Children, newLeaf(dir))
 	} else {
-		return nil, fmt.Errorf("Expected 'ke' or 'turun_ke' in for loop")
+		return nil, p.errorf(diagnostic.ExpectedToken, "Expected 'ke' or 'turun_ke' in for loop")
 	}
 
 	endExpr, err := p.parseExpression()
@@ -998,7 +1471,7 @@ func (p *Parser) parseForStatement() (*AbstractSyntaxTree, error) {
 	}
 	node.Children = append(node.Children, endExpr)
 
-	do, err := p.consume("KEYWORD", "lakukan", "Expected 'lakukan' in for loop")
+	do, err := p.consumeKeyword(milestone1.KwDo, "Expected 'lakukan' in for loop")
 	if err != nil {
 		return nil, err
 	}
@@ -1015,7 +1488,7 @@ func (p *Parser) parseForStatement() (*AbstractSyntaxTree, error) {
 
 // <expression> -> simple-expr (rel-op simple-expr)?
 func (p *Parser) parseExpression() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<expression>"}
+	node := p.newNode(NodeExpression)
 
 	left, err := p.parseSimpleExpression()
 	if err != nil {
@@ -1025,7 +1498,7 @@ func (p *Parser) parseExpression() (*AbstractSyntaxTree, error) {
 
 	if p.checkType("RELATIONAL_OPERATOR") {
 		op := p.advance()
-		node.Children = append(node.Children, &AbstractSyntaxTree{Value: op.String()})
+		node.Children = append(node.Children, newLeaf(op))
 		right, err := p.parseSimpleExpression()
 		if err != nil {
 			return nil, err
@@ -1037,12 +1510,12 @@ func (p *Parser) parseExpression() (*AbstractSyntaxTree, error) {
 
 // <simple-expression> -> (+|-)? term (add-op term)*
 func (p *Parser) parseSimpleExpression() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<simple-expression>"}
+	node := p.newNode(NodeSimpleExpression)
 
 	// (Handle unary +/-)
 	if p.check("ARITHMETIC_OPERATOR", "+") || p.check("ARITHMETIC_OPERATOR", "-") {
 		op := p.advance()
-		node.Children = append(node.Children, &AbstractSyntaxTree{Value: op.String()})
+		node.Children = append(node.Children, newLeaf(op))
 	}
 
 	left, err := p.parseTerm()
@@ -1051,9 +1524,9 @@ func (p *Parser) parseSimpleExpression() (*AbstractSyntaxTree, error) {
 	}
 	node.Children = append(node.Children, left)
 
-	for p.check("ARITHMETIC_OPERATOR", "+") || p.check("ARITHMETIC_OPERATOR", "-") || p.check("KEYWORD", "atau") {
+	for p.check("ARITHMETIC_OPERATOR", "+") || p.check("ARITHMETIC_OPERATOR", "-") || p.checkKeyword(milestone1.KwOr) {
 		op := p.advance()
-		node.Children = append(node.Children, &AbstractSyntaxTree{Value: op.String()})
+		node.Children = append(node.Children, newLeaf(op))
 		right, err := p.parseTerm()
 		if err != nil {
 			return nil, err
@@ -1065,7 +1538,7 @@ func (p *Parser) parseSimpleExpression() (*AbstractSyntaxTree, error) {
 
 // <term> -> factor (mul-op factor)*
 func (p *Parser) parseTerm() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<term>"}
+	node := p.newNode(NodeTerm)
 
 	left, err := p.parseFactor()
 	if err != nil {
@@ -1074,11 +1547,11 @@ func (p *Parser) parseTerm() (*AbstractSyntaxTree, error) {
 	node.Children = append(node.Children, left)
 
 	for p.check("ARITHMETIC_OPERATOR", "*") || p.check("ARITHMETIC_OPERATOR", "/") ||
-		p.check("ARITHMETIC_OPERATOR", "bagi") || p.check("ARITHMETIC_OPERATOR", "mod") ||
-		p.check("LOGICAL_OPERATOR", "dan") {
+		p.checkKeyword(milestone1.KwDiv) || p.checkKeyword(milestone1.KwMod) ||
+		p.checkKeyword(milestone1.KwAnd) {
 
 		op := p.advance()
-		node.Children = append(node.Children, &AbstractSyntaxTree{Value: op.String()})
+		node.Children = append(node.Children, newLeaf(op))
 		right, err := p.parseFactor()
 		if err != nil {
 			return nil, err
@@ -1090,12 +1563,12 @@ func (p *Parser) parseTerm() (*AbstractSyntaxTree, error) {
 
 // <factor> -> ID | ID(...) | NUM | ( expr ) | not factor
 func (p *Parser) parseFactor() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<factor>"}
+	node := p.newNode(NodeFactor)
 
 	// (NUMBER, REAL, STRING, CHAR, true, false)
-	if p.checkType("NUMBER") || p.checkType("REAL") || p.checkType("STRING_LITERAL") || p.checkType("CHAR_LITERAL") || p.check("KEYWORD", "true") || p.check("KEYWORD", "false") {
+	if p.checkType("NUMBER") || p.checkType("REAL") || p.checkType("STRING_LITERAL") || p.checkType("CHAR_LITERAL") || p.checkKeyword(milestone1.KwTrue) || p.checkKeyword(milestone1.KwFalse) {
 		t := p.advance()
-		node.Children = append(node.Children, &AbstractSyntaxTree{Value: t.String()})
+		node.Children = append(node.Children, newLeaf(t))
 		return node, nil
 	}
 
@@ -1124,7 +1597,7 @@ func (p *Parser) parseFactor() (*AbstractSyntaxTree, error) {
 	// ( <expression> )
 	if p.check("LPARENTHESIS", "(") {
 		lp := p.advance()
-		node.Children = append(node.Children, &AbstractSyntaxTree{Value: lp.String()})
+		node.Children = append(node.Children, newLeaf(lp))
 		expr, err := p.parseExpression()
 		if err != nil {
 			return nil, err
@@ -1139,9 +1612,9 @@ func (p *Parser) parseFactor() (*AbstractSyntaxTree, error) {
 	}
 
 	// 'tidak' factor
-	if p.check("LOGICAL_OPERATOR", "tidak") || p.check("KEYWORD", "tidak") {
+	if p.checkKeyword(milestone1.KwNot) {
 		not := p.advance()
-		node.Children = append(node.Children, &AbstractSyntaxTree{Value: not.String()})
+		node.Children = append(node.Children, newLeaf(not))
 		fact, err := p.parseFactor() // (Rekursif)
 		if err != nil {
 			return nil, err
@@ -1150,12 +1623,12 @@ func (p *Parser) parseFactor() (*AbstractSyntaxTree, error) {
 		return node, nil
 	}
 
-	return nil, fmt.Errorf("Unexpected token in factor: %s(%s)", p.peek().Type, p.peek().Value)
+	return nil, p.errorf(diagnostic.UnexpectedToken, "Unexpected token in factor: %s(%s)", p.peek().Type, p.peek().Lexeme)
 }
 
 // <function-call> -> ID ( <expr-list> )
 func (p *Parser) parseFunctionCall() (*AbstractSyntaxTree, error) {
-	node := &AbstractSyntaxTree{Value: "<function-call>"} // (Sesuai spek 26)
+	node := p.newNode(NodeFunctionCall) // (Sesuai spek 26)
 
 	name, _ := p.consumeType("IDENTIFIER", "Expected function name")
 	node.Children = append(node.Children, name)
@@ -1171,7 +1644,10 @@ func (p *Parser) parseFunctionCall() (*AbstractSyntaxTree, error) {
 		node.Children = append(node.Children, params)
 	}
 
-	rp, _ := p.consume("RPARENTHESIS", ")", "Expected ')'")
+	rp, err := p.consume("RPARENTHESIS", ")", "Expected ')'")
+	if err != nil {
+		return nil, err
+	}
 	node.Children = append(node.Children, rp)
 
 	return node, nil
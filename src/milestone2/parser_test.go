@@ -0,0 +1,62 @@
+package milestone2
+
+import "testing"
+
+// Token ERROR sudah dilaporkan lexer, jadi parser tidak boleh mencatat error kedua di posisi yang sama
+func TestErrorTokenNotReportedTwice(t *testing.T) {
+	tokens := ParseTokenStrings([]string{
+		"KEYWORD(program) @1:1", "IDENTIFIER(p) @1:9", "SEMICOLON(;) @1:10",
+		"KEYWORD(mulai) @2:1",
+		"IDENTIFIER(x) @3:3", "ASSIGN_OPERATOR(:=) @3:5", "NUMBER(1) @3:8", "ERROR(@) @3:10", "NUMBER(2) @3:12",
+		"KEYWORD(selesai) @4:1", "DOT(.) @4:8",
+	})
+	parser := NewParser(tokens)
+	root, err := parser.ParseProgram()
+	if err != ErrInvalidTokens {
+		t.Fatalf("ParseProgram error = %v, want ErrInvalidTokens", err)
+	}
+	if len(parser.Errors()) != 0 {
+		t.Errorf("parser reported %v, want no syntax errors", parser.Errors())
+	}
+	if root == nil {
+		t.Fatal("no partial tree returned")
+	}
+}
+
+// Cabang kasus boleh kosong: "'a': ;" menghasilkan <empty-statement>
+func TestEmptyCaseArm(t *testing.T) {
+	tokens := ParseTokenStrings([]string{
+		"KEYWORD(program) @1:1", "IDENTIFIER(p) @1:9", "SEMICOLON(;) @1:10",
+		"KEYWORD(mulai) @2:1",
+		"KEYWORD(kasus) @3:3", "IDENTIFIER(c) @3:9", "KEYWORD(dari) @3:11",
+		"CHAR_LITERAL('a') @4:5", "COLON(:) @4:8", "SEMICOLON(;) @4:10",
+		"CHAR_LITERAL('b') @5:5", "COLON(:) @5:8", "IDENTIFIER(x) @5:10", "ASSIGN_OPERATOR(:=) @5:12", "NUMBER(1) @5:15",
+		"KEYWORD(selesai) @6:3",
+		"KEYWORD(selesai) @7:1", "DOT(.) @7:8",
+	})
+	parser := NewParser(tokens)
+	root, err := parser.ParseProgram()
+	if err != nil {
+		t.Fatalf("ParseProgram error = %v, errors %v", err, parser.Errors())
+	}
+	var arms []*AbstractSyntaxTree
+	var find func(node *AbstractSyntaxTree)
+	find = func(node *AbstractSyntaxTree) {
+		if node.Kind == NodeCaseElement {
+			arms = append(arms, node)
+		}
+		for _, child := range node.Children {
+			find(child)
+		}
+	}
+	find(root)
+	if len(arms) != 2 {
+		t.Fatalf("found %d case elements, want 2", len(arms))
+	}
+	if got := arms[0].Children[2].Kind; got != NodeEmptyStatement {
+		t.Errorf("statement of 'a' = %v, want %v", got, NodeEmptyStatement)
+	}
+	if got := arms[1].Children[2].Kind; got != NodeAssignmentStatement {
+		t.Errorf("statement of 'b' = %v, want %v", got, NodeAssignmentStatement)
+	}
+}
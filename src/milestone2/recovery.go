@@ -0,0 +1,103 @@
+package milestone2
+
+import (
+	"compiler/diagnostic"
+	"compiler/milestone1"
+)
+
+// Panic-mode error recovery:
+// kalau sebuah konstruksi gagal diparse, error dicatat, token dilompati sampai titik sinkronisasi,
+// dan token yang dilompati disimpan di node <error> supaya parsing bisa lanjut.
+
+// Titik sinkronisasi untuk deklarasi (keyword ditulis dengan ejaan kanonik, sama dengan Token.Value)
+var declarationSync = []string{
+	string(milestone1.KwConst), string(milestone1.KwType), string(milestone1.KwVar),
+	string(milestone1.KwProcedure), string(milestone1.KwFunction), string(milestone1.KwBegin),
+}
+
+// Titik sinkronisasi untuk statement
+var statementSync = []string{";", string(milestone1.KwEnd), string(milestone1.KwUntil)}
+
+// Errors semua error sintaks yang tercatat selama parsing
+func (p *Parser) Errors() []diagnostic.Diagnostic {
+	return p.errors
+}
+
+// Catat error sintaks (error kedua di token yang sama dianggap lanjutan dan tidak dicatat)
+// Error tepat di token ERROR tidak dicatat lagi karena lexer sudah melaporkannya; parsing tetap gagal
+func (p *Parser) report(err error) {
+	d, ok := err.(*diagnostic.Diagnostic)
+	if !ok {
+		d = diagnostic.Errorf(diagnostic.UnexpectedToken, p.peek().Span(), "%v", err)
+	}
+	if t := p.peek(); t.Type == string(milestone1.KindError) && d.Span.Start == t.Span().Start {
+		p.invalid = true
+		return
+	}
+	if n := len(p.errors); n > 0 && p.errors[n-1].Span.Start == d.Span.Start {
+		return
+	}
+	p.errors = append(p.errors, *d)
+}
+
+// Catat error lalu lompati token sampai salah satu syncValues (atau EOF)
+// Blok mulai/rekaman/kasus..selesai dan ulangi..sampai yang terlewati dilompati utuh
+func (p *Parser) synchronize(err error, syncValues ...string) *AbstractSyntaxTree {
+	p.report(err)
+	node := p.newNode(NodeError)
+
+	depth := 0
+	for !p.isAtEnd() {
+		t := p.peek()
+		// Identifier tidak pernah jadi titik sinkronisasi, meski ejaannya sama dengan keyword kanonik
+		if depth == 0 && t.Type != "IDENTIFIER" && containsValue(syncValues, t.Value) {
+			break
+		}
+		switch t.Keyword {
+		case milestone1.KwBegin, milestone1.KwRecord, milestone1.KwCase, milestone1.KwRepeat:
+			depth++
+		case milestone1.KwEnd, milestone1.KwUntil:
+			if depth > 0 {
+				depth--
+			}
+		}
+		node.Children = append(node.Children, newLeaf(p.advance()))
+	}
+	return node
+}
+
+// Apakah token sekarang bisa jadi awal sebuah statement
+func (p *Parser) startsStatement() bool {
+	return p.checkType("IDENTIFIER") ||
+		p.checkKeyword(milestone1.KwIf) ||
+		p.checkKeyword(milestone1.KwWhile) ||
+		p.checkKeyword(milestone1.KwFor) ||
+		p.checkKeyword(milestone1.KwRepeat) ||
+		p.checkKeyword(milestone1.KwCase) ||
+		p.checkKeyword(milestone1.KwBegin)
+}
+
+// Apakah token sekarang menutup statement list
+func (p *Parser) endsStatementList() bool {
+	return p.isAtEnd() || p.checkKeyword(milestone1.KwEnd) || p.checkKeyword(milestone1.KwUntil)
+}
+
+func containsValue(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Buang node <error> (dipakai visitor yang membaca anak berdasarkan posisi)
+func WithoutErrorNodes(children []*AbstractSyntaxTree) []*AbstractSyntaxTree {
+	result := make([]*AbstractSyntaxTree, 0, len(children))
+	for _, child := range children {
+		if child.Kind != NodeError {
+			result = append(result, child)
+		}
+	}
+	return result
+}
@@ -0,0 +1,68 @@
+package milestone2
+
+import (
+	"compiler/diagnostic"
+	"compiler/dot"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// Ekspor parse tree ke Graphviz DOT (diagram di slide) dan JSON (tools penilaian)
+// Urutan node mengikuti urutan anak di tree, jadi output selalu sama untuk input yang sama
+
+// Node parse tree dalam JSON
+type jsonTreeNode struct {
+	Kind     string           `json:"kind"`            // Nama non-terminal tanpa <>, "token" untuk daun
+	Token    string           `json:"token,omitempty"` // Jenis token (daun)
+	Text     string           `json:"text,omitempty"`  // Teks token, keyword dengan ejaan kanonik
+	Span     *diagnostic.Span `json:"span,omitempty"`
+	Children []*jsonTreeNode  `json:"children,omitempty"`
+}
+
+// WriteTreeJSON tulis parse tree sebagai JSON
+func WriteTreeJSON(w io.Writer, root *AbstractSyntaxTree) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(toJSONTree(root))
+}
+
+func toJSONTree(node *AbstractSyntaxTree) *jsonTreeNode {
+	result := &jsonTreeNode{Kind: strings.Trim(node.Kind.String(), "<>")}
+	if node.Kind == NodeToken {
+		result.Token = string(node.Token)
+		result.Text = node.Text
+	}
+	if node.Line > 0 {
+		span := node.Span()
+		result.Span = &span
+	}
+	for _, child := range node.Children {
+		result.Children = append(result.Children, toJSONTree(child))
+	}
+	return result
+}
+
+// WriteTreeDOT tulis parse tree sebagai graf DOT (non-terminal kotak, token elips)
+func WriteTreeDOT(w io.Writer, root *AbstractSyntaxTree) error {
+	graph := dot.NewGraph("ParseTree")
+	graph.Attr(`node [shape=box, fontname="Courier"]`)
+	addTreeNode(graph, root)
+	return graph.Write(w)
+}
+
+func addTreeNode(graph *dot.Graph, node *AbstractSyntaxTree) string {
+	var id string
+	switch node.Kind {
+	case NodeToken:
+		id = graph.Node(node.Value, "shape=ellipse")
+	case NodeError:
+		id = graph.Node(node.Value, "color=red")
+	default:
+		id = graph.Node(node.Value)
+	}
+	for _, child := range node.Children {
+		graph.Edge(id, addTreeNode(graph, child), "")
+	}
+	return id
+}
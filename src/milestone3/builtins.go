@@ -0,0 +1,142 @@
+package milestone3
+
+import (
+	"compiler/diagnostic"
+	"compiler/milestone2"
+)
+
+// Type checking of the predeclared standard functions and procedures (see initStandardIdentifiers).
+
+// Check a call of a standard function and set its result type and function number
+// abs/sqr select their real variant (number + 1) for a real argument
+func (sa *SemanticAnalyzer) checkStdFunction(call *ProcCallNode, entry *TabEntry) {
+	call.IsBuiltIn = true
+	call.Func = StdFunc(entry.Adr)
+	call.Type = entry.Type
+	if call.Type == TypeNone {
+		// abs, sqr, succ, pred: integer unless the argument says otherwise (avoids cascading errors)
+		call.Type = TypeInteger
+	}
+
+	if call.Func == StdEof || call.Func == StdEoln {
+		if len(call.Arguments) != 0 {
+			sa.addError(diagnostic.ArgumentCount, "'%s' takes no arguments but got %d", call.Name, len(call.Arguments))
+		}
+		return
+	}
+
+	if len(call.Arguments) != 1 {
+		sa.addError(diagnostic.ArgumentCount, "'%s' expects 1 argument but got %d", call.Name, len(call.Arguments))
+		return
+	}
+	arg := call.Arguments[0]
+	argType := sa.getNodeType(arg)
+	if argType == TypeNone {
+		return // Already reported
+	}
+
+	switch call.Func {
+	case StdAbs, StdSqr:
+		switch argType {
+		case TypeInteger:
+		case TypeReal:
+			call.Func++
+			call.Type = TypeReal
+		default:
+			sa.stdArgumentMismatch(call, "integer or real", argType)
+		}
+
+	case StdOdd:
+		if argType != TypeInteger {
+			sa.stdArgumentMismatch(call, "integer", argType)
+		}
+
+	case StdChr:
+		if argType != TypeInteger {
+			sa.stdArgumentMismatch(call, "integer", argType)
+		} else {
+			sa.checkConstInRange(arg, 0, 255, "Character code")
+		}
+
+	case StdOrd:
+		if !isOrdinalType(argType) {
+			sa.stdArgumentMismatch(call, "an ordinal type", argType)
+		}
+
+	case StdSucc, StdPred:
+		// Result has the type of the argument (same enumeration for enum values)
+		if !isOrdinalType(argType) {
+			sa.stdArgumentMismatch(call, "an ordinal type", argType)
+			return
+		}
+		call.Type = argType
+		call.Ref = arg.GetRef()
+
+	case StdRound, StdTrunc:
+		if !sa.typesCompatible(TypeReal, argType) {
+			sa.stdArgumentMismatch(call, "real", argType)
+		}
+
+	default:
+		// sin, cos, exp, ln, sqrt: integer arguments are converted to real
+		if !sa.typesCompatible(TypeReal, argType) {
+			sa.stdArgumentMismatch(call, "integer or real", argType)
+		}
+	}
+}
+
+// Check a call of write/writeln (printable values) or read/readln (variables of readable types)
+// Arguments of read/readln are passed by reference
+func (sa *SemanticAnalyzer) checkStdProcedure(call *ProcCallNode) {
+	call.IsBuiltIn = true
+	call.ByRef = make([]bool, len(call.Arguments))
+	isRead := call.Name == "read" || call.Name == "readln"
+
+	for i, arg := range call.Arguments {
+		argType := sa.getNodeType(arg)
+		if isRead {
+			call.ByRef[i] = true
+			if !sa.isLValue(arg) {
+				sa.addError(diagnostic.ArgumentMismatch, "Argument %d of '%s' must be a variable", i+1, call.Name)
+				continue
+			}
+			switch argType {
+			case TypeInteger, TypeReal, TypeChar, TypeString, TypeNone:
+			default:
+				sa.addError(diagnostic.ArgumentMismatch, "Argument %d of '%s': cannot read a value of type %s", i+1, call.Name, argType)
+			}
+			continue
+		}
+
+		switch argType {
+		case TypeInteger, TypeReal, TypeChar, TypeBoolean, TypeString, TypeEnum, TypeNone:
+		default:
+			sa.addError(diagnostic.ArgumentMismatch, "Argument %d of '%s': cannot write a value of type %s", i+1, call.Name, argType)
+		}
+	}
+}
+
+// Remember the write/writeln arguments of an enumeration type; they are written as their ordinal
+func (sa *SemanticAnalyzer) recordEnumWrites(call *ProcCallNode, expressions []*milestone2.AbstractSyntaxTree) {
+	if call.Name != "write" && call.Name != "writeln" {
+		return
+	}
+	for i, arg := range call.Arguments {
+		if i < len(expressions) && sa.getNodeType(arg) == TypeEnum {
+			if sa.enumWrites == nil {
+				sa.enumWrites = make(map[*milestone2.AbstractSyntaxTree]bool)
+			}
+			sa.enumWrites[expressions[i]] = true
+		}
+	}
+}
+
+// EnumArgument reports whether node (an argument expression in the parse tree) is
+// written by write/writeln as the ordinal of an enumeration value
+func (sa *SemanticAnalyzer) EnumArgument(node *milestone2.AbstractSyntaxTree) bool {
+	return sa.enumWrites[node]
+}
+
+func (sa *SemanticAnalyzer) stdArgumentMismatch(call *ProcCallNode, expected string, got TypeKind) {
+	sa.addError(diagnostic.ArgumentMismatch, "Argument of '%s' must be %s, got %s", call.Name, expected, got)
+}
@@ -0,0 +1,324 @@
+package milestone3
+
+import (
+	"compiler/diagnostic"
+	"compiler/milestone1"
+	"compiler/milestone2"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Compile-time evaluation of constant expressions (konstanta values, array bounds and subranges).
+// Values are int for integer, char and boolean, enumValue for enumeration constants,
+// float64 for real and string for string.
+// Supported: literals, earlier constants, parentheses, unary +/- and + - * / bagi mod.
+
+// Value of an enumeration constant: its ordinal and the RTAB index of its type
+type enumValue struct {
+	Ord int
+	Ref int
+}
+
+// Evaluate a constant expression; ok is false if an error was reported
+func (sa *SemanticAnalyzer) evalConstant(node *milestone2.AbstractSyntaxTree) (value interface{}, typ TypeKind, ok bool) {
+	defer sa.at(node)()
+	defer func() {
+		if ok {
+			sa.recordConstant(node, value, typ)
+		}
+	}()
+	children := milestone2.WithoutErrorNodes(node.Children)
+	if len(children) == 0 {
+		sa.addError(diagnostic.NotConstant, "Missing constant value")
+		return nil, TypeNone, false
+	}
+
+	switch node.Kind {
+	case milestone2.NodeExpression:
+		if len(children) > 1 {
+			return sa.notConstant(children[1], "relational operator '%s'", children[1].Text)
+		}
+		return sa.evalConstant(children[0])
+
+	case milestone2.NodeSimpleExpression:
+		sign := ""
+		if isOperatorLeaf(children[0]) {
+			sign = children[0].Text
+			children = children[1:]
+		}
+		value, typ, ok = sa.evalConstant(children[0])
+		if !ok {
+			return nil, TypeNone, false
+		}
+		if sign != "" {
+			if typ != TypeInteger && typ != TypeReal {
+				sa.addError(diagnostic.InvalidOperand, "Unary '%s' requires a numeric constant, got %s", sign, typ)
+				return nil, TypeNone, false
+			}
+			if sign == "-" {
+				value = negateConst(value)
+			}
+		}
+		return sa.evalConstBinary(value, typ, children[1:])
+
+	case milestone2.NodeTerm:
+		value, typ, ok = sa.evalConstant(children[0])
+		if !ok {
+			return nil, TypeNone, false
+		}
+		return sa.evalConstBinary(value, typ, children[1:])
+
+	case milestone2.NodeFactor:
+		return sa.evalConstFactor(children)
+
+	case milestone2.NodeVariable:
+		if len(children) > 1 {
+			return sa.notConstant(node, "selector on '%s'", children[0].Text)
+		}
+		return sa.evalConstIdentifier(children[0])
+	}
+
+	return sa.notConstant(node, "'%s'", node.Value)
+}
+
+// <factor>: literal, constant name, ( expression ); everything else is not constant
+func (sa *SemanticAnalyzer) evalConstFactor(children []*milestone2.AbstractSyntaxTree) (interface{}, TypeKind, bool) {
+	first := children[0]
+	switch {
+	case first.Kind == milestone2.NodeVariable:
+		return sa.evalConstant(first)
+	case first.Kind == milestone2.NodeFunctionCall:
+		return sa.notConstant(first, "function call")
+	case first.IsToken(milestone1.KindLParenthesis) && len(children) >= 2:
+		return sa.evalConstant(children[1])
+	case first.IsKeyword(milestone1.KwNot):
+		return sa.notConstant(first, "operator 'tidak'")
+	}
+
+	value, typ := sa.extractConstValue(first)
+	if typ == TypeNone {
+		return sa.notConstant(first, "'%s'", first.Text)
+	}
+	return value, typ, true
+}
+
+// Reference to an earlier konstanta entry
+func (sa *SemanticAnalyzer) evalConstIdentifier(node *milestone2.AbstractSyntaxTree) (interface{}, TypeKind, bool) {
+	defer sa.at(node)()
+	name := node.Text
+	tabIndex, found := sa.SymTable.Lookup(name)
+	if !found {
+		sa.addError(diagnostic.UndeclaredIdent, "Undefined constant '%s'", name)
+		return nil, TypeNone, false
+	}
+
+	entry := sa.SymTable.Tab[tabIndex]
+	if entry.Obj != ObjConstant {
+		sa.addError(diagnostic.NotConstant, "'%s' is not a constant", name).
+			WithNote(nil, "'%s' is declared as a %s", name, entry.Obj)
+		return nil, TypeNone, false
+	}
+
+	switch entry.Type {
+	case TypeReal:
+		value, err := sa.SymTable.GetReal(entry.Adr)
+		return value, TypeReal, err == nil
+	case TypeString:
+		value, err := sa.SymTable.GetString(entry.Adr)
+		return value, TypeString, err == nil
+	case TypeEnum:
+		return enumValue{Ord: entry.Adr, Ref: entry.Ref}, TypeEnum, true
+	default:
+		return entry.Adr, entry.Type, true
+	}
+}
+
+// Apply the (operator operand)* tail of a simple-expression or term from left to right
+func (sa *SemanticAnalyzer) evalConstBinary(value interface{}, typ TypeKind, rest []*milestone2.AbstractSyntaxTree) (interface{}, TypeKind, bool) {
+	for i := 0; i+1 < len(rest); i += 2 {
+		opNode := rest[i]
+		op := opNode.Text
+
+		right, rightType, ok := sa.evalConstant(rest[i+1])
+		if !ok {
+			return nil, TypeNone, false
+		}
+
+		restore := sa.at(opNode)
+		value, typ, ok = sa.applyConstOperator(op, value, typ, right, rightType)
+		restore()
+		if !ok {
+			return nil, TypeNone, false
+		}
+	}
+	return value, typ, true
+}
+
+// Evaluate a constant that must be ordinal (array bound, subrange bound)
+// ref is the RTAB index of the enumeration for enum constants, -1 otherwise
+func (sa *SemanticAnalyzer) evalOrdinalConstant(node *milestone2.AbstractSyntaxTree) (ord int, typ TypeKind, ref int, ok bool) {
+	value, typ, ok := sa.evalConstant(node)
+	if !ok {
+		return 0, TypeNone, -1, false
+	}
+	switch v := value.(type) {
+	case int:
+		if isOrdinalType(typ) {
+			return v, typ, -1, true
+		}
+	case enumValue:
+		return v.Ord, TypeEnum, v.Ref, true
+	}
+	restore := sa.at(node)
+	sa.addError(diagnostic.InvalidRange, "Bound must be an ordinal constant, got %s %s", typ, formatConst(value, typ))
+	restore()
+	return 0, TypeNone, -1, false
+}
+
+// Fold one binary operator
+func (sa *SemanticAnalyzer) applyConstOperator(op string, left interface{}, leftType TypeKind, right interface{}, rightType TypeKind) (interface{}, TypeKind, bool) {
+	switch op {
+	case "+", "-", "*", "/":
+		if !sa.isConstNumeric(leftType) || !sa.isConstNumeric(rightType) {
+			sa.addError(diagnostic.InvalidOperand, "Operator '%s' requires numeric constants, got %s and %s", op, leftType, rightType)
+			return nil, TypeNone, false
+		}
+		if op != "/" && leftType == TypeInteger && rightType == TypeInteger {
+			l, r := left.(int), right.(int)
+			switch op {
+			case "+":
+				return l + r, TypeInteger, true
+			case "-":
+				return l - r, TypeInteger, true
+			default:
+				return l * r, TypeInteger, true
+			}
+		}
+		l, r := constToReal(left), constToReal(right)
+		switch op {
+		case "+":
+			return l + r, TypeReal, true
+		case "-":
+			return l - r, TypeReal, true
+		case "*":
+			return l * r, TypeReal, true
+		default:
+			if r == 0 {
+				sa.addError(diagnostic.InvalidOperand, "Division by zero in constant expression")
+				return nil, TypeNone, false
+			}
+			return l / r, TypeReal, true
+		}
+
+	case "bagi", "mod":
+		if leftType != TypeInteger || rightType != TypeInteger {
+			sa.addError(diagnostic.InvalidOperand, "Operator '%s' requires integer constants, got %s and %s", op, leftType, rightType)
+			return nil, TypeNone, false
+		}
+		l, r := left.(int), right.(int)
+		if r == 0 {
+			sa.addError(diagnostic.InvalidOperand, "Division by zero in constant expression")
+			return nil, TypeNone, false
+		}
+		if op == "bagi" {
+			return l / r, TypeInteger, true
+		}
+		return l % r, TypeInteger, true
+	}
+
+	sa.addError(diagnostic.NotConstant, "Operator '%s' is not allowed in a constant expression", op)
+	return nil, TypeNone, false
+}
+
+// Report a construct that cannot be evaluated at compile time
+func (sa *SemanticAnalyzer) notConstant(node *milestone2.AbstractSyntaxTree, format string, args ...interface{}) (interface{}, TypeKind, bool) {
+	defer sa.at(node)()
+	sa.addError(diagnostic.NotConstant, "Value is not constant: "+format+" cannot be evaluated at compile time", args...)
+	return nil, TypeNone, false
+}
+
+func (sa *SemanticAnalyzer) isConstNumeric(typ TypeKind) bool {
+	return typ == TypeInteger || typ == TypeReal
+}
+
+func isOperatorLeaf(node *milestone2.AbstractSyntaxTree) bool {
+	return node.IsToken(milestone1.KindArithmeticOperator)
+}
+
+func negateConst(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return -v
+	case float64:
+		return -v
+	}
+	return value
+}
+
+func constToReal(value interface{}) float64 {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
+
+// Text form of a constant value (used in error messages)
+func formatConst(value interface{}, typ TypeKind) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case string:
+		return "'" + v + "'"
+	case int:
+		return formatOrdinal(v, typ)
+	case enumValue:
+		return strconv.Itoa(v.Ord)
+	}
+	return "?"
+}
+
+// Remember the folded value of a constant expression so backends can print it as a literal
+func (sa *SemanticAnalyzer) recordConstant(node *milestone2.AbstractSyntaxTree, value interface{}, typ TypeKind) {
+	literal, ok := constLiteral(value, typ)
+	if !ok {
+		return
+	}
+	if sa.constants == nil {
+		sa.constants = make(map[*milestone2.AbstractSyntaxTree]string)
+	}
+	sa.constants[node] = literal
+}
+
+// ConstantValue returns the folded value of a constant expression in the parse tree
+// (konstanta values, array bounds, subrange bounds) as Pascal source text
+func (sa *SemanticAnalyzer) ConstantValue(node *milestone2.AbstractSyntaxTree) (string, bool) {
+	literal, ok := sa.constants[node]
+	return literal, ok
+}
+
+// Pascal literal for a constant value; enumeration values have no literal form
+func constLiteral(value interface{}, typ TypeKind) (string, bool) {
+	switch v := value.(type) {
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return "", false
+		}
+		literal := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(literal, ".e") {
+			literal += ".0"
+		}
+		return literal, true
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'", true
+	case int:
+		if typ == TypeChar && v == '\'' {
+			return "''''", true
+		}
+		return formatOrdinal(v, typ), true
+	}
+	return "", false
+}
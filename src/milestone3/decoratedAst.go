@@ -1,11 +1,16 @@
 package milestone3
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"strings"
+)
 
 // DecoratedNode interface - base interface for all decorated AST nodes
 type DecoratedNode interface {
 	GetTabIndex() int
 	GetType() TypeKind
+	GetRef() int
 	GetLevel() int
 	Accept(visitor DecoratedNodeVisitor)
 }
@@ -26,6 +31,8 @@ type DecoratedNodeVisitor interface {
 	VisitProcCall(*ProcCallNode)
 	VisitIf(*IfNode)
 	VisitWhile(*WhileNode)
+	VisitRepeat(*RepeatNode)
+	VisitCase(*CaseNode)
 	VisitFor(*ForNode)
 }
 
@@ -48,6 +55,10 @@ func (n *BaseDecoratedNode) GetType() TypeKind {
 	return n.Type
 }
 
+func (n *BaseDecoratedNode) GetRef() int {
+	return n.Ref
+}
+
 func (n *BaseDecoratedNode) GetLevel() int {
 	return n.Level
 }
@@ -162,11 +173,12 @@ type TypeDeclNode struct {
 // SubprogramDeclNode - procedure or function declaration
 type SubprogramDeclNode struct {
 	BaseDecoratedNode
-	Name       string
-	Parameters []DecoratedNode
-	ReturnType TypeKind
-	Body       DecoratedNode
-	IsFunction bool
+	Name         string
+	Parameters   []DecoratedNode
+	ReturnType   TypeKind
+	Declarations DecoratedNode // Local declarations (including nested subprograms)
+	Body         DecoratedNode
+	IsFunction   bool
 }
 
 func NewSubprogramDeclNode(name string, parameters []DecoratedNode, returnType TypeKind, body DecoratedNode, isFunction bool) *SubprogramDeclNode {
@@ -298,7 +310,38 @@ type VarNode struct {
 	Name      string
 	IsLValue  bool
 	IsIndexed bool
-	Index     DecoratedNode
+	Selectors []Selector // Access chain applied to the variable, in source order
+}
+
+// Selector - one step of a variable access chain: [index] or .field
+type Selector struct {
+	Index  DecoratedNode // Index expression for [index], nil for .field
+	Field  string        // Field name for .field
+	Offset int           // Field offset inside the record
+	Ref    int           // ATAB index of the indexed array, BTAB index of the record
+	Type   TypeKind      // Type of the component selected by this step
+}
+
+// StaticOffset - sum of the field offsets before the first index (known at compile time)
+func (n *VarNode) StaticOffset() int {
+	offset := 0
+	for _, sel := range n.Selectors {
+		if sel.Index != nil {
+			break
+		}
+		offset += sel.Offset
+	}
+	return offset
+}
+
+// DynamicSelectors - selectors from the first index on, which must be applied at run time
+func (n *VarNode) DynamicSelectors() []Selector {
+	for i, sel := range n.Selectors {
+		if sel.Index != nil {
+			return n.Selectors[i:]
+		}
+	}
+	return nil
 }
 
 func NewVarNode(name string) *VarNode {
@@ -376,7 +419,7 @@ func NewStringNode(value string) *StringNode {
 	return &StringNode{
 		BaseDecoratedNode: BaseDecoratedNode{
 			TabIndex: -1,
-			Type:     TypeChar,
+			Type:     TypeString,
 			Ref:      -1,
 			Errors:   make([]string, 0),
 			Warnings: make([]string, 0),
@@ -440,7 +483,9 @@ type ProcCallNode struct {
 	BaseDecoratedNode
 	Name      string
 	Arguments []DecoratedNode
+	ByRef     []bool // ByRef[i]: argument i is bound to a var parameter (passed by address)
 	IsBuiltIn bool
+	Func      StdFunc // Standard function number (built-in functions only)
 }
 
 func NewProcCallNode(name string, arguments []DecoratedNode) *ProcCallNode {
@@ -462,6 +507,19 @@ func (n *ProcCallNode) Accept(visitor DecoratedNodeVisitor) {
 	visitor.VisitProcCall(n)
 }
 
+// IsByRef reports whether argument i is passed by reference
+func (n *ProcCallNode) IsByRef(i int) bool {
+	return i < len(n.ByRef) && n.ByRef[i]
+}
+
+// Inline form of argument i ("var x" for by-reference arguments)
+func (n *ProcCallNode) formatArgument(i int) string {
+	if n.IsByRef(i) {
+		return "var " + formatNodeInline(n.Arguments[i])
+	}
+	return formatNodeInline(n.Arguments[i])
+}
+
 // IfNode - if statement
 type IfNode struct {
 	BaseDecoratedNode
@@ -485,6 +543,35 @@ func (n *WhileNode) Accept(visitor DecoratedNodeVisitor) {
 	visitor.VisitWhile(n)
 }
 
+// RepeatNode - repeat-until loop (body runs at least once)
+type RepeatNode struct {
+	BaseDecoratedNode
+	Body      DecoratedNode // *BlockNode with the statement sequence
+	Condition DecoratedNode
+}
+
+func (n *RepeatNode) Accept(visitor DecoratedNodeVisitor) {
+	visitor.VisitRepeat(n)
+}
+
+// CaseArm - one arm of a case statement: ordinal label values and the statement they select
+type CaseArm struct {
+	Labels []int
+	Body   DecoratedNode
+}
+
+// CaseNode - case statement
+type CaseNode struct {
+	BaseDecoratedNode
+	Selector DecoratedNode
+	Arms     []*CaseArm
+	Else     DecoratedNode // *BlockNode for selain_itu, nil if absent
+}
+
+func (n *CaseNode) Accept(visitor DecoratedNodeVisitor) {
+	visitor.VisitCase(n)
+}
+
 // ForNode - for loop
 type ForNode struct {
 	BaseDecoratedNode
@@ -501,8 +588,8 @@ func (n *ForNode) Accept(visitor DecoratedNodeVisitor) {
 
 // ========== PRINT FUNCTION ==========
 
-// PrintDecoratedAST prints the decorated AST in a tree format with visual connectors
-func PrintDecoratedAST(node DecoratedNode, prefix string, isLast bool) {
+// PrintDecoratedAST writes the decorated AST to w in a tree format with visual connectors
+func PrintDecoratedAST(w io.Writer, node DecoratedNode, prefix string, isLast bool) {
 	if node == nil {
 		return
 	}
@@ -518,7 +605,7 @@ func PrintDecoratedAST(node DecoratedNode, prefix string, isLast bool) {
 
 	switch n := node.(type) {
 	case *ProgramNode:
-		fmt.Printf("%s%sProgramNode(name: '%s')\n", prefix, connector, n.Name)
+		fmt.Fprintf(w, "%s%sProgramNode(name: '%s')\n", prefix, connector, n.Name)
 
 		newPrefix := prefix
 		if prefix != "" {
@@ -533,31 +620,31 @@ func PrintDecoratedAST(node DecoratedNode, prefix string, isLast bool) {
 		hasBlock := n.Block != nil
 
 		if n.Declarations != nil {
-			fmt.Printf("%s|\n", newPrefix)
-			fmt.Printf("%s+-- Declarations\n", newPrefix)
+			fmt.Fprintf(w, "%s|\n", newPrefix)
+			fmt.Fprintf(w, "%s+-- Declarations\n", newPrefix)
 			declPrefix := newPrefix + "|   "
-			printDeclarationList(n.Declarations, declPrefix)
+			printDeclarationList(w, n.Declarations, declPrefix)
 		}
 
 		if hasBlock {
-			fmt.Printf("%s|\n", newPrefix)
-			fmt.Printf("%s\\-- Block\n", newPrefix)
+			fmt.Fprintf(w, "%s|\n", newPrefix)
+			fmt.Fprintf(w, "%s\\-- Block\n", newPrefix)
 			blockPrefix := newPrefix + "    "
-			printBlockStatements(n.Block, blockPrefix)
+			printBlockStatements(w, n.Block, blockPrefix)
 		}
 
 	case *VarDeclNode:
-		fmt.Printf("%s%sVarDecl(name: '%s', type: '%s')\n", prefix, connector, n.Name, n.Type)
+		fmt.Fprintf(w, "%s%sVarDecl(name: '%s', type: '%s')\n", prefix, connector, n.Name, n.Type)
 
 	case *ConstDeclNode:
-		fmt.Printf("%s%sConstDecl(name: '%s', value: %v, type: '%s')\n", prefix, connector, n.Name, n.Value, n.Type)
+		fmt.Fprintf(w, "%s%sConstDecl(name: '%s', value: %v, type: '%s')\n", prefix, connector, n.Name, n.Value, n.Type)
 
 	case *SubprogramDeclNode:
 		kind := "Procedure"
 		if n.IsFunction {
 			kind = "Function"
 		}
-		fmt.Printf("%s%s%sDecl(name: '%s', return_type: '%s')\n", prefix, connector, kind, n.Name, n.ReturnType)
+		fmt.Fprintf(w, "%s%s%sDecl(name: '%s', return_type: '%s')\n", prefix, connector, kind, n.Name, n.ReturnType)
 
 	case *AssignNode:
 		// Print assignment with multiline formatting for complex values
@@ -565,113 +652,146 @@ func PrintDecoratedAST(node DecoratedNode, prefix string, isLast bool) {
 
 		// Check if we need multiline format
 		if isBinOp(n.Value) {
-			fmt.Printf("%s%sAssign(target: %s,\n", prefix, connector, targetStr)
+			fmt.Fprintf(w, "%s%sAssign(target: %s,\n", prefix, connector, targetStr)
 			valueStr := formatBinOpMultiline(n.Value, prefix+getSpaces(len(connector))+"       ")
-			fmt.Printf("%s%svalue: %s)\n", prefix, getSpaces(len(connector))+"       ", valueStr)
+			fmt.Fprintf(w, "%s%svalue: %s)\n", prefix, getSpaces(len(connector))+"       ", valueStr)
 		} else {
 			valueStr := formatNodeInline(n.Value)
-			fmt.Printf("%s%sAssign(target: %s, value: %s)\n", prefix, connector, targetStr, valueStr)
+			fmt.Fprintf(w, "%s%sAssign(target: %s, value: %s)\n", prefix, connector, targetStr, valueStr)
 		}
 
 	case *BinOpNode:
-		fmt.Printf("%s%sBinOp(op: '%s', left: %s, right: %s)\n",
+		fmt.Fprintf(w, "%s%sBinOp(op: '%s', left: %s, right: %s)\n",
 			prefix, connector, n.Operator, formatNodeInline(n.Left), formatNodeInline(n.Right))
 
 	case *UnaryOpNode:
-		fmt.Printf("%s%sUnaryOp(op: '%s', operand: %s)\n", prefix, connector, n.Operator, formatNodeInline(n.Operand))
+		fmt.Fprintf(w, "%s%sUnaryOp(op: '%s', operand: %s)\n", prefix, connector, n.Operator, formatNodeInline(n.Operand))
 
 	case *VarNode:
-		fmt.Printf("%s%sVar('%s')\n", prefix, connector, n.Name)
+		fmt.Fprintf(w, "%s%sVar('%s')\n", prefix, connector, n.Name)
 
 	case *NumberNode:
-		fmt.Printf("%s%sNum(%d)\n", prefix, connector, n.Value)
+		fmt.Fprintf(w, "%s%sNum(%d)\n", prefix, connector, n.Value)
 
 	case *StringNode:
-		fmt.Printf("%s%sString('%s')\n", prefix, connector, n.Value)
+		fmt.Fprintf(w, "%s%sString('%s')\n", prefix, connector, n.Value)
 
 	case *CharNode:
-		fmt.Printf("%s%sChar('%c')\n", prefix, connector, n.Value)
+		fmt.Fprintf(w, "%s%sChar('%c')\n", prefix, connector, n.Value)
 
 	case *BooleanNode:
-		fmt.Printf("%s%sBool(%v)\n", prefix, connector, n.Value)
+		fmt.Fprintf(w, "%s%sBool(%v)\n", prefix, connector, n.Value)
 
 	case *ProcCallNode:
 		// Format arguments inline
 		args := make([]string, len(n.Arguments))
-		for i, arg := range n.Arguments {
-			args[i] = formatNodeInline(arg)
+		for i := range n.Arguments {
+			args[i] = n.formatArgument(i)
 		}
 
 		if len(args) == 0 {
-			fmt.Printf("%s%sProcedureCall(name: '%s', args: [])\n", prefix, connector, n.Name)
+			fmt.Fprintf(w, "%s%sProcedureCall(name: '%s', args: [])\n", prefix, connector, n.Name)
 		} else if len(args) == 1 {
-			fmt.Printf("%s%sProcedureCall(name: '%s',\n", prefix, connector, n.Name)
-			fmt.Printf("%s%sargs: [%s])\n", prefix, getSpaces(len(connector))+"              ", args[0])
+			fmt.Fprintf(w, "%s%sProcedureCall(name: '%s',\n", prefix, connector, n.Name)
+			fmt.Fprintf(w, "%s%sargs: [%s])\n", prefix, getSpaces(len(connector))+"              ", args[0])
 		} else {
 			// Multiline for multiple args
-			fmt.Printf("%s%sProcedureCall(name: '%s',\n", prefix, connector, n.Name)
+			fmt.Fprintf(w, "%s%sProcedureCall(name: '%s',\n", prefix, connector, n.Name)
 			argPrefix := prefix + getSpaces(len(connector)) + "              args: ["
 			for i, arg := range args {
 				if i == 0 {
-					fmt.Printf("%s%s", argPrefix, arg)
+					fmt.Fprintf(w, "%s%s", argPrefix, arg)
 				} else {
-					fmt.Printf(", %s", arg)
+					fmt.Fprintf(w, ", %s", arg)
 				}
 			}
-			fmt.Printf("])\n")
+			fmt.Fprintf(w, "])\n")
 		}
 
 	case *IfNode:
-		fmt.Printf("%s%sIf(condition: %s)\n", prefix, connector, formatNodeInline(n.Condition))
+		fmt.Fprintf(w, "%s%sIf(condition: %s)\n", prefix, connector, formatNodeInline(n.Condition))
 
 	case *WhileNode:
-		fmt.Printf("%s%sWhile(condition: %s)\n", prefix, connector, formatNodeInline(n.Condition))
+		fmt.Fprintf(w, "%s%sWhile(condition: %s)\n", prefix, connector, formatNodeInline(n.Condition))
+
+	case *RepeatNode:
+		fmt.Fprintf(w, "%s%sRepeat(until: %s)\n", prefix, connector, formatNodeInline(n.Condition))
+
+	case *CaseNode:
+		selectorType := TypeNone
+		if n.Selector != nil {
+			selectorType = n.Selector.GetType()
+		}
+		arms := make([]string, len(n.Arms))
+		for i, arm := range n.Arms {
+			labels := make([]string, len(arm.Labels))
+			for j, label := range arm.Labels {
+				labels[j] = formatOrdinal(label, selectorType)
+			}
+			arms[i] = strings.Join(labels, ", ")
+		}
+		if n.Else != nil {
+			arms = append(arms, "selain_itu")
+		}
+		fmt.Fprintf(w, "%s%sCase(selector: %s, arms: [%s])\n", prefix, connector, formatNodeInline(n.Selector), strings.Join(arms, " | "))
 
 	case *ForNode:
 		direction := "to"
 		if n.IsDownTo {
 			direction = "downto"
 		}
-		fmt.Printf("%s%sFor(var: %s, %s, start: %s, end: %s)\n",
+		fmt.Fprintf(w, "%s%sFor(var: %s, %s, start: %s, end: %s)\n",
 			prefix, connector, formatNodeInline(n.Variable), direction,
 			formatNodeInline(n.StartValue), formatNodeInline(n.EndValue))
 	}
 }
 
 // Helper to print declaration list with proper connectors
-func printDeclarationList(node DecoratedNode, prefix string) {
+func printDeclarationList(w io.Writer, node DecoratedNode, prefix string) {
 	if node == nil {
 		return
 	}
 
 	if declList, ok := node.(*DeclarationListNode); ok {
-		fmt.Printf("%s|\n", prefix)
+		fmt.Fprintf(w, "%s|\n", prefix)
 		for i, decl := range declList.Declarations {
 			isLast := i == len(declList.Declarations)-1
-			PrintDecoratedAST(decl, prefix, isLast)
+			PrintDecoratedAST(w, decl, prefix, isLast)
 		}
 	} else {
-		PrintDecoratedAST(node, prefix, false)
+		PrintDecoratedAST(w, node, prefix, false)
 	}
 }
 
 // Helper to print block statements with proper connectors
-func printBlockStatements(node DecoratedNode, prefix string) {
+func printBlockStatements(w io.Writer, node DecoratedNode, prefix string) {
 	if node == nil {
 		return
 	}
 
 	if block, ok := node.(*BlockNode); ok {
-		fmt.Printf("%s|\n", prefix)
+		fmt.Fprintf(w, "%s|\n", prefix)
 		for i, stmt := range block.Statements {
 			isLast := i == len(block.Statements)-1
-			PrintDecoratedAST(stmt, prefix, isLast)
+			PrintDecoratedAST(w, stmt, prefix, isLast)
 			if !isLast {
-				fmt.Printf("%s|\n", prefix)
+				fmt.Fprintf(w, "%s|\n", prefix)
 			}
 		}
 	} else {
-		PrintDecoratedAST(node, prefix, false)
+		PrintDecoratedAST(w, node, prefix, false)
+	}
+}
+
+// Helper to format an ordinal value (case label) according to its type
+func formatOrdinal(value int, typ TypeKind) string {
+	switch typ {
+	case TypeChar:
+		return fmt.Sprintf("'%c'", rune(value))
+	case TypeBoolean:
+		return fmt.Sprintf("%v", value != 0)
+	default:
+		return fmt.Sprintf("%d", value)
 	}
 }
 
@@ -703,8 +823,8 @@ func formatNodeInline(node DecoratedNode) string {
 			return fmt.Sprintf("%s()", n.Name)
 		}
 		args := make([]string, len(n.Arguments))
-		for i, arg := range n.Arguments {
-			args[i] = formatNodeInline(arg)
+		for i := range n.Arguments {
+			args[i] = n.formatArgument(i)
 		}
 		argsStr := ""
 		for i, arg := range args {
@@ -0,0 +1,222 @@
+package milestone3
+
+import (
+	"compiler/dot"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Graphviz DOT and JSON export of the decorated AST. Every node carries its decoration
+// (type, level, symbol table index, address and ATAB/BTAB reference); children are labelled
+// with their role in the parent. Field order and child order are fixed, so output is stable.
+
+type exportedNode struct {
+	Node     string          `json:"node"`
+	Role     string          `json:"role,omitempty"` // Relation to the parent: target, condition, arg, ...
+	Name     string          `json:"name,omitempty"`
+	Operator string          `json:"operator,omitempty"`
+	Value    interface{}     `json:"value,omitempty"`
+	Block    *int            `json:"block,omitempty"` // BTAB index of a block
+	Type     string          `json:"type"`
+	Level    int             `json:"level"`
+	TabIndex int             `json:"tabIndex"`
+	Address  int             `json:"address"`
+	Ref      int             `json:"ref"`
+	Children []*exportedNode `json:"children,omitempty"`
+}
+
+// WriteDecoratedJSON writes the decorated AST rooted at node as JSON
+func WriteDecoratedJSON(w io.Writer, node DecoratedNode) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(exportNode(node, "", 0))
+}
+
+// WriteDecoratedDOT writes the decorated AST rooted at node as a DOT graph
+func WriteDecoratedDOT(w io.Writer, node DecoratedNode) error {
+	graph := dot.NewGraph("DecoratedAST")
+	graph.Attr(`node [shape=box, fontname="Courier"]`)
+	if exported := exportNode(node, "", 0); exported != nil {
+		addExportedNode(graph, exported)
+	}
+	return graph.Write(w)
+}
+
+func addExportedNode(graph *dot.Graph, node *exportedNode) string {
+	id := graph.Node(exportedLabel(node))
+	for _, child := range node.Children {
+		graph.Edge(id, addExportedNode(graph, child), child.Role)
+	}
+	return id
+}
+
+// Node title on the first line, decoration below
+func exportedLabel(node *exportedNode) string {
+	title := node.Node
+	switch {
+	case node.Name != "":
+		title += " '" + node.Name + "'"
+	case node.Operator != "":
+		title += " '" + node.Operator + "'"
+	}
+	if node.Value != nil {
+		title += fmt.Sprintf(" = %v", node.Value)
+	}
+	lines := []string{
+		title,
+		fmt.Sprintf("type: %s  level: %d", node.Type, node.Level),
+		fmt.Sprintf("tab: %d  adr: %d  ref: %d", node.TabIndex, node.Address, node.Ref),
+	}
+	if node.Block != nil {
+		lines = append(lines, fmt.Sprintf("block: %d", *node.Block))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Describe one decorated node and its children. level is the level of the enclosing block:
+// declarations, blocks and variables carry their own level, statements and expressions get this one
+func exportNode(node DecoratedNode, role string, level int) *exportedNode {
+	if node == nil {
+		return nil
+	}
+	result := &exportedNode{
+		Role:     role,
+		Type:     node.GetType().String(),
+		Level:    level,
+		TabIndex: node.GetTabIndex(),
+		Ref:      node.GetRef(),
+	}
+	inner := level // Level of the block the children belong to
+	add := func(child DecoratedNode, role string) {
+		if exported := exportNode(child, role, inner); exported != nil {
+			result.Children = append(result.Children, exported)
+		}
+	}
+
+	switch n := node.(type) {
+	case *ProgramNode:
+		result.Node, result.Name, result.Address = "Program", n.Name, n.Address
+		result.Level, inner = n.Level, n.Level
+		add(n.Declarations, "declaration")
+		add(n.Block, "block")
+	case *DeclarationListNode:
+		result.Node, result.Address = "Declarations", n.Address
+		for _, decl := range n.Declarations {
+			add(decl, "declaration")
+		}
+	case *VarDeclNode:
+		result.Node, result.Name, result.Address, result.Level = "VarDecl", n.Name, n.Address, n.Level
+	case *ConstDeclNode:
+		result.Node, result.Name, result.Value, result.Address, result.Level = "ConstDecl", n.Name, n.Value, n.Address, n.Level
+	case *SubprogramDeclNode:
+		result.Node, result.Name, result.Address = "ProcedureDecl", n.Name, n.Address
+		// Declared at n.Level; parameters, locals and body live one level deeper
+		result.Level, inner = n.Level, n.Level+1
+		if n.IsFunction {
+			result.Node = "FunctionDecl"
+		}
+		for _, param := range n.Parameters {
+			add(param, "parameter")
+		}
+		add(n.Declarations, "declaration")
+		add(n.Body, "body")
+	case *BlockNode:
+		block := n.BlockIndex
+		result.Node, result.Block, result.Address = "Block", &block, n.Address
+		result.Level, inner = n.Level, n.Level
+		for _, stmt := range n.Statements {
+			add(stmt, "statement")
+		}
+	case *AssignNode:
+		result.Node, result.Address = "Assign", n.Address
+		add(n.Target, "target")
+		add(n.Value, "value")
+	case *BinOpNode:
+		result.Node, result.Operator, result.Address = "BinOp", n.Operator, n.Address
+		add(n.Left, "left")
+		add(n.Right, "right")
+	case *UnaryOpNode:
+		result.Node, result.Operator, result.Address = "UnaryOp", n.Operator, n.Address
+		add(n.Operand, "operand")
+	case *VarNode:
+		result.Node, result.Name, result.Address, result.Level = "Var", n.Name, n.Address, n.Level
+		for _, sel := range n.Selectors {
+			if sel.Index != nil {
+				add(sel.Index, "index")
+				continue
+			}
+			result.Children = append(result.Children, &exportedNode{
+				Node: "Field", Role: "field", Name: sel.Field, Type: sel.Type.String(),
+				Level: n.Level, TabIndex: -1, Address: sel.Offset, Ref: sel.Ref,
+			})
+		}
+	case *NumberNode:
+		result.Node, result.Value, result.Address = "Num", n.Value, n.Address
+	case *RealNode:
+		result.Node, result.Value, result.Address = "Real", n.Value, n.Address
+	case *StringNode:
+		result.Node, result.Value, result.Address = "String", n.Value, n.Address
+	case *CharNode:
+		result.Node, result.Value, result.Address = "Char", string(n.Value), n.Address
+	case *BooleanNode:
+		result.Node, result.Value, result.Address = "Bool", n.Value, n.Address
+	case *ProcCallNode:
+		result.Node, result.Name, result.Address = "ProcedureCall", n.Name, n.Address
+		for i, arg := range n.Arguments {
+			if n.IsByRef(i) {
+				add(arg, "var arg")
+			} else {
+				add(arg, "arg")
+			}
+		}
+	case *IfNode:
+		result.Node, result.Address = "If", n.Address
+		add(n.Condition, "condition")
+		add(n.ThenStmt, "then")
+		add(n.ElseStmt, "else")
+	case *WhileNode:
+		result.Node, result.Address = "While", n.Address
+		add(n.Condition, "condition")
+		add(n.Body, "body")
+	case *RepeatNode:
+		result.Node, result.Address = "Repeat", n.Address
+		add(n.Body, "body")
+		add(n.Condition, "until")
+	case *CaseNode:
+		result.Node, result.Address = "Case", n.Address
+		add(n.Selector, "selector")
+		selectorType := TypeNone
+		if n.Selector != nil {
+			selectorType = n.Selector.GetType()
+		}
+		for _, arm := range n.Arms {
+			labels := make([]string, len(arm.Labels))
+			for i, label := range arm.Labels {
+				labels[i] = formatOrdinal(label, selectorType)
+			}
+			armNode := &exportedNode{
+				Node: "CaseArm", Role: "arm", Value: strings.Join(labels, ", "), Type: TypeNone.String(),
+				Level: level, TabIndex: -1, Ref: -1,
+			}
+			if body := exportNode(arm.Body, "body", level); body != nil {
+				armNode.Children = append(armNode.Children, body)
+			}
+			result.Children = append(result.Children, armNode)
+		}
+		add(n.Else, "else")
+	case *ForNode:
+		result.Node, result.Operator, result.Address = "For", "to", n.Address
+		if n.IsDownTo {
+			result.Operator = "downto"
+		}
+		add(n.Variable, "variable")
+		add(n.StartValue, "start")
+		add(n.EndValue, "end")
+		add(n.Body, "body")
+	default:
+		result.Node = fmt.Sprintf("%T", node)
+	}
+	return result
+}
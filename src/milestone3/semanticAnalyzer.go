@@ -1,10 +1,11 @@
 package milestone3
 
 import (
+	"compiler/diagnostic"
+	"compiler/milestone1"
 	"compiler/milestone2"
 	"fmt"
 	"strconv"
-	"strings"
 )
 
 // SemanticAnalyzer performs semantic analysis on parse tree
@@ -12,17 +13,25 @@ import (
 type SemanticAnalyzer struct {
 	SymTable      *SymbolTable
 	CurrentOffset int
-	Errors        []string
-	Warnings      []string
+	Errors        []diagnostic.Diagnostic
+	Warnings      []diagnostic.Diagnostic
+	current       *milestone2.AbstractSyntaxTree            // Parse tree node being analyzed (source of error spans)
+	constants     map[*milestone2.AbstractSyntaxTree]string // Folded constant expressions (see ConstantValue)
+	enumWrites    map[*milestone2.AbstractSyntaxTree]bool   // write/writeln arguments of an enumeration type (see EnumArgument)
 }
 
 // Create new semantic analyzer
 func NewSemanticAnalyzer() *SemanticAnalyzer {
+	return NewSemanticAnalyzerWithDialect(milestone1.Indonesian)
+}
+
+// NewSemanticAnalyzerWithDialect spells the reserved words in the symbol table like the source dialect
+func NewSemanticAnalyzerWithDialect(dialect *milestone1.Dialect) *SemanticAnalyzer {
 	return &SemanticAnalyzer{
-		SymTable:      NewSymbolTable(),
+		SymTable:      NewSymbolTableWithDialect(dialect),
 		CurrentOffset: 5, // Stack frame header offset
-		Errors:        make([]string, 0),
-		Warnings:      make([]string, 0),
+		Errors:        make([]diagnostic.Diagnostic, 0),
+		Warnings:      make([]diagnostic.Diagnostic, 0),
 	}
 }
 
@@ -50,12 +59,12 @@ func (sa *SemanticAnalyzer) GetSymbolTable() *SymbolTable {
 }
 
 // Get errors
-func (sa *SemanticAnalyzer) GetErrors() []string {
+func (sa *SemanticAnalyzer) GetErrors() []diagnostic.Diagnostic {
 	return sa.Errors
 }
 
 // Get warnings
-func (sa *SemanticAnalyzer) GetWarnings() []string {
+func (sa *SemanticAnalyzer) GetWarnings() []diagnostic.Diagnostic {
 	return sa.Warnings
 }
 
@@ -63,8 +72,9 @@ func (sa *SemanticAnalyzer) GetWarnings() []string {
 
 // Visit <program> node
 func (sa *SemanticAnalyzer) visitProgram(node *milestone2.AbstractSyntaxTree) *ProgramNode {
-	if node.Value != "<program>" {
-		sa.addError(fmt.Sprintf("expected <program> node, got %s", node.Value))
+	defer sa.at(node)()
+	if node.Kind != milestone2.NodeProgram {
+		sa.addError(diagnostic.MalformedTree, "expected <program> node, got %s", node.Value)
 		return NewProgramNode("")
 	}
 
@@ -75,10 +85,10 @@ func (sa *SemanticAnalyzer) visitProgram(node *milestone2.AbstractSyntaxTree) *P
 	// Extract program name from <program-header>
 	if len(node.Children) > 0 {
 		headerNode := node.Children[0]
-		if headerNode.Value == "<program-header>" && len(headerNode.Children) > 1 {
+		if headerNode.Kind == milestone2.NodeProgramHeader && len(headerNode.Children) > 1 {
 			programNameNode := headerNode.Children[1]
-			if strings.Contains(programNameNode.Value, "IDENTIFIER") {
-				programName = extractValue(programNameNode.Value)
+			if programNameNode.IsToken(milestone1.KindIdentifier) {
+				programName = programNameNode.Text
 
 				// Add program to symbol table
 				sa.SymTable.Enter(programName, ObjProgram, TypeNone, 0, 1, 0)
@@ -103,7 +113,7 @@ func (sa *SemanticAnalyzer) visitProgram(node *milestone2.AbstractSyntaxTree) *P
 	}
 
 	// Visit <compound-statement> - semantic validation + decorated AST
-	if len(node.Children) > 2 && node.Children[2].Value == "<compound-statement>" {
+	if len(node.Children) > 2 && node.Children[2].Kind == milestone2.NodeCompoundStatement {
 		blockNode = sa.visitCompoundStatement(node.Children[2])
 	}
 
@@ -115,7 +125,8 @@ func (sa *SemanticAnalyzer) visitProgram(node *milestone2.AbstractSyntaxTree) *P
 
 // Visit <declaration-part> node
 func (sa *SemanticAnalyzer) visitDeclarationPart(node *milestone2.AbstractSyntaxTree) DecoratedNode {
-	if node.Value != "<declaration-part>" {
+	defer sa.at(node)()
+	if node.Kind != milestone2.NodeDeclarationPart {
 		return nil
 	}
 
@@ -124,14 +135,14 @@ func (sa *SemanticAnalyzer) visitDeclarationPart(node *milestone2.AbstractSyntax
 	// Visit all declarations
 	for _, child := range node.Children {
 		var decl DecoratedNode
-		switch child.Value {
-		case "<const-declaration>":
+		switch child.Kind {
+		case milestone2.NodeConstDeclaration:
 			decl = sa.visitConstDeclaration(child)
-		case "<var-declaration>":
+		case milestone2.NodeVarDeclaration:
 			decl = sa.visitVarDeclaration(child)
-		case "<type-declaration>":
+		case milestone2.NodeTypeDeclaration:
 			decl = sa.visitTypeDeclaration(child)
-		case "<subprogram-declaration>":
+		case milestone2.NodeSubprogramDeclaration:
 			decl = sa.visitSubprogramDeclaration(child)
 		}
 
@@ -159,16 +170,18 @@ func (sa *SemanticAnalyzer) visitDeclarationPart(node *milestone2.AbstractSyntax
 // Visit <var-declaration> node
 // Builds symbol table AND creates VarDecl nodes
 func (sa *SemanticAnalyzer) visitVarDeclaration(node *milestone2.AbstractSyntaxTree) DecoratedNode {
+	defer sa.at(node)()
 	declarations := make([]DecoratedNode, 0)
 
 	// Process variable declarations: <identifier-list> : <type> ;
-	for i := 1; i < len(node.Children); i += 4 {
-		if i+2 >= len(node.Children) {
+	children := milestone2.WithoutErrorNodes(node.Children)
+	for i := 1; i < len(children); i += 4 {
+		if i+2 >= len(children) {
 			break
 		}
 
-		identifierListNode := node.Children[i]
-		typeNode := node.Children[i+2]
+		identifierListNode := children[i]
+		typeNode := children[i+2]
 
 		// Extract identifiers
 		identifiers := sa.extractIdentifierList(identifierListNode)
@@ -177,18 +190,18 @@ func (sa *SemanticAnalyzer) visitVarDeclaration(node *milestone2.AbstractSyntaxT
 		typ, ref := sa.processType(typeNode)
 
 		// Forward reference check: for user-defined types, ensure type is already declared
-		if typeNode.Value == "<type>" && len(typeNode.Children) > 0 {
+		if typeNode.Kind == milestone2.NodeType && len(typeNode.Children) > 0 {
 			typeChild := typeNode.Children[0]
-			if strings.HasPrefix(typeChild.Value, "IDENTIFIER(") {
-				typeName := extractValue(typeChild.Value)
+			if typeChild.IsToken(milestone1.KindIdentifier) {
+				typeName := typeChild.Text
 				// Check if type exists
 				if typeIdx, exists := sa.SymTable.Lookup(typeName); !exists {
-					sa.addError(fmt.Sprintf("Forward reference: type '%s' not declared before use", typeName))
+					sa.addError(diagnostic.UndeclaredIdent, "Forward reference: type '%s' not declared before use", typeName)
 				} else {
 					// Verify it's actually a type
 					if entry, err := sa.SymTable.GetEntry(typeIdx); err == nil {
 						if entry.Obj != ObjType {
-							sa.addError(fmt.Sprintf("'%s' is not a type", typeName))
+							sa.addError(diagnostic.WrongIdentKind, "'%s' is not a type", typeName)
 						}
 					}
 				}
@@ -201,7 +214,7 @@ func (sa *SemanticAnalyzer) visitVarDeclaration(node *milestone2.AbstractSyntaxT
 		for _, identifier := range identifiers {
 			// Check for duplicate
 			if sa.SymTable.IsDeclaredInCurrentScope(identifier) {
-				sa.addError(fmt.Sprintf("Duplicate variable declaration: %s", identifier))
+				sa.addError(diagnostic.DuplicateDecl, "Duplicate variable declaration: %s", identifier)
 				continue
 			}
 
@@ -242,26 +255,43 @@ func (sa *SemanticAnalyzer) visitVarDeclaration(node *milestone2.AbstractSyntaxT
 
 // Visit <const-declaration> node
 func (sa *SemanticAnalyzer) visitConstDeclaration(node *milestone2.AbstractSyntaxTree) DecoratedNode {
+	defer sa.at(node)()
 	declarations := make([]DecoratedNode, 0)
 
 	// Process const definitions
 	for i := 1; i < len(node.Children); i++ {
 		child := node.Children[i]
-		if child.Value == "<const-def>" && len(child.Children) >= 3 {
+		if child.Kind == milestone2.NodeConstDef && len(child.Children) >= 3 {
 			identifierNode := child.Children[0]
 			valueNode := child.Children[2]
 
-			identifier := extractValue(identifierNode.Value)
+			identifier := identifierNode.Text
 
 			if sa.SymTable.IsDeclaredInCurrentScope(identifier) {
-				sa.addError(fmt.Sprintf("Duplicate constant declaration: %s", identifier))
+				sa.addError(diagnostic.DuplicateDecl, "Duplicate constant declaration: %s", identifier)
 				continue
 			}
 
-			value, typ := sa.extractConstValue(valueNode.Value)
+			value, typ, ok := sa.evalConstant(valueNode)
+			if !ok {
+				// Still declared (without a usable type) so later uses don't report it as undefined
+				value, typ = 0, TypeNone
+			}
 
-			// Add to symbol table
-			tabIndex := sa.SymTable.Enter(identifier, ObjConstant, typ, -1, 1, value)
+			// Add to symbol table (adr holds the value, or its RCONST/STAB index for reals/strings)
+			adr, ref := 0, -1
+			switch v := value.(type) {
+			case int:
+				adr = v
+			case enumValue:
+				adr, ref = v.Ord, v.Ref
+				value = v.Ord
+			case float64:
+				adr = sa.SymTable.EnterReal(v)
+			case string:
+				adr = sa.SymTable.EnterString(v)
+			}
+			tabIndex := sa.SymTable.Enter(identifier, ObjConstant, typ, ref, 1, adr)
 
 			// Create decorated node
 			constDecl := NewConstDeclNode(identifier, value, typ)
@@ -286,21 +316,23 @@ func (sa *SemanticAnalyzer) visitConstDeclaration(node *milestone2.AbstractSynta
 
 // Visit <type-declaration> node
 func (sa *SemanticAnalyzer) visitTypeDeclaration(node *milestone2.AbstractSyntaxTree) DecoratedNode {
+	defer sa.at(node)()
 	// Type declarations don't appear in decorated AST typically
 	// But we still process them for symbol table
 
-	for i := 1; i < len(node.Children); i += 4 {
-		if i+2 >= len(node.Children) {
+	children := milestone2.WithoutErrorNodes(node.Children)
+	for i := 1; i < len(children); i += 4 {
+		if i+2 >= len(children) {
 			break
 		}
 
-		identifierNode := node.Children[i]
-		typeNode := node.Children[i+2]
+		identifierNode := children[i]
+		typeNode := children[i+2]
 
-		identifier := extractValue(identifierNode.Value)
+		identifier := identifierNode.Text
 
 		if sa.SymTable.IsDeclaredInCurrentScope(identifier) {
-			sa.addError(fmt.Sprintf("Duplicate type declaration: %s", identifier))
+			sa.addError(diagnostic.DuplicateDecl, "Duplicate type declaration: %s", identifier)
 			continue
 		}
 
@@ -313,6 +345,7 @@ func (sa *SemanticAnalyzer) visitTypeDeclaration(node *milestone2.AbstractSyntax
 
 // Visit <subprogram-declaration> node
 func (sa *SemanticAnalyzer) visitSubprogramDeclaration(node *milestone2.AbstractSyntaxTree) DecoratedNode {
+	defer sa.at(node)()
 	if len(node.Children) < 2 {
 		return nil
 	}
@@ -320,17 +353,22 @@ func (sa *SemanticAnalyzer) visitSubprogramDeclaration(node *milestone2.Abstract
 	keywordNode := node.Children[0]
 	nameNode := node.Children[1]
 
-	isFungsi := strings.Contains(keywordNode.Value, "fungsi")
-	name := extractValue(nameNode.Value)
+	// Header that failed to parse (no name): nothing to declare
+	if !nameNode.IsToken(milestone1.KindIdentifier) {
+		return nil
+	}
+
+	isFungsi := keywordNode.IsKeyword(milestone1.KwFunction)
+	name := nameNode.Text
 
 	// Check for duplicate subprogram in current scope
 	if tabIndex, exists := sa.SymTable.LookupInCurrentScope(name); exists {
 		entry, _ := sa.SymTable.GetEntry(tabIndex)
 		if entry != nil && (entry.Obj == ObjProcedure || entry.Obj == ObjFunction) {
-			sa.addError(fmt.Sprintf("Duplicate subprogram declaration: %s", name))
+			sa.addError(diagnostic.DuplicateDecl, "Duplicate subprogram declaration: %s", name)
 		} else if entry != nil {
 			// Also check if name conflicts with existing variable/type/constant
-			sa.addError(fmt.Sprintf("Identifier '%s' already declared as %s in current scope", name, entry.Obj))
+			sa.addError(diagnostic.DuplicateDecl, "Identifier '%s' already declared as %s in current scope", name, entry.Obj)
 		}
 	}
 
@@ -345,19 +383,25 @@ func (sa *SemanticAnalyzer) visitSubprogramDeclaration(node *milestone2.Abstract
 	lastParamIndex := 0
 
 	for _, child := range node.Children {
-		if child.Value == "<parameter-list>" {
+		if child.Kind == milestone2.NodeParameterList {
 			params := sa.extractParameters(child)
 			for _, param := range params {
 				size := sa.SymTable.getTypeSize(param.Type, param.Ref)
+				if param.Nrm == 0 {
+					size = 1 // var parameter only holds the address of the argument
+				}
 				lastParamIndex = sa.SymTable.Enter(param.Name, ObjVariable, param.Type, param.Ref, param.Nrm, sa.CurrentOffset)
 				sa.SymTable.AddParameterSize(size)
 				sa.CurrentOffset += size
 
 				// Create parameter decorated node
+				entry := sa.SymTable.Tab[lastParamIndex]
 				paramNode := NewVarDeclNode(param.Name, param.Type)
 				paramNode.TabIndex = lastParamIndex
 				paramNode.Type = param.Type
+				paramNode.Ref = entry.Ref
 				paramNode.Level = sa.SymTable.CurrentLevel
+				paramNode.Address = entry.Adr
 				parameters = append(parameters, paramNode)
 			}
 			break
@@ -367,12 +411,12 @@ func (sa *SemanticAnalyzer) visitSubprogramDeclaration(node *milestone2.Abstract
 	sa.SymTable.UpdateBlockLastParam(blockIndex, lastParamIndex)
 
 	// Determine return type
-	returnType := TypeNone
+	returnType, returnRef := TypeNone, -1
 	if isFungsi {
 		for i := 0; i < len(node.Children)-1; i++ {
-			if strings.Contains(node.Children[i].Value, "COLON") && i+1 < len(node.Children) {
-				if node.Children[i+1].Value == "<type>" {
-					returnType, _ = sa.processType(node.Children[i+1])
+			if node.Children[i].IsToken(milestone1.KindColon) && i+1 < len(node.Children) {
+				if node.Children[i+1].Kind == milestone2.NodeType {
+					returnType, returnRef = sa.processType(node.Children[i+1])
 					break
 				}
 			}
@@ -387,12 +431,13 @@ func (sa *SemanticAnalyzer) visitSubprogramDeclaration(node *milestone2.Abstract
 
 	sa.SymTable.exitLevel()
 	tabIndex := sa.SymTable.Enter(name, objClass, returnType, blockIndex, 1, 0)
+	sa.SymTable.Tab[tabIndex].Rref = returnRef
 	sa.SymTable.Display[sa.SymTable.CurrentLevel+1] = blockIndex
 	sa.SymTable.enterLevel()
 
 	// For functions, add implicit return variable
 	if isFungsi {
-		sa.SymTable.Enter(name, ObjVariable, returnType, -1, 1, 0)
+		sa.SymTable.Enter(name, ObjVariable, returnType, returnRef, 1, 0)
 	}
 
 	// Process local declarations and body
@@ -400,9 +445,9 @@ func (sa *SemanticAnalyzer) visitSubprogramDeclaration(node *milestone2.Abstract
 	var body DecoratedNode
 
 	for _, child := range node.Children {
-		if child.Value == "<declaration-part>" {
+		if child.Kind == milestone2.NodeDeclarationPart {
 			localDecls = sa.visitDeclarationPart(child)
-		} else if child.Value == "<compound-statement>" {
+		} else if child.Kind == milestone2.NodeCompoundStatement {
 			body = sa.visitCompoundStatement(child)
 		}
 	}
@@ -410,7 +455,7 @@ func (sa *SemanticAnalyzer) visitSubprogramDeclaration(node *milestone2.Abstract
 	// Validate function has return assignment
 	if isFungsi {
 		if !sa.checkFunctionHasReturnAssignment(body, name) {
-			sa.addWarning(fmt.Sprintf("Function '%s' should assign to its own name at least once", name))
+			sa.addWarning(diagnostic.MissingResult, "Function '%s' should assign to its own name at least once", name)
 		}
 	}
 
@@ -422,9 +467,7 @@ func (sa *SemanticAnalyzer) visitSubprogramDeclaration(node *milestone2.Abstract
 	subprogNode.TabIndex = tabIndex
 	subprogNode.Type = returnType
 	subprogNode.Level = sa.SymTable.CurrentLevel
-	if localDecls != nil {
-		// TODO: add local declarations to body
-	}
+	subprogNode.Declarations = localDecls
 
 	return subprogNode
 }
@@ -432,11 +475,12 @@ func (sa *SemanticAnalyzer) visitSubprogramDeclaration(node *milestone2.Abstract
 // Visit <compound-statement> node
 // Performs semantic validation while building decorated AST
 func (sa *SemanticAnalyzer) visitCompoundStatement(node *milestone2.AbstractSyntaxTree) *BlockNode {
+	defer sa.at(node)()
 	statements := make([]DecoratedNode, 0)
 
 	// Find <statement-list>
 	for _, child := range node.Children {
-		if child.Value == "<statement-list>" {
+		if child.Kind == milestone2.NodeStatementList {
 			statements = sa.visitStatementList(child)
 			break
 		}
@@ -451,6 +495,7 @@ func (sa *SemanticAnalyzer) visitCompoundStatement(node *milestone2.AbstractSynt
 
 // Visit <statement-list> node
 func (sa *SemanticAnalyzer) visitStatementList(node *milestone2.AbstractSyntaxTree) []DecoratedNode {
+	defer sa.at(node)()
 	statements := make([]DecoratedNode, 0)
 
 	for _, child := range node.Children {
@@ -464,18 +509,23 @@ func (sa *SemanticAnalyzer) visitStatementList(node *milestone2.AbstractSyntaxTr
 
 // Visit individual statement
 func (sa *SemanticAnalyzer) visitStatement(node *milestone2.AbstractSyntaxTree) DecoratedNode {
-	switch node.Value {
-	case "<assignment-statement>":
+	defer sa.at(node)()
+	switch node.Kind {
+	case milestone2.NodeAssignmentStatement:
 		return sa.visitAssignmentStatement(node)
-	case "<procedure-call>":
+	case milestone2.NodeProcedureCall:
 		return sa.visitProcedureCall(node)
-	case "<compound-statement>":
+	case milestone2.NodeCompoundStatement:
 		return sa.visitCompoundStatement(node)
-	case "<if-statement>":
+	case milestone2.NodeIfStatement:
 		return sa.visitIfStatement(node)
-	case "<while-statement>":
+	case milestone2.NodeWhileStatement:
 		return sa.visitWhileStatement(node)
-	case "<for-statement>":
+	case milestone2.NodeRepeatStatement:
+		return sa.visitRepeatStatement(node)
+	case milestone2.NodeCaseStatement:
+		return sa.visitCaseStatement(node)
+	case milestone2.NodeForStatement:
 		return sa.visitForStatement(node)
 	default:
 		// Skip non-statement nodes like SEMICOLON
@@ -486,20 +536,23 @@ func (sa *SemanticAnalyzer) visitStatement(node *milestone2.AbstractSyntaxTree)
 // Visit <assignment-statement> node
 // Semantic rule: assignment_statement.node = new AssignNode(new VarNode(ID.lexeme), expr.node)
 func (sa *SemanticAnalyzer) visitAssignmentStatement(node *milestone2.AbstractSyntaxTree) *AssignNode {
+	defer sa.at(node)()
 	var targetName string
+	var targetVar *milestone2.AbstractSyntaxTree
 	var valueNode DecoratedNode
 
 	// Extract target variable and expression
 	for _, child := range node.Children {
-		if child.Value == "<variable>" {
+		if child.Kind == milestone2.NodeVariable {
+			targetVar = child
 			// Extract identifier from variable node
 			for _, grandchild := range child.Children {
-				if strings.Contains(grandchild.Value, "IDENTIFIER") {
-					targetName = extractValue(grandchild.Value)
+				if grandchild.IsToken(milestone1.KindIdentifier) {
+					targetName = grandchild.Text
 					break
 				}
 			}
-		} else if child.Value == "<expression>" {
+		} else if child.Kind == milestone2.NodeExpression {
 			valueNode = sa.visitExpression(child)
 		}
 	}
@@ -509,30 +562,39 @@ func (sa *SemanticAnalyzer) visitAssignmentStatement(node *milestone2.AbstractSy
 	if targetName != "" {
 		tabIndex, found := sa.SymTable.Lookup(targetName)
 		if !found {
-			sa.addError(fmt.Sprintf("Undefined variable '%s'", targetName))
+			sa.addError(diagnostic.UndeclaredIdent, "Undefined variable '%s'", targetName)
 			targetNode = NewVarNode(targetName)
 		} else {
 			entry, _ := sa.SymTable.GetEntry(tabIndex)
 			if entry != nil {
 				if entry.Obj != ObjVariable {
-					sa.addError(fmt.Sprintf("'%s' is not a variable", targetName))
+					sa.addError(diagnostic.WrongIdentKind, "'%s' is not a variable", targetName).
+						WithNote(nil, "'%s' is declared as a %s", targetName, entry.Obj)
 				}
 
-				targetNode = NewVarNode(targetName)
-				targetNode.TabIndex = tabIndex
-				targetNode.Type = entry.Type
-				targetNode.Ref = entry.Ref
-				targetNode.Level = entry.Lev
-				targetNode.Address = entry.Adr
+				// Resolve indices/fields so the target carries the element type
+				if target, ok := sa.visitVariable(targetVar).(*VarNode); ok {
+					targetNode = target
+				} else {
+					targetNode = NewVarNode(targetName)
+				}
 				targetNode.IsLValue = true
 
-				// Type checking
-				if valueNode != nil {
-					targetType := entry.Type
+				// Type checking (skipped if the target or a source variable is already in error)
+				if valueNode != nil && targetNode.Type != TypeNone && !isErrorVar(valueNode) {
+					targetType := targetNode.Type
 					valueType := sa.getNodeType(valueNode)
 
 					if !sa.typesCompatible(targetType, valueType) {
-						sa.addError(fmt.Sprintf("Type mismatch in assignment: cannot assign %s to %s", valueType, targetType))
+						sa.addError(diagnostic.TypeMismatch, "Type mismatch in assignment: cannot assign %s to %s", valueType, targetType)
+					} else if !sa.sameCompositeType(targetType, targetNode.Ref, valueNode.GetRef()) {
+						if targetType == TypeEnum {
+							sa.addError(diagnostic.TypeMismatch, "Type mismatch in assignment: value belongs to a different enumeration")
+						} else {
+							sa.addError(diagnostic.TypeMismatch, "Type mismatch in assignment: %s types have different structure", targetType)
+						}
+					} else if low, high, bounded := sa.SymTable.RangeBounds(targetNode.Ref); bounded && isOrdinalType(targetType) {
+						sa.checkConstInRange(valueNode, low, high, "Value")
 					}
 				}
 			}
@@ -555,6 +617,7 @@ func (sa *SemanticAnalyzer) visitAssignmentStatement(node *milestone2.AbstractSy
 // - <expression> → <simple-expr> : expression.node = simple_expr.node
 // - <expression> → <simple-expr> relop <simple-expr> : expression.node = new BinOpNode(relop, left, right)
 func (sa *SemanticAnalyzer) visitExpression(node *milestone2.AbstractSyntaxTree) DecoratedNode {
+	defer sa.at(node)()
 	if len(node.Children) == 1 {
 		// expression → simple-expression
 		return sa.visitSimpleExpression(node.Children[0])
@@ -563,15 +626,18 @@ func (sa *SemanticAnalyzer) visitExpression(node *milestone2.AbstractSyntaxTree)
 	if len(node.Children) == 3 {
 		// expression → simple-expression relop simple-expression
 		left := sa.visitSimpleExpression(node.Children[0])
-		operator := extractValue(node.Children[1].Value)
+		operator := node.Children[1].Text
 		right := sa.visitSimpleExpression(node.Children[2])
 
 		// Type checking
 		leftType := sa.getNodeType(left)
 		rightType := sa.getNodeType(right)
 
-		if !sa.typesCompatible(leftType, rightType) {
-			sa.addError(fmt.Sprintf("Type mismatch in relational operation: %s and %s", leftType, rightType))
+		// Either side may be widened (e.g. integer < real)
+		if !sa.typesCompatible(leftType, rightType) && !sa.typesCompatible(rightType, leftType) {
+			sa.addError(diagnostic.TypeMismatch, "Type mismatch in relational operation: %s and %s", leftType, rightType)
+		} else if leftType == TypeEnum && !sa.sameCompositeType(TypeEnum, left.GetRef(), right.GetRef()) {
+			sa.addError(diagnostic.TypeMismatch, "Type mismatch in relational operation: values of different enumerations")
 		}
 
 		binOp := NewBinOpNode(operator, left, right)
@@ -583,44 +649,79 @@ func (sa *SemanticAnalyzer) visitExpression(node *milestone2.AbstractSyntaxTree)
 }
 
 // Visit <simple-expression> node
-// Semantic rule: <simple-expr> → <term> (addop <term>)*
+// Semantic rule: <simple-expr> → (+|-)? <term> (addop <term>)*
 func (sa *SemanticAnalyzer) visitSimpleExpression(node *milestone2.AbstractSyntaxTree) DecoratedNode {
-	if len(node.Children) == 1 {
-		// simple-expression → term
-		return sa.visitTerm(node.Children[0])
+	defer sa.at(node)()
+	if len(node.Children) == 0 {
+		return NewNumberNode(0)
 	}
 
-	if len(node.Children) >= 3 {
-		// simple-expression → term addop term
-		left := sa.visitTerm(node.Children[0])
-		operator := extractValue(node.Children[1].Value)
-		right := sa.visitTerm(node.Children[2])
+	// Optional leading sign: simple-expression → (+|-) term ...
+	start := 0
+	sign := ""
+	if node.Children[0].Kind != milestone2.NodeTerm {
+		sign = node.Children[0].Text
+		start = 1
+	}
+	if start >= len(node.Children) {
+		return NewNumberNode(0)
+	}
+
+	result := sa.visitTerm(node.Children[start])
+	if sign != "" {
+		operandType := sa.getNodeType(result)
+		if !sa.isNumericType(operandType) {
+			sa.addError(diagnostic.InvalidOperand, "Unary '%s' requires numeric operand", sign)
+		}
+		unaryOp := NewUnaryOpNode(sign, result)
+		unaryOp.Type = operandType
+		result = unaryOp
+	}
+
+	// Handle chained operators: term (+|-|atau) term (+|-|atau) term ...
+	for i := start + 1; i+1 < len(node.Children); i += 2 {
+		operatorNode := node.Children[i]
+		operator := operatorNode.Text
+		right := sa.visitTerm(node.Children[i+1])
 
 		// Type checking
-		leftType := sa.getNodeType(left)
+		leftType := sa.getNodeType(result)
 		rightType := sa.getNodeType(right)
 
-		if !sa.isNumericType(leftType) || !sa.isNumericType(rightType) {
-			sa.addError("Arithmetic operator requires numeric operands")
-		}
-
-		binOp := NewBinOpNode(operator, left, right)
-		// Type promotion: if either operand is real, result is real
-		// Division (/) always produces real
-		if operator == "/" || leftType == TypeReal || rightType == TypeReal {
-			binOp.Type = TypeReal
+		binOp := NewBinOpNode(operator, result, right)
+		if operatorNode.IsKeyword(milestone1.KwOr) {
+			// Logical OR - expects boolean operands
+			if leftType != TypeBoolean || rightType != TypeBoolean {
+				sa.addError(diagnostic.InvalidOperand, "Logical OR operator requires boolean operands")
+			}
+			binOp.Type = TypeBoolean
+		} else if operator == "+" && (leftType == TypeString || rightType == TypeString) {
+			// String concatenation - char operands are converted to strings
+			if !sa.typesCompatible(TypeString, leftType) || !sa.typesCompatible(TypeString, rightType) {
+				sa.addError(diagnostic.InvalidOperand, "String concatenation requires string or char operands, got %s and %s", leftType, rightType)
+			}
+			binOp.Type = TypeString
 		} else {
-			binOp.Type = TypeInteger
+			if !sa.isNumericType(leftType) || !sa.isNumericType(rightType) {
+				sa.addError(diagnostic.InvalidOperand, "Arithmetic operator requires numeric operands")
+			}
+			// Type promotion: if either operand is real, result is real
+			if leftType == TypeReal || rightType == TypeReal {
+				binOp.Type = TypeReal
+			} else {
+				binOp.Type = TypeInteger
+			}
 		}
-		return binOp
+		result = binOp
 	}
 
-	return NewNumberNode(0)
+	return result
 }
 
 // Visit <term> node
 // Semantic rule: <term> → <factor> (mulop <factor>)*
 func (sa *SemanticAnalyzer) visitTerm(node *milestone2.AbstractSyntaxTree) DecoratedNode {
+	defer sa.at(node)()
 	if len(node.Children) == 0 {
 		return NewNumberNode(0)
 	}
@@ -635,9 +736,9 @@ func (sa *SemanticAnalyzer) visitTerm(node *milestone2.AbstractSyntaxTree) Decor
 		}
 
 		operatorNode := node.Children[i]
-		operator := extractValue(operatorNode.Value)
+		operator := operatorNode.Text
 
-		if node.Children[i+1].Value != "<factor>" {
+		if node.Children[i+1].Kind != milestone2.NodeFactor {
 			break
 		}
 
@@ -647,10 +748,10 @@ func (sa *SemanticAnalyzer) visitTerm(node *milestone2.AbstractSyntaxTree) Decor
 		leftType := sa.getNodeType(result)
 		rightType := sa.getNodeType(right)
 
-		if operator == "dan" || operator == "and" {
+		if operatorNode.IsKeyword(milestone1.KwAnd) {
 			// Logical AND - expects boolean operands
 			if leftType != TypeBoolean || rightType != TypeBoolean {
-				sa.addError("Logical AND operator requires boolean operands")
+				sa.addError(diagnostic.InvalidOperand, "Logical AND operator requires boolean operands")
 			}
 			binOp := NewBinOpNode(operator, result, right)
 			binOp.Type = TypeBoolean
@@ -658,7 +759,7 @@ func (sa *SemanticAnalyzer) visitTerm(node *milestone2.AbstractSyntaxTree) Decor
 		} else {
 			// Arithmetic operators
 			if !sa.isNumericType(leftType) || !sa.isNumericType(rightType) {
-				sa.addError("Multiplicative operator requires numeric operands")
+				sa.addError(diagnostic.InvalidOperand, "Multiplicative operator requires numeric operands")
 			}
 			binOp := NewBinOpNode(operator, result, right)
 
@@ -666,10 +767,10 @@ func (sa *SemanticAnalyzer) visitTerm(node *milestone2.AbstractSyntaxTree) Decor
 			if operator == "/" {
 				// Division (/) always produces real
 				binOp.Type = TypeReal
-			} else if operator == "bagi" || operator == "div" || operator == "mod" {
+			} else if operatorNode.IsKeyword(milestone1.KwDiv) || operatorNode.IsKeyword(milestone1.KwMod) {
 				// Integer division (bagi/div) and modulo require integer operands and produce integer
 				if leftType != TypeInteger || rightType != TypeInteger {
-					sa.addError(fmt.Sprintf("Operator '%s' requires integer operands", operator))
+					sa.addError(diagnostic.InvalidOperand, "Operator '%s' requires integer operands", operator)
 				}
 				binOp.Type = TypeInteger
 			} else if leftType == TypeReal || rightType == TypeReal {
@@ -697,55 +798,48 @@ func (sa *SemanticAnalyzer) visitTerm(node *milestone2.AbstractSyntaxTree) Decor
 // - <factor> → <function-call> : factor.node = function_call.node
 // - <factor> → CHAR_LITERAL : factor.node = new CharNode(value)
 func (sa *SemanticAnalyzer) visitFactor(node *milestone2.AbstractSyntaxTree) DecoratedNode {
+	defer sa.at(node)()
 	for _, child := range node.Children {
-		if strings.Contains(child.Value, "NUMBER") {
+		if child.IsToken(milestone1.KindNumber) {
 			// factor → NUMBER
-			valueStr := extractValue(child.Value)
+			valueStr := child.Text
 			value, _ := strconv.Atoi(valueStr)
 			numberNode := NewNumberNode(value)
 			numberNode.Type = TypeInteger
 			return numberNode
-		} else if strings.Contains(child.Value, "STRING_LITERAL") {
+		} else if child.IsToken(milestone1.KindReal) {
+			// factor → REAL
+			value, _ := strconv.ParseFloat(child.Text, 64)
+			return NewRealNode(value)
+		} else if child.IsToken(milestone1.KindStringLiteral) {
 			// factor → STRING_LITERAL
-			valueStr := extractValue(child.Value)
-			// Remove quotes
-			if len(valueStr) >= 2 && valueStr[0] == '\'' {
-				valueStr = valueStr[1 : len(valueStr)-1]
-			}
-			stringNode := NewStringNode(valueStr)
-			stringNode.Type = TypeChar
-			return stringNode
-		} else if strings.Contains(child.Value, "CHAR_LITERAL") {
-			// factor → CHAR_LITERAL
-			valueStr := extractValue(child.Value)
-			if len(valueStr) >= 2 && valueStr[0] == '\'' {
-				valueStr = valueStr[1 : len(valueStr)-1]
-			}
-			charVal := rune(0)
-			if len(valueStr) > 0 {
-				charVal = rune(valueStr[0])
+			return NewStringNode(unquote(child.Text))
+		} else if child.IsToken(milestone1.KindCharLiteral) {
+			// factor → CHAR_LITERAL ('' is the empty string)
+			valueStr := unquote(child.Text)
+			if valueStr == "" {
+				return NewStringNode("")
 			}
+			charVal := rune(valueStr[0])
 			charNode := &CharNode{
 				BaseDecoratedNode: BaseDecoratedNode{Type: TypeChar},
 				Value:             charVal,
 			}
 			return charNode
-		} else if strings.Contains(child.Value, "true") || strings.Contains(child.Value, "false") {
+		} else if child.IsKeyword(milestone1.KwTrue) || child.IsKeyword(milestone1.KwFalse) {
 			// factor → true | false
-			valueStr := extractValue(child.Value)
-			boolVal := (valueStr == "true")
-			boolNode := NewBooleanNode(boolVal)
+			boolNode := NewBooleanNode(child.IsKeyword(milestone1.KwTrue))
 			boolNode.Type = TypeBoolean
 			return boolNode
-		} else if strings.Contains(child.Value, "tidak") || strings.Contains(child.Value, "not") {
+		} else if child.IsKeyword(milestone1.KwNot) {
 			// factor → tidak <factor> (NOT operator)
 			// Find the factor child
 			for _, subChild := range node.Children {
-				if subChild.Value == "<factor>" {
+				if subChild.Kind == milestone2.NodeFactor {
 					operand := sa.visitFactor(subChild)
 					operandType := sa.getNodeType(operand)
 					if operandType != TypeBoolean {
-						sa.addError("NOT operator requires boolean operand")
+						sa.addError(diagnostic.InvalidOperand, "NOT operator requires boolean operand")
 					}
 					unaryOp := &UnaryOpNode{
 						BaseDecoratedNode: BaseDecoratedNode{Type: TypeBoolean},
@@ -755,20 +849,23 @@ func (sa *SemanticAnalyzer) visitFactor(node *milestone2.AbstractSyntaxTree) Dec
 					return unaryOp
 				}
 			}
-		} else if child.Value == "<function-call>" {
+		} else if child.Kind == milestone2.NodeFunctionCall {
 			// factor → <function-call>
 			return sa.visitFunctionCall(child)
-		} else if child.Value == "<variable>" {
-			// factor → variable (which contains ID)
+		} else if child.Kind == milestone2.NodeVariable {
+			// factor → variable (which contains ID), or a call of a function without arguments
+			if call := sa.parameterlessCall(child); call != nil {
+				return call
+			}
 			return sa.visitVariable(child)
-		} else if strings.Contains(child.Value, "IDENTIFIER") {
+		} else if child.IsToken(milestone1.KindIdentifier) {
 			// factor → ID (direct identifier)
 			return sa.visitIdentifier(child)
-		} else if child.Value == "<expression>" {
+		} else if child.Kind == milestone2.NodeExpression {
 			// factor → ( expression )
 			return sa.visitExpression(child)
-		} else {
-			// Recursively check children
+		} else if len(child.Children) > 0 {
+			// Recursively check children (skip leaf tokens such as parentheses)
 			if result := sa.visitFactor(child); result != nil {
 				return result
 			}
@@ -781,92 +878,139 @@ func (sa *SemanticAnalyzer) visitFactor(node *milestone2.AbstractSyntaxTree) Dec
 // Visit <variable> node
 // Handles:
 // - <variable> → ID : simple variable reference
-// - <variable> → ID [ expression ] : array indexing
-// - <variable> → ID . ID : record field access
-// - <variable> → ID [ expr ] . field : array element with field access (arr[i].field)
-// - <variable> → ID . field [ expr ] : record field with array access (rec.arr[i])
+// - <variable> → ID ( [ expression ] | . field )* : selector chain, e.g. data[i].nilai[j]
+// Each selector is type checked against the type produced by the previous one
 func (sa *SemanticAnalyzer) visitVariable(node *milestone2.AbstractSyntaxTree) DecoratedNode {
-	var varNode *VarNode
-	var fieldName string
-	hasFieldBeforeIndex := false
+	defer sa.at(node)()
+	if len(node.Children) == 0 || !node.Children[0].IsToken(milestone1.KindIdentifier) {
+		return NewVarNode("unknown")
+	}
 
-	// Extract identifier, optional indices (multi-dimensional), and optional field name
-	indexExprs := make([]DecoratedNode, 0)
-	for i, child := range node.Children {
-		if strings.Contains(child.Value, "IDENTIFIER") {
-			if varNode == nil {
-				// First identifier is the variable
-				varNode = sa.visitIdentifier(child)
-			} else {
-				// Second identifier is field name (record.field)
-				fieldName = extractValue(child.Value)
-				// Check if field comes before indices (rec.field[i]) vs after (arr[i].field)
-				hasFieldBeforeIndex = i < len(node.Children)-1 &&
-					len(indexExprs) == 0
-			}
-		} else if child.Value == "<expression>" {
-			// Collect all index expressions for multi-dimensional arrays
-			idxExpr := sa.visitExpression(child)
-			indexExprs = append(indexExprs, idxExpr)
+	varNode := sa.visitIdentifier(node.Children[0])
+	for _, child := range node.Children[1:] {
+		if varNode.Type == TypeNone {
+			// Base or an earlier selector is already in error
+			break
+		}
+		if child.Kind == milestone2.NodeExpression {
+			index := sa.visitExpression(child)
+			restore := sa.at(child)
+			sa.processIndex(varNode, index)
+			restore()
+		} else if child.IsToken(milestone1.KindIdentifier) {
+			restore := sa.at(child)
+			sa.processFieldAccess(varNode, child.Text)
+			restore()
 		}
 	}
 
-	if varNode == nil {
-		return NewVarNode("unknown")
-	}
+	return varNode
+}
 
-	// Process access patterns based on order:
-	// Pattern 1: arr[i].field - Apply indices first, then field access
-	// Pattern 2: rec.field[i] - Apply field access first, then indices
+// Helper function to process array indexing: [index] selects an element of the current array type
+func (sa *SemanticAnalyzer) processIndex(varNode *VarNode, indexExpr DecoratedNode) {
+	if varNode.Type != TypeArray {
+		if varNode.IsIndexed {
+			sa.addError(diagnostic.InvalidIndex, "Too many dimensions for array '%s'", varNode.Name)
+		} else {
+			sa.addError(diagnostic.InvalidIndex, "'%s' is not an array", varNode.Name)
+		}
+		varNode.Type = TypeNone
+		return
+	}
 
-	if fieldName != "" && hasFieldBeforeIndex {
-		// Pattern 2: rec.field[i] - Field access before indexing
-		varNode = sa.processFieldAccess(varNode, fieldName)
+	// Dimension within the array type being indexed: indices since the last field selector
+	dimension := 1
+	for i := len(varNode.Selectors) - 1; i >= 0 && varNode.Selectors[i].Index != nil; i-- {
+		dimension++
 	}
 
-	// Handle array indexing (supports multi-dimensional arrays)
-	if len(indexExprs) > 0 {
-		varNode.IsIndexed = true
+	// Resolve to element type via ATAB chain
+	sel := Selector{Index: indexExpr, Ref: varNode.Ref, Type: TypeNone}
+	if varNode.Ref >= 0 && varNode.Ref < len(sa.SymTable.Atab) {
+		atabEntry := sa.SymTable.Atab[varNode.Ref]
 
-		for dimIdx, idxExpr := range indexExprs {
-			if varNode.Type != TypeArray {
-				if dimIdx == 0 {
-					sa.addError(fmt.Sprintf("'%s' is not an array", varNode.Name))
-				} else {
-					sa.addError(fmt.Sprintf("Too many dimensions for array '%s'", varNode.Name))
-				}
-				break
+		// Index must match the index type of this dimension (same enumeration for enum indices)
+		indexType := TypeKind(atabEntry.Xtyp)
+		if !sa.ordinalMatches(indexType, atabEntry.Xref, indexExpr) {
+			if indexType == TypeEnum && sa.getNodeType(indexExpr) == TypeEnum {
+				sa.addError(diagnostic.InvalidIndex, "Array index (dimension %d) belongs to a different enumeration than the index type",
+					dimension)
+			} else {
+				sa.addError(diagnostic.InvalidIndex, "Array index (dimension %d) must be %s type, got %s",
+					dimension, indexType, sa.getNodeType(indexExpr))
 			}
+		} else {
+			sa.checkConstInRange(indexExpr, atabEntry.Low, atabEntry.High, "Array index")
+		}
 
-			if sa.getNodeType(idxExpr) != TypeInteger {
-				sa.addError(fmt.Sprintf("Array index (dimension %d) must be integer type", dimIdx+1))
-			}
+		sel.Type = TypeKind(atabEntry.Etyp)
+		varNode.Type = sel.Type
+		varNode.Ref = atabEntry.Eref
+	}
 
-			// Resolve to element type via ATAB chain
-			if varNode.Ref >= 0 && varNode.Ref < len(sa.SymTable.Atab) {
-				atabEntry := sa.SymTable.Atab[varNode.Ref]
-				varNode.Type = TypeKind(atabEntry.Etyp)
-				varNode.Ref = atabEntry.Eref
-			}
-		}
+	varNode.IsIndexed = true
+	varNode.Selectors = append(varNode.Selectors, sel)
+}
 
-		// Store last index
-		varNode.Index = indexExprs[len(indexExprs)-1]
+// Check that an ordinal expression has the given type (and the same enumeration for enum types)
+func (sa *SemanticAnalyzer) ordinalMatches(typ TypeKind, ref int, node DecoratedNode) bool {
+	nodeType := sa.getNodeType(node)
+	if nodeType == TypeNone {
+		return true // Already reported
 	}
+	if nodeType != typ {
+		return false
+	}
+	return typ != TypeEnum || sa.SymTable.EnumBase(ref) == sa.SymTable.EnumBase(node.GetRef())
+}
 
-	if fieldName != "" && !hasFieldBeforeIndex {
-		// Pattern 1: arr[i].field - Field access after indexing
-		varNode = sa.processFieldAccess(varNode, fieldName)
+// Report a constant value outside low..high (array index or subrange assignment)
+func (sa *SemanticAnalyzer) checkConstInRange(node DecoratedNode, low, high int, what string) {
+	value, ok := sa.constOrdinal(node)
+	if ok && (value < low || value > high) {
+		typ := sa.getNodeType(node)
+		sa.addError(diagnostic.OutOfRange, "%s %s is out of range %s..%s", what,
+			formatOrdinal(value, typ), formatOrdinal(low, typ), formatOrdinal(high, typ))
 	}
+}
 
-	return varNode
+// Ordinal value of a decorated node known at compile time (literal or constant)
+func (sa *SemanticAnalyzer) constOrdinal(node DecoratedNode) (int, bool) {
+	switch n := node.(type) {
+	case *NumberNode:
+		return n.Value, true
+	case *CharNode:
+		return int(n.Value), true
+	case *BooleanNode:
+		if n.Value {
+			return 1, true
+		}
+		return 0, true
+	case *UnaryOpNode:
+		if value, ok := sa.constOrdinal(n.Operand); ok && n.Type == TypeInteger {
+			if n.Operator == "-" {
+				return -value, true
+			}
+			return value, true
+		}
+	case *VarNode:
+		entry, err := sa.SymTable.GetEntry(n.TabIndex)
+		if err == nil && entry.Obj == ObjConstant && len(n.Selectors) == 0 && isOrdinalType(entry.Type) {
+			return entry.Adr, true
+		}
+	}
+	return 0, false
 }
 
 // Helper function to process field access on a record
+// The field offset is added to VarNode.Address while no index has been applied yet and the base is
+// not a var parameter (whose slot holds the address of the actual argument)
 func (sa *SemanticAnalyzer) processFieldAccess(varNode *VarNode, fieldName string) *VarNode {
 	// Verify variable is a record
 	if varNode.Type != TypeRecord {
-		sa.addError(fmt.Sprintf("'%s' is not a record (cannot access field '%s')", varNode.Name, fieldName))
+		sa.addError(diagnostic.InvalidField, "'%s' is not a record (cannot access field '%s')", varNode.Name, fieldName)
+		varNode.Type = TypeNone
 		return varNode
 	}
 
@@ -876,49 +1020,46 @@ func (sa *SemanticAnalyzer) processFieldAccess(varNode *VarNode, fieldName strin
 
 		// Search for field in record's symbol table entries
 		fieldTabIndex := btabEntry.Last
-		found := false
-
 		for fieldTabIndex >= 0 && fieldTabIndex < len(sa.SymTable.Tab) {
 			fieldEntry := sa.SymTable.Tab[fieldTabIndex]
 
-			if fieldEntry.Identifier == fieldName {
-				// Found the field - create new VarNode with field info
-				fieldNode := NewVarNode(varNode.Name + "." + fieldName)
-				fieldNode.TabIndex = fieldTabIndex
-				fieldNode.Type = fieldEntry.Type
-				fieldNode.Ref = fieldEntry.Ref
-				fieldNode.Level = fieldEntry.Lev
-				fieldNode.Address = varNode.Address + fieldEntry.Adr // Base + field offset
-				fieldNode.IsLValue = true
-				found = true
-				return fieldNode
+			if fieldEntry.Identifier == fieldName && fieldEntry.Obj == ObjField {
+				varNode.Selectors = append(varNode.Selectors, Selector{
+					Field:  fieldName,
+					Offset: fieldEntry.Adr,
+					Ref:    varNode.Ref,
+					Type:   fieldEntry.Type,
+				})
+				varNode.Name = varNode.Name + "." + fieldName
+				varNode.Type = fieldEntry.Type
+				varNode.Ref = fieldEntry.Ref
+				if base, err := sa.SymTable.GetEntry(varNode.TabIndex); err == nil && base.Nrm == 1 && !varNode.IsIndexed {
+					varNode.Address += fieldEntry.Adr // Base + field offset
+				}
+				return varNode
 			}
 
 			// Follow linked list
 			fieldTabIndex = fieldEntry.Link
-			if fieldTabIndex == -1 {
-				break
-			}
-		}
-
-		if !found {
-			sa.addError(fmt.Sprintf("Record '%s' has no field '%s'", varNode.Name, fieldName))
 		}
 	}
 
+	sa.addError(diagnostic.InvalidField, "Record '%s' has no field '%s'", varNode.Name, fieldName)
+	varNode.Type = TypeNone
 	return varNode
 }
 
 // Visit IDENTIFIER node
 // Semantic rule: Creates VarNode with symbol table lookup
 func (sa *SemanticAnalyzer) visitIdentifier(node *milestone2.AbstractSyntaxTree) *VarNode {
-	varName := extractValue(node.Value)
+	defer sa.at(node)()
+	varName := node.Text
 	varNode := NewVarNode(varName)
 
 	// Look up in symbol table
 	tabIndex, found := sa.SymTable.Lookup(varName)
 	if !found {
-		sa.addError(fmt.Sprintf("Undefined identifier '%s'", varName))
+		sa.addError(diagnostic.UndeclaredIdent, "Undefined identifier '%s'", varName)
 		return varNode
 	}
 
@@ -938,23 +1079,22 @@ func (sa *SemanticAnalyzer) visitIdentifier(node *milestone2.AbstractSyntaxTree)
 // Visit <procedure-call> node
 // Semantic rule: procedure_call.node = new ProcCallNode(ID.lexeme, params.nodes)
 func (sa *SemanticAnalyzer) visitProcedureCall(node *milestone2.AbstractSyntaxTree) *ProcCallNode {
+	defer sa.at(node)()
 	var procName string
 	arguments := make([]DecoratedNode, 0)
+	var expressions []*milestone2.AbstractSyntaxTree // Parse node of every argument
 
 	// Extract procedure name and arguments
 	for _, child := range node.Children {
-		if strings.Contains(child.Value, "IDENTIFIER") || strings.Contains(child.Value, "writeln") || strings.Contains(child.Value, "write") {
-			if strings.Contains(child.Value, "IDENTIFIER") {
-				procName = extractValue(child.Value)
-			} else {
-				procName = extractValue(child.Value)
-			}
-		} else if child.Value == "<parameter-list>" {
+		if child.IsToken(milestone1.KindIdentifier) {
+			procName = child.Text
+		} else if child.Kind == milestone2.NodeParameterList {
 			// Extract arguments from parameter list
 			for _, paramChild := range child.Children {
-				if paramChild.Value == "<expression>" {
+				if paramChild.Kind == milestone2.NodeExpression {
 					if arg := sa.visitExpression(paramChild); arg != nil {
 						arguments = append(arguments, arg)
+						expressions = append(expressions, paramChild)
 					}
 				}
 			}
@@ -963,31 +1103,35 @@ func (sa *SemanticAnalyzer) visitProcedureCall(node *milestone2.AbstractSyntaxTr
 
 	procCall := NewProcCallNode(procName, arguments)
 
-	// Check if built-in
-	builtIns := map[string]bool{
-		"write": true, "writeln": true, "read": true, "readln": true,
+	// Look up in symbol table
+	tabIndex, found := sa.SymTable.LookupSubprogram(procName)
+	if !found {
+		tabIndex, found = sa.SymTable.Lookup(procName)
 	}
-
-	if builtIns[procName] {
-		procCall.IsBuiltIn = true
-		procCall.TabIndex = -1
-	} else {
-		// Look up in symbol table
-		if tabIndex, found := sa.SymTable.Lookup(procName); found {
-			entry, _ := sa.SymTable.GetEntry(tabIndex)
-			if entry != nil {
-				if entry.Obj != ObjProcedure && entry.Obj != ObjFunction {
-					sa.addError(fmt.Sprintf("'%s' is not a procedure", procName))
-				}
-				procCall.TabIndex = tabIndex
-				procCall.Type = entry.Type
-
-				// Parameter type checking
-				sa.checkProcedureArguments(procName, entry, arguments)
+	if found {
+		entry, _ := sa.SymTable.GetEntry(tabIndex)
+		if entry != nil && sa.SymTable.IsStandard(tabIndex) {
+			// Predeclared write/writeln/read/readln (or a standard function misused as a statement)
+			procCall.TabIndex = tabIndex
+			if entry.Obj == ObjFunction {
+				sa.addError(diagnostic.WrongIdentKind, "Standard function '%s' cannot be called as a procedure", procName)
+			} else {
+				sa.checkStdProcedure(procCall)
+				sa.recordEnumWrites(procCall, expressions)
 			}
-		} else {
-			sa.addError(fmt.Sprintf("Undefined procedure '%s'", procName))
+		} else if entry != nil {
+			if entry.Obj != ObjProcedure && entry.Obj != ObjFunction {
+				sa.addError(diagnostic.WrongIdentKind, "'%s' is not a procedure", procName).
+					WithNote(nil, "'%s' is declared as a %s", procName, entry.Obj)
+			}
+			procCall.TabIndex = tabIndex
+			procCall.Type = entry.Type
+
+			// Parameter type checking
+			sa.checkProcedureArguments(procCall, entry)
 		}
+	} else {
+		sa.addError(diagnostic.UndeclaredIdent, "Undefined procedure '%s'", procName)
 	}
 
 	return procCall
@@ -996,17 +1140,18 @@ func (sa *SemanticAnalyzer) visitProcedureCall(node *milestone2.AbstractSyntaxTr
 // Visit <function-call> node (for function calls in expressions)
 // Semantic rule: function_call.node = new ProcCallNode(ID.lexeme, params.nodes)
 func (sa *SemanticAnalyzer) visitFunctionCall(node *milestone2.AbstractSyntaxTree) *ProcCallNode {
+	defer sa.at(node)()
 	var funcName string
 	arguments := make([]DecoratedNode, 0)
 
 	// Extract function name and arguments
 	for _, child := range node.Children {
-		if strings.Contains(child.Value, "IDENTIFIER") {
-			funcName = extractValue(child.Value)
-		} else if child.Value == "<parameter-list>" {
+		if child.IsToken(milestone1.KindIdentifier) {
+			funcName = child.Text
+		} else if child.Kind == milestone2.NodeParameterList {
 			// Process parameter list (expressions separated by commas)
 			for _, exprChild := range child.Children {
-				if exprChild.Value == "<expression>" {
+				if exprChild.Kind == milestone2.NodeExpression {
 					arg := sa.visitExpression(exprChild)
 					arguments = append(arguments, arg)
 				}
@@ -1014,31 +1159,60 @@ func (sa *SemanticAnalyzer) visitFunctionCall(node *milestone2.AbstractSyntaxTre
 		}
 	}
 
-	funcCall := NewProcCallNode(funcName, arguments)
+	return sa.resolveFunctionCall(NewProcCallNode(funcName, arguments))
+}
+
+// Resolve a function call against the symbol table and check its arguments
+func (sa *SemanticAnalyzer) resolveFunctionCall(funcCall *ProcCallNode) *ProcCallNode {
+	funcName := funcCall.Name
 
-	// Look up in symbol table
-	tabIndex, found := sa.SymTable.Lookup(funcName)
+	// Look up in symbol table (skip the implicit result variable for recursive calls)
+	tabIndex, found := sa.SymTable.LookupSubprogram(funcName)
 	if !found {
-		sa.addError(fmt.Sprintf("Undefined function '%s'", funcName))
+		tabIndex, found = sa.SymTable.Lookup(funcName)
+	}
+	if !found {
+		sa.addError(diagnostic.UndeclaredIdent, "Undefined function '%s'", funcName)
 	} else {
 		entry, _ := sa.SymTable.GetEntry(tabIndex)
 		if entry != nil {
 			if entry.Obj != ObjFunction {
-				sa.addError(fmt.Sprintf("'%s' is not a function", funcName))
+				sa.addError(diagnostic.WrongIdentKind, "'%s' is not a function", funcName).
+					WithNote(nil, "'%s' is declared as a %s", funcName, entry.Obj)
 			}
 			funcCall.TabIndex = tabIndex
 			funcCall.Type = entry.Type // Function return type
 
-			// Parameter type checking
-			sa.checkProcedureArguments(funcName, entry, arguments)
+			if sa.SymTable.IsStandard(tabIndex) && entry.Obj == ObjFunction {
+				sa.checkStdFunction(funcCall, entry)
+			} else {
+				funcCall.Ref = entry.Rref // Enumeration/subrange of the result
+				// Parameter type checking
+				sa.checkProcedureArguments(funcCall, entry)
+			}
 		}
 	}
 
 	return funcCall
 }
 
+// A bare identifier in an expression that names a function is a call without arguments (e.g. eof, eoln)
+func (sa *SemanticAnalyzer) parameterlessCall(node *milestone2.AbstractSyntaxTree) *ProcCallNode {
+	if len(node.Children) != 1 || !node.Children[0].IsToken(milestone1.KindIdentifier) {
+		return nil
+	}
+	name := node.Children[0].Text
+	tabIndex, found := sa.SymTable.Lookup(name)
+	if !found || sa.SymTable.Tab[tabIndex].Obj != ObjFunction {
+		return nil
+	}
+	defer sa.at(node)()
+	return sa.resolveFunctionCall(NewProcCallNode(name, make([]DecoratedNode, 0)))
+}
+
 // Visit <if-statement> node
 func (sa *SemanticAnalyzer) visitIfStatement(node *milestone2.AbstractSyntaxTree) *IfNode {
+	defer sa.at(node)()
 	ifNode := &IfNode{
 		BaseDecoratedNode: BaseDecoratedNode{
 			TabIndex: -1,
@@ -1049,21 +1223,25 @@ func (sa *SemanticAnalyzer) visitIfStatement(node *milestone2.AbstractSyntaxTree
 		},
 	}
 
-	// Extract condition and statements
+	// Extract condition and statements (a statement after selain_itu is the else branch, even if
+	// the then branch is empty)
+	inElse := false
 	for _, child := range node.Children {
-		if child.Value == "<expression>" && ifNode.Condition == nil {
+		if child.IsKeyword(milestone1.KwElse) {
+			inElse = true
+		} else if child.Kind == milestone2.NodeExpression && ifNode.Condition == nil {
 			ifNode.Condition = sa.visitExpression(child)
 
 			// Type check: condition must be boolean
 			condType := sa.getNodeType(ifNode.Condition)
 			if condType != TypeBoolean {
-				sa.addError("If condition must be boolean type")
+				sa.addError(diagnostic.NonBooleanCond, "If condition must be boolean type")
 			}
-		} else if child.Value == "<statement>" || child.Value == "<compound-statement>" {
-			if ifNode.ThenStmt == nil {
-				ifNode.ThenStmt = sa.visitStatement(child)
-			} else if ifNode.ElseStmt == nil {
+		} else if isStatementNode(child) {
+			if inElse {
 				ifNode.ElseStmt = sa.visitStatement(child)
+			} else {
+				ifNode.ThenStmt = sa.visitStatement(child)
 			}
 		}
 	}
@@ -1073,6 +1251,7 @@ func (sa *SemanticAnalyzer) visitIfStatement(node *milestone2.AbstractSyntaxTree
 
 // Visit <while-statement> node
 func (sa *SemanticAnalyzer) visitWhileStatement(node *milestone2.AbstractSyntaxTree) *WhileNode {
+	defer sa.at(node)()
 	whileNode := &WhileNode{
 		BaseDecoratedNode: BaseDecoratedNode{
 			TabIndex: -1,
@@ -1085,15 +1264,15 @@ func (sa *SemanticAnalyzer) visitWhileStatement(node *milestone2.AbstractSyntaxT
 
 	// Extract condition and body
 	for _, child := range node.Children {
-		if child.Value == "<expression>" {
+		if child.Kind == milestone2.NodeExpression {
 			whileNode.Condition = sa.visitExpression(child)
 
 			// Type check: condition must be boolean
 			condType := sa.getNodeType(whileNode.Condition)
 			if condType != TypeBoolean {
-				sa.addError("While condition must be boolean type")
+				sa.addError(diagnostic.NonBooleanCond, "While condition must be boolean type")
 			}
-		} else if child.Value == "<statement>" || child.Value == "<compound-statement>" {
+		} else if isStatementNode(child) {
 			whileNode.Body = sa.visitStatement(child)
 		}
 	}
@@ -1101,12 +1280,191 @@ func (sa *SemanticAnalyzer) visitWhileStatement(node *milestone2.AbstractSyntaxT
 	return whileNode
 }
 
+// Visit <repeat-statement> node
+// Semantic rules:
+// - Body is a statement sequence (no mulai..selesai needed)
+// - Until condition must be boolean type
+func (sa *SemanticAnalyzer) visitRepeatStatement(node *milestone2.AbstractSyntaxTree) *RepeatNode {
+	defer sa.at(node)()
+	repeatNode := &RepeatNode{
+		BaseDecoratedNode: BaseDecoratedNode{
+			TabIndex: -1,
+			Type:     TypeNone,
+			Ref:      -1,
+			Errors:   make([]string, 0),
+			Warnings: make([]string, 0),
+		},
+	}
+
+	for _, child := range node.Children {
+		switch child.Kind {
+		case milestone2.NodeStatementList:
+			body := NewBlockNode(sa.visitStatementList(child))
+			body.BlockIndex = sa.SymTable.CurrentBlock
+			body.Level = sa.SymTable.CurrentLevel
+			repeatNode.Body = body
+		case milestone2.NodeExpression:
+			repeatNode.Condition = sa.visitExpression(child)
+
+			// Type check: condition must be boolean
+			condType := sa.getNodeType(repeatNode.Condition)
+			if condType != TypeBoolean {
+				sa.addError(diagnostic.NonBooleanCond, "Repeat-until condition must be boolean type")
+			}
+		}
+	}
+
+	return repeatNode
+}
+
+// Visit <case-statement> node
+// Semantic rules:
+// - Selector must be an ordinal type (integer, char, boolean)
+// - Labels must be constants compatible with the selector type
+// - A label value may appear only once in the whole statement
+func (sa *SemanticAnalyzer) visitCaseStatement(node *milestone2.AbstractSyntaxTree) *CaseNode {
+	defer sa.at(node)()
+	caseNode := &CaseNode{
+		BaseDecoratedNode: BaseDecoratedNode{
+			TabIndex: -1,
+			Type:     TypeNone,
+			Ref:      -1,
+			Errors:   make([]string, 0),
+			Warnings: make([]string, 0),
+		},
+		Arms: make([]*CaseArm, 0),
+	}
+
+	selectorType := TypeNone
+	seen := make(map[int]diagnostic.Span)
+	for i, child := range node.Children {
+		switch child.Kind {
+		case milestone2.NodeExpression:
+			caseNode.Selector = sa.visitExpression(child)
+			selectorType = sa.getNodeType(caseNode.Selector)
+			if !isOrdinalType(selectorType) {
+				sa.addError(diagnostic.InvalidSelector, "Case selector must be of ordinal type (integer, char, boolean or enumeration), got %s", selectorType)
+				selectorType = TypeNone
+			}
+		case milestone2.NodeCaseElement:
+			caseNode.Arms = append(caseNode.Arms, sa.visitCaseElement(child, selectorType, caseNode.Selector.GetRef(), seen))
+		case milestone2.NodeStatementList:
+			// selain_itu <statement-list>
+			if i > 0 && node.Children[i-1].IsKeyword(milestone1.KwElse) {
+				elseBlock := NewBlockNode(sa.visitStatementList(child))
+				elseBlock.BlockIndex = sa.SymTable.CurrentBlock
+				elseBlock.Level = sa.SymTable.CurrentLevel
+				caseNode.Else = elseBlock
+			}
+		}
+	}
+
+	return caseNode
+}
+
+// Visit <case-element> node: labels are checked against the selector type and the labels seen so far
+func (sa *SemanticAnalyzer) visitCaseElement(node *milestone2.AbstractSyntaxTree, selectorType TypeKind, selectorRef int, seen map[int]diagnostic.Span) *CaseArm {
+	defer sa.at(node)()
+	arm := &CaseArm{Labels: make([]int, 0)}
+
+	for _, child := range node.Children {
+		if child.Kind == milestone2.NodeCaseLabelList {
+			for _, labelNode := range child.Children {
+				if labelNode.Kind != milestone2.NodeCaseLabel {
+					continue
+				}
+				value, ok := sa.visitCaseLabel(labelNode, selectorType, selectorRef)
+				if !ok {
+					continue
+				}
+				restore := sa.at(labelNode)
+				if first, dup := seen[value]; dup {
+					sa.addError(diagnostic.DuplicateLabel, "Duplicate case label %s", formatOrdinal(value, selectorType)).
+						WithNote(&first, "first used here")
+				} else {
+					seen[value] = sa.currentSpan()
+					arm.Labels = append(arm.Labels, value)
+				}
+				restore()
+			}
+		} else if isStatementNode(child) {
+			arm.Body = sa.visitStatement(child)
+		}
+	}
+
+	return arm
+}
+
+// Evaluate a <case-label> node to its ordinal value
+// Returns false if the label is invalid (the error has already been reported)
+func (sa *SemanticAnalyzer) visitCaseLabel(node *milestone2.AbstractSyntaxTree, selectorType TypeKind, selectorRef int) (int, bool) {
+	defer sa.at(node)()
+
+	sign := 1
+	var value int
+	var typ TypeKind
+	ref := -1
+	for _, child := range node.Children {
+		token := child.Text
+		switch {
+		case child.IsToken(milestone1.KindArithmeticOperator):
+			if token == "-" {
+				sign = -1
+			}
+			continue
+		case child.IsToken(milestone1.KindNumber):
+			value, _ = strconv.Atoi(token)
+			typ = TypeInteger
+		case child.IsToken(milestone1.KindCharLiteral), child.IsToken(milestone1.KindStringLiteral):
+			content := unquote(token)
+			if len(content) != 1 {
+				sa.addError(diagnostic.InvalidCaseLabel, "Case label %s is not a single character", token)
+				return 0, false
+			}
+			value, typ = int(content[0]), TypeChar
+		case child.IsKeyword(milestone1.KwTrue):
+			value, typ = 1, TypeBoolean
+		case child.IsKeyword(milestone1.KwFalse):
+			value, typ = 0, TypeBoolean
+		case child.IsToken(milestone1.KindIdentifier):
+			tabIndex, found := sa.SymTable.Lookup(token)
+			if !found {
+				sa.addError(diagnostic.UndeclaredIdent, "Undefined identifier '%s'", token)
+				return 0, false
+			}
+			entry, _ := sa.SymTable.GetEntry(tabIndex)
+			if entry == nil || entry.Obj != ObjConstant {
+				sa.addError(diagnostic.InvalidCaseLabel, "Case label '%s' is not a constant", token)
+				return 0, false
+			}
+			value, typ, ref = entry.Adr, entry.Type, entry.Ref
+		}
+	}
+
+	if sign < 0 && typ != TypeInteger {
+		sa.addError(diagnostic.InvalidCaseLabel, "Sign is only allowed on integer case labels")
+		return 0, false
+	}
+	value *= sign
+
+	if selectorType != TypeNone && typ != selectorType {
+		sa.addError(diagnostic.InvalidCaseLabel, "Case label of type %s is not compatible with selector of type %s", typ, selectorType)
+		return 0, false
+	}
+	if selectorType == TypeEnum && !sa.sameCompositeType(TypeEnum, selectorRef, ref) {
+		sa.addError(diagnostic.InvalidCaseLabel, "Case label is a value of a different enumeration than the selector")
+		return 0, false
+	}
+	return value, true
+}
+
 // Visit <for-statement> node
 // Semantic rules:
 // - Loop variable must be declared
-// - Start and end expressions must be integer type
-// - Loop variable must be integer type
+// - Loop variable must be an ordinal type (integer, char, boolean, enumeration)
+// - Start and end expressions must have the type of the loop variable
 func (sa *SemanticAnalyzer) visitForStatement(node *milestone2.AbstractSyntaxTree) *ForNode {
+	defer sa.at(node)()
 	forNode := &ForNode{
 		BaseDecoratedNode: BaseDecoratedNode{
 			TabIndex: -1,
@@ -1125,18 +1483,18 @@ func (sa *SemanticAnalyzer) visitForStatement(node *milestone2.AbstractSyntaxTre
 	var body DecoratedNode
 
 	for _, child := range node.Children {
-		if strings.Contains(child.Value, "IDENTIFIER") {
-			loopVarName = extractValue(child.Value)
-		} else if child.Value == "<expression>" {
+		if child.IsToken(milestone1.KindIdentifier) {
+			loopVarName = child.Text
+		} else if isStatementNode(child) {
+			body = sa.visitStatement(child)
+		} else if child.Kind == milestone2.NodeExpression {
 			if startExpr == nil {
 				startExpr = sa.visitExpression(child)
 			} else {
 				endExpr = sa.visitExpression(child)
 			}
-		} else if strings.Contains(child.Value, "ke") || strings.Contains(child.Value, "turun_ke") {
-			direction = extractValue(child.Value)
-		} else if child.Value == "<statement>" {
-			body = sa.visitStatement(child)
+		} else if child.IsKeyword(milestone1.KwTo) || child.IsKeyword(milestone1.KwDownTo) {
+			direction = child.Text
 		}
 	}
 
@@ -1144,15 +1502,15 @@ func (sa *SemanticAnalyzer) visitForStatement(node *milestone2.AbstractSyntaxTre
 	if loopVarName != "" {
 		tabIndex, found := sa.SymTable.Lookup(loopVarName)
 		if !found {
-			sa.addError(fmt.Sprintf("Loop variable '%s' is not declared", loopVarName))
+			sa.addError(diagnostic.UndeclaredIdent, "Loop variable '%s' is not declared", loopVarName)
 		} else {
 			entry, _ := sa.SymTable.GetEntry(tabIndex)
 			if entry != nil {
-				if entry.Type != TypeInteger {
-					sa.addError(fmt.Sprintf("Loop variable '%s' must be integer type", loopVarName))
+				if !isOrdinalType(entry.Type) {
+					sa.addError(diagnostic.InvalidForLoop, "Loop variable '%s' must be an ordinal type (integer, char, boolean or enumeration), got %s", loopVarName, entry.Type)
 				}
 				if entry.Obj != ObjVariable {
-					sa.addError(fmt.Sprintf("Loop counter '%s' must be a variable", loopVarName))
+					sa.addError(diagnostic.WrongIdentKind, "Loop counter '%s' must be a variable", loopVarName)
 				}
 				forNode.TabIndex = tabIndex
 
@@ -1160,23 +1518,29 @@ func (sa *SemanticAnalyzer) visitForStatement(node *milestone2.AbstractSyntaxTre
 				loopVarNode := NewVarNode(loopVarName)
 				loopVarNode.TabIndex = tabIndex
 				loopVarNode.Type = entry.Type
+				loopVarNode.Ref = entry.Ref
 				loopVarNode.Level = entry.Lev
+				loopVarNode.Address = entry.Adr
 				forNode.Variable = loopVarNode
 			}
 		}
 	}
 
-	// Type check expressions and set remaining fields
+	// Type check expressions against the loop variable and set remaining fields
+	loopType, loopRef := TypeInteger, -1
+	if forNode.Variable != nil && isOrdinalType(forNode.Variable.GetType()) {
+		loopType, loopRef = forNode.Variable.GetType(), forNode.Variable.GetRef()
+	}
 	if startExpr != nil {
-		if sa.getNodeType(startExpr) != TypeInteger {
-			sa.addError("FOR loop start expression must be integer type")
+		if !sa.ordinalMatches(loopType, loopRef, startExpr) {
+			sa.addError(diagnostic.InvalidForLoop, "FOR loop start expression must be %s type, got %s", loopType, sa.getNodeType(startExpr))
 		}
 		forNode.StartValue = startExpr
 	}
 
 	if endExpr != nil {
-		if sa.getNodeType(endExpr) != TypeInteger {
-			sa.addError("FOR loop end expression must be integer type")
+		if !sa.ordinalMatches(loopType, loopRef, endExpr) {
+			sa.addError(diagnostic.InvalidForLoop, "FOR loop end expression must be %s type, got %s", loopType, sa.getNodeType(endExpr))
 		}
 		forNode.EndValue = endExpr
 	}
@@ -1190,7 +1554,9 @@ func (sa *SemanticAnalyzer) visitForStatement(node *milestone2.AbstractSyntaxTre
 // ========== HELPER FUNCTIONS ==========
 
 // Check procedure/function arguments match parameters
-func (sa *SemanticAnalyzer) checkProcedureArguments(name string, entry *TabEntry, arguments []DecoratedNode) {
+// Var parameters are recorded in call.ByRef so backends pass their address
+func (sa *SemanticAnalyzer) checkProcedureArguments(call *ProcCallNode, entry *TabEntry) {
+	name, arguments := call.Name, call.Arguments
 	if entry.Ref < 0 || entry.Ref >= len(sa.SymTable.Btab) {
 		return // No BTAB entry, cannot validate
 	}
@@ -1201,8 +1567,8 @@ func (sa *SemanticAnalyzer) checkProcedureArguments(name string, entry *TabEntry
 	params := []TabEntry{}
 	paramIdx := btabEntry.Lpar
 
-	// Traverse parameter chain
-	for paramIdx >= 0 && paramIdx < btabEntry.Last {
+	// Traverse parameter chain (from the last parameter back to the first)
+	for paramIdx >= 0 && paramIdx <= btabEntry.Last {
 		if paramIdx >= len(sa.SymTable.Tab) {
 			break
 		}
@@ -1210,7 +1576,7 @@ func (sa *SemanticAnalyzer) checkProcedureArguments(name string, entry *TabEntry
 
 		// Parameters are ObjVariable at procedure's level + 1
 		if paramEntry.Obj == ObjVariable && paramEntry.Lev == entry.Lev+1 {
-			params = append(params, paramEntry)
+			params = append([]TabEntry{paramEntry}, params...)
 		}
 
 		// Follow link
@@ -1222,31 +1588,96 @@ func (sa *SemanticAnalyzer) checkProcedureArguments(name string, entry *TabEntry
 
 	// Check argument count
 	if len(arguments) != len(params) {
-		sa.addError(fmt.Sprintf("'%s' expects %d argument(s) but got %d", name, len(params), len(arguments)))
+		sa.addError(diagnostic.ArgumentCount, "'%s' expects %d argument(s) but got %d", name, len(params), len(arguments))
 		return
 	}
 
 	// Check each argument's type
+	call.ByRef = make([]bool, len(arguments))
 	for i := 0; i < len(arguments) && i < len(params); i++ {
 		argType := sa.getNodeType(arguments[i])
 		paramType := params[i].Type
+		formal := sa.SymTable.TypeName(paramType, params[i].Ref)
+		actual := sa.SymTable.TypeName(argType, arguments[i].GetRef())
 
-		// Type compatibility check
-		if !sa.typesCompatible(argType, paramType) {
-			sa.addError(fmt.Sprintf("Argument %d of '%s': type mismatch (expected %s, got %s)",
-				i+1, name, paramType.String(), argType.String()))
+		// Type compatibility check (var parameters alias the argument, so no conversion is allowed)
+		if params[i].Nrm == 0 {
+			call.ByRef[i] = true
+			if argType != TypeNone && (argType != paramType || !sa.identicalType(arguments[i], params[i])) {
+				sa.addError(diagnostic.ArgumentMismatch, "Argument %d of '%s': var parameter needs a variable of type %s, got %s",
+					i+1, name, formal, actual).
+					WithNote(nil, "parameter '%s' is declared with 'variabel'", params[i].Identifier)
+				continue
+			}
+		} else if !sa.typesCompatible(paramType, argType) || (argType == paramType && !sa.sameStructure(arguments[i], params[i])) {
+			sa.addError(diagnostic.ArgumentMismatch, "Argument %d of '%s': type mismatch (expected %s, got %s)",
+				i+1, name, formal, actual).
+				WithNote(nil, "parameter '%s' is declared as %s", params[i].Identifier, formal)
 		}
 
 		// Check var parameter constraint (nrm == 0 means var parameter)
 		if params[i].Nrm == 0 {
 			// Var parameter requires an L-value (assignable variable)
 			if !sa.isLValue(arguments[i]) {
-				sa.addError(fmt.Sprintf("Argument %d of '%s': var parameter requires a variable, not an expression", i+1, name))
+				sa.addError(diagnostic.ArgumentMismatch, "Argument %d of '%s': var parameter requires a variable, not an expression", i+1, name).
+					WithNote(nil, "parameter '%s' is declared with 'variabel'", params[i].Identifier)
 			}
 		}
 	}
 }
 
+// Var parameters alias the argument, so the types must be identical:
+// besides the structure, a subrange only matches the same subrange (not its host type)
+func (sa *SemanticAnalyzer) identicalType(arg DecoratedNode, param TabEntry) bool {
+	switch param.Type {
+	case TypeInteger, TypeChar, TypeEnum:
+		return arg.GetRef() == param.Ref
+	}
+	return sa.sameStructure(arg, param)
+}
+
+// Array/record arguments must have the same structure as the parameter, enum arguments the same enumeration
+func (sa *SemanticAnalyzer) sameStructure(arg DecoratedNode, param TabEntry) bool {
+	switch param.Type {
+	case TypeArray, TypeRecord:
+		varNode, ok := arg.(*VarNode)
+		return ok && sa.sameCompositeType(param.Type, param.Ref, varNode.Ref)
+	case TypeEnum:
+		return sa.sameCompositeType(TypeEnum, param.Ref, arg.GetRef())
+	}
+	return true
+}
+
+// Arrays are equivalent when every dimension has the same bounds and element type,
+// records only when they are the same record type (same BTAB entry),
+// enum values when they come from the same enumeration (subranges included)
+func (sa *SemanticAnalyzer) sameCompositeType(typ TypeKind, ref1, ref2 int) bool {
+	switch typ {
+	case TypeArray:
+		if ref1 == ref2 {
+			return true
+		}
+		if ref1 < 0 || ref2 < 0 || ref1 >= len(sa.SymTable.Atab) || ref2 >= len(sa.SymTable.Atab) {
+			return false
+		}
+		a, b := sa.SymTable.Atab[ref1], sa.SymTable.Atab[ref2]
+		return a.Low == b.Low && a.High == b.High && a.Etyp == b.Etyp &&
+			sa.sameCompositeType(TypeKind(a.Etyp), a.Eref, b.Eref)
+	case TypeRecord:
+		return ref1 == ref2
+	case TypeEnum:
+		return sa.SymTable.EnumBase(ref1) == sa.SymTable.EnumBase(ref2)
+	default:
+		return true
+	}
+}
+
+// Variable whose type could not be resolved (undefined name or a constant in error, already reported)
+func isErrorVar(node DecoratedNode) bool {
+	varNode, ok := node.(*VarNode)
+	return ok && varNode.Type == TypeNone
+}
+
 // Check if a node represents an L-value (can be assigned to)
 func (sa *SemanticAnalyzer) isLValue(node DecoratedNode) bool {
 	switch n := node.(type) {
@@ -1259,15 +1690,24 @@ func (sa *SemanticAnalyzer) isLValue(node DecoratedNode) bool {
 
 // Process type node
 func (sa *SemanticAnalyzer) processType(node *milestone2.AbstractSyntaxTree) (TypeKind, int) {
-	if node.Value == "<array-type>" {
+	defer sa.at(node)()
+	if node.Kind == milestone2.NodeArrayType {
 		return sa.processArrayType(node)
 	}
 
-	if node.Value == "<record-type>" {
+	if node.Kind == milestone2.NodeRecordType {
 		return sa.processRecordType(node)
 	}
 
-	if node.Value != "<type>" {
+	if node.Kind == milestone2.NodeEnumType {
+		return sa.processEnumType(node)
+	}
+
+	if node.Kind == milestone2.NodeSubrangeType {
+		return sa.processSubrangeType(node)
+	}
+
+	if node.Kind != milestone2.NodeType {
 		return TypeNone, -1
 	}
 
@@ -1277,120 +1717,198 @@ func (sa *SemanticAnalyzer) processType(node *milestone2.AbstractSyntaxTree) (Ty
 
 	child := node.Children[0]
 
-	if strings.Contains(child.Value, "KEYWORD") {
-		typeStr := extractValue(child.Value)
-		switch typeStr {
-		case "integer":
+	if child.IsToken(milestone1.KindKeyword) {
+		switch child.Keyword {
+		case milestone1.KwInteger:
 			return TypeInteger, -1
-		case "real":
+		case milestone1.KwReal:
 			return TypeReal, -1
-		case "boolean":
+		case milestone1.KwBoolean:
 			return TypeBoolean, -1
-		case "char":
+		case milestone1.KwChar:
 			return TypeChar, -1
+		case milestone1.KwString:
+			return TypeString, -1
 		}
 	}
 
-	if child.Value == "<array-type>" {
+	if child.Kind == milestone2.NodeArrayType {
 		return sa.processArrayType(child)
 	}
 
-	if child.Value == "<record-type>" {
+	if child.Kind == milestone2.NodeRecordType {
 		return sa.processRecordType(child)
 	}
 
-	if strings.Contains(child.Value, "IDENTIFIER") {
-		typeName := extractValue(child.Value)
+	if child.IsToken(milestone1.KindIdentifier) {
+		typeName := child.Text
 		idx, found := sa.SymTable.Lookup(typeName)
 		if found && sa.SymTable.Tab[idx].Obj == ObjType {
 			return sa.SymTable.Tab[idx].Type, sa.SymTable.Tab[idx].Ref
 		}
-		sa.addError(fmt.Sprintf("Undefined type '%s'", typeName))
+		sa.addError(diagnostic.UndeclaredIdent, "Undefined type '%s'", typeName)
 	}
 
 	return TypeNone, -1
 }
 
+// One dimension of an array type: index type and its bounds
+type arrayDimension struct {
+	xtyp      TypeKind
+	xref      int
+	low, high int
+}
+
 // Process array type
+// Each dimension is a constant range (1..N, 'a'..'z', Senin..Rabu) or an ordinal type name (Hari, Nilai, char)
 func (sa *SemanticAnalyzer) processArrayType(node *milestone2.AbstractSyntaxTree) (TypeKind, int) {
-	low, high := 0, 0
-	lowIsConst, highIsConst := false, false
+	defer sa.at(node)()
+	dims := make([]arrayDimension, 0, 1)
 	var elementTypeNode *milestone2.AbstractSyntaxTree
 
-	for i, child := range node.Children {
-		if strings.Contains(child.Value, "NUMBER") {
-			// Literal number - always a constant
-			numVal, err := strconv.Atoi(extractValue(child.Value))
-			if err != nil {
-				sa.addError(fmt.Sprintf("Invalid array bound: %s", extractValue(child.Value)))
-				continue
-			}
-
-			if !lowIsConst {
-				low = numVal
-				lowIsConst = true
-			} else {
-				high = numVal
-				highIsConst = true
-			}
-		} else if strings.Contains(child.Value, "IDENTIFIER") {
-			// Identifier - must be a declared constant
-			constName := extractValue(child.Value)
-			tabIndex, found := sa.SymTable.Lookup(constName)
-
-			if !found {
-				sa.addError(fmt.Sprintf("Undefined constant '%s' in array bounds", constName))
-				continue
-			}
-
-			constEntry := sa.SymTable.Tab[tabIndex]
-			if constEntry.Obj != ObjConstant {
-				sa.addError(fmt.Sprintf("Array bounds must be constants (not variable '%s')", constName))
-				continue
-			}
-
-			// Use constant value from Adr field
-			constVal := constEntry.Adr
-			if !lowIsConst {
-				low = constVal
-				lowIsConst = true
-			} else {
-				high = constVal
-				highIsConst = true
-			}
-		} else if child.Value == "<type>" {
-			elementTypeNode = child
-		} else if i == len(node.Children)-1 {
+	valid := true
+	children := node.Children
+	for i := 0; i < len(children); i++ {
+		child := children[i]
+		switch {
+		case child.Kind == milestone2.NodeIndexType:
+			dim, ok := sa.processIndexType(child)
+			valid = valid && ok
+			dims = append(dims, dim)
+		case child.Kind == milestone2.NodeExpression && i+2 < len(children) && children[i+2].Kind == milestone2.NodeExpression:
+			// Range low .. high (the range operator sits between the two bounds)
+			low, high, typ, ref, ok := sa.processRange(child, children[i+2], diagnostic.InvalidArrayBound)
+			valid = valid && ok
+			dims = append(dims, arrayDimension{xtyp: typ, xref: ref, low: low, high: high})
+			i += 2
+		case child.Kind == milestone2.NodeType || i == len(children)-1:
 			elementTypeNode = child
 		}
 	}
 
-	// Validate bounds were found
-	if !lowIsConst || !highIsConst {
-		sa.addError("Array bounds must be compile-time constants")
-		low, high = 0, 0
+	// Errors in the bounds themselves are already reported
+	if !valid {
+		return TypeArray, -1
 	}
-
-	// Validate bounds make sense
-	if low > high {
-		sa.addError(fmt.Sprintf("Array lower bound (%d) cannot be greater than upper bound (%d)", low, high))
+	if len(dims) == 0 {
+		sa.addError(diagnostic.InvalidArrayBound, "Array bounds must be compile-time constants")
+		return TypeArray, -1
 	}
 
 	if elementTypeNode == nil {
 		return TypeArray, -1
 	}
 
+	// larik [a..b, c..d] dari T = larik [a..b] dari larik [c..d] dari T:
+	// ATAB entries are chained from the last dimension outwards through Eref
 	elemType, elemRef := sa.processType(elementTypeNode)
-	elemSize := sa.SymTable.getTypeSize(elemType, elemRef)
+	for i := len(dims) - 1; i >= 0; i-- {
+		elemSize := sa.SymTable.getTypeSize(elemType, elemRef)
+		elemRef = sa.SymTable.EnterArray(int(dims[i].xtyp), dims[i].xref, int(elemType), elemRef, dims[i].low, dims[i].high, elemSize)
+		elemType = TypeArray
+	}
+
+	return TypeArray, elemRef
+}
+
+// Evaluate the bounds of a range (array dimension or subrange type)
+// Both bounds must be ordinal constants of the same type with low <= high
+// ref is the RTAB index of the enumeration for enum ranges, -1 otherwise
+func (sa *SemanticAnalyzer) processRange(lowNode, highNode *milestone2.AbstractSyntaxTree, code diagnostic.Code) (low, high int, typ TypeKind, ref int, ok bool) {
+	low, lowType, lowRef, lowOk := sa.evalOrdinalConstant(lowNode)
+	high, highType, highRef, highOk := sa.evalOrdinalConstant(highNode)
+	if !lowOk || !highOk {
+		return 0, 0, TypeNone, -1, false
+	}
+
+	restore := sa.at(lowNode)
+	defer restore()
+	if lowType != highType || sa.SymTable.EnumBase(lowRef) != sa.SymTable.EnumBase(highRef) {
+		sa.addError(code, "Range bounds have different types (%s and %s)", lowType, highType)
+		return 0, 0, TypeNone, -1, false
+	}
+	if low > high {
+		sa.addError(code, "Lower bound (%d) cannot be greater than upper bound (%d)", low, high)
+		return 0, 0, TypeNone, -1, false
+	}
+	return low, high, lowType, sa.SymTable.EnumBase(lowRef), true
+}
+
+// Array index given as a type name: char, boolean, an enumeration or a subrange
+func (sa *SemanticAnalyzer) processIndexType(node *milestone2.AbstractSyntaxTree) (arrayDimension, bool) {
+	defer sa.at(node)()
+	if len(node.Children) == 0 {
+		return arrayDimension{}, false
+	}
+
+	name := node.Children[0].Text
+	switch node.Children[0].Keyword {
+	case milestone1.KwChar:
+		return arrayDimension{xtyp: TypeChar, xref: -1, low: 0, high: 255}, true
+	case milestone1.KwBoolean:
+		return arrayDimension{xtyp: TypeBoolean, xref: -1, low: 0, high: 1}, true
+	}
+
+	typ, ref := sa.processType(&milestone2.AbstractSyntaxTree{Value: milestone2.NodeType.String(), Kind: milestone2.NodeType, Children: node.Children})
+	if typ == TypeNone {
+		return arrayDimension{}, false // Undefined type, already reported
+	}
+	low, high, bounded := sa.SymTable.RangeBounds(ref)
+	if !bounded || !isOrdinalType(typ) {
+		sa.addError(diagnostic.InvalidArrayBound, "Array index type '%s' must be an enumeration or subrange type", name)
+		return arrayDimension{}, false
+	}
+	return arrayDimension{xtyp: typ, xref: sa.SymTable.EnumBase(ref), low: low, high: high}, true
+}
 
-	atabIndex := sa.SymTable.EnterArray(0, int(elemType), elemRef, low, high, elemSize)
+// Process enumerated type: ( ID, ID, ... )
+// Each value is entered into TAB as a constant of the new enum type with its ordinal as adr
+func (sa *SemanticAnalyzer) processEnumType(node *milestone2.AbstractSyntaxTree) (TypeKind, int) {
+	defer sa.at(node)()
+	var names []string
+	for _, child := range node.Children {
+		if child.Kind == milestone2.NodeIdentifierList {
+			names = sa.extractIdentifierList(child)
+		}
+	}
+	if len(names) == 0 {
+		return TypeNone, -1
+	}
 
-	return TypeArray, atabIndex
+	ref := sa.SymTable.EnterRange(TypeEnum, -1, 0, len(names)-1)
+	for ord, name := range names {
+		if sa.SymTable.IsDeclaredInCurrentScope(name) {
+			sa.addError(diagnostic.DuplicateDecl, "Duplicate declaration of enumeration value '%s'", name)
+			continue
+		}
+		sa.SymTable.Enter(name, ObjConstant, TypeEnum, ref, 1, ord)
+	}
+	return TypeEnum, ref
+}
+
+// Process subrange type: low .. high
+// The type stays its host type (integer, char or enum); the bounds are kept in RTAB
+func (sa *SemanticAnalyzer) processSubrangeType(node *milestone2.AbstractSyntaxTree) (TypeKind, int) {
+	defer sa.at(node)()
+	if len(node.Children) < 3 {
+		return TypeNone, -1
+	}
+
+	low, high, typ, ref, ok := sa.processRange(node.Children[0], node.Children[2], diagnostic.InvalidRange)
+	if !ok {
+		return TypeNone, -1
+	}
+	if typ == TypeBoolean {
+		sa.addError(diagnostic.InvalidRange, "Subrange of boolean is not supported")
+		return TypeNone, -1
+	}
+	return typ, sa.SymTable.EnterRange(typ, ref, low, high)
 }
 
 // Process record type
 // Creates BTAB entry for record and processes field declarations
 func (sa *SemanticAnalyzer) processRecordType(node *milestone2.AbstractSyntaxTree) (TypeKind, int) {
+	defer sa.at(node)()
 	// Create new block for record type
 	oldBlock := sa.SymTable.CurrentBlock
 	oldOffset := sa.CurrentOffset
@@ -1400,7 +1918,7 @@ func (sa *SemanticAnalyzer) processRecordType(node *milestone2.AbstractSyntaxTre
 
 	// Process field-list
 	for _, child := range node.Children {
-		if child.Value == "<field-list>" {
+		if child.Kind == milestone2.NodeFieldList {
 			sa.processFieldList(child, blockIndex)
 			break
 		}
@@ -1419,7 +1937,8 @@ func (sa *SemanticAnalyzer) processRecordType(node *milestone2.AbstractSyntaxTre
 
 // Process field list for record type
 func (sa *SemanticAnalyzer) processFieldList(node *milestone2.AbstractSyntaxTree, blockIndex int) {
-	if node.Value != "<field-list>" {
+	defer sa.at(node)()
+	if node.Kind != milestone2.NodeFieldList {
 		return
 	}
 
@@ -1427,7 +1946,7 @@ func (sa *SemanticAnalyzer) processFieldList(node *milestone2.AbstractSyntaxTree
 	for i := 0; i < len(node.Children); i++ {
 		child := node.Children[i]
 
-		if child.Value == "<identifier-list>" && i+2 < len(node.Children) {
+		if child.Kind == milestone2.NodeIdentifierList && i+2 < len(node.Children) {
 			// Extract field names
 			fieldNames := sa.extractIdentifierList(child)
 
@@ -1469,8 +1988,8 @@ func (sa *SemanticAnalyzer) extractIdentifierList(node *milestone2.AbstractSynta
 	identifiers := make([]string, 0)
 
 	for _, child := range node.Children {
-		if strings.Contains(child.Value, "IDENTIFIER") {
-			identifiers = append(identifiers, extractValue(child.Value))
+		if child.IsToken(milestone1.KindIdentifier) {
+			identifiers = append(identifiers, child.Text)
 		}
 	}
 
@@ -1492,21 +2011,18 @@ type Parameter struct {
 func (sa *SemanticAnalyzer) extractParameters(node *milestone2.AbstractSyntaxTree) []Parameter {
 	params := make([]Parameter, 0)
 
-	if node.Value != "<parameter-list>" {
+	if node.Kind != milestone2.NodeParameterList {
 		return params
 	}
 
-	// Parse pattern: <identifier-list> : <type> (; <identifier-list> : <type>)*
-	// Note: Current parser doesn't distinguish var parameters
-	// If parser is extended to support "variabel" keyword before identifier-list,
-	// set nrm=0 for those parameters
+	// Parse pattern: [variabel] <identifier-list> : <type> (; [variabel] <identifier-list> : <type>)*
 
 	for i := 0; i < len(node.Children); i++ {
 		child := node.Children[i]
 
 		// Check if this is a var parameter (variabel keyword)
 		isVarParam := false
-		if strings.Contains(child.Value, "variabel") {
+		if child.IsKeyword(milestone1.KwVar) {
 			isVarParam = true
 			i++ // Skip to identifier-list
 			if i >= len(node.Children) {
@@ -1515,7 +2031,7 @@ func (sa *SemanticAnalyzer) extractParameters(node *milestone2.AbstractSyntaxTre
 			child = node.Children[i]
 		}
 
-		if child.Value == "<identifier-list>" && i+2 < len(node.Children) {
+		if child.Kind == milestone2.NodeIdentifierList && i+2 < len(node.Children) {
 			// Extract parameter names
 			paramNames := sa.extractIdentifierList(child)
 
@@ -1547,19 +2063,27 @@ func (sa *SemanticAnalyzer) extractParameters(node *milestone2.AbstractSyntaxTre
 	return params
 }
 
-// Extract constant value
-func (sa *SemanticAnalyzer) extractConstValue(tokenValue string) (int, TypeKind) {
-	if strings.Contains(tokenValue, "NUMBER") {
-		valueStr := extractValue(tokenValue)
-		value, _ := strconv.Atoi(valueStr)
+// Extract constant value (int for ordinal types, float64 for real, string for string)
+func (sa *SemanticAnalyzer) extractConstValue(leaf *milestone2.AbstractSyntaxTree) (interface{}, TypeKind) {
+	switch {
+	case leaf.IsToken(milestone1.KindNumber):
+		value, _ := strconv.Atoi(leaf.Text)
 		return value, TypeInteger
-	} else if strings.Contains(tokenValue, "true") || strings.Contains(tokenValue, "false") {
-		if strings.Contains(tokenValue, "true") {
-			return 1, TypeBoolean
+	case leaf.IsToken(milestone1.KindReal):
+		value, _ := strconv.ParseFloat(leaf.Text, 64)
+		return value, TypeReal
+	case leaf.IsToken(milestone1.KindCharLiteral):
+		content := unquote(leaf.Text)
+		if content == "" {
+			return "", TypeString
 		}
+		return int(content[0]), TypeChar
+	case leaf.IsToken(milestone1.KindStringLiteral):
+		return unquote(leaf.Text), TypeString
+	case leaf.IsKeyword(milestone1.KwTrue):
+		return 1, TypeBoolean
+	case leaf.IsKeyword(milestone1.KwFalse):
 		return 0, TypeBoolean
-	} else if strings.Contains(tokenValue, "STRING_LITERAL") {
-		return 0, TypeChar
 	}
 	return 0, TypeNone
 }
@@ -1574,6 +2098,8 @@ func (sa *SemanticAnalyzer) getNodeType(node DecoratedNode) TypeKind {
 	case *RealNode:
 		return TypeReal
 	case *StringNode:
+		return TypeString
+	case *CharNode:
 		return TypeChar
 	case *BooleanNode:
 		return TypeBoolean
@@ -1588,46 +2114,80 @@ func (sa *SemanticAnalyzer) getNodeType(node DecoratedNode) TypeKind {
 	}
 }
 
-// Check type compatibility
-func (sa *SemanticAnalyzer) typesCompatible(type1, type2 TypeKind) bool {
-	if type1 == type2 {
+// Check type compatibility: can a value of type source be used where target is expected
+// Integer widens to real (never the other way); integer and char are interchangeable
+func (sa *SemanticAnalyzer) typesCompatible(target, source TypeKind) bool {
+	if target == source {
 		return true
 	}
-	// Integer can be promoted to real
-	if (type1 == TypeInteger && type2 == TypeReal) || (type1 == TypeReal && type2 == TypeInteger) {
+	if target == TypeReal && source == TypeInteger {
 		return true
 	}
-	// Integer and char are compatible
-	if (type1 == TypeInteger && type2 == TypeChar) ||
-		(type1 == TypeChar && type2 == TypeInteger) {
+	if (target == TypeInteger && source == TypeChar) ||
+		(target == TypeChar && source == TypeInteger) {
+		return true
+	}
+	// A char can be used as a one-character string
+	if target == TypeString && source == TypeChar {
 		return true
 	}
 	return false
 }
 
+// Check if type is ordinal (valid as case selector / label)
+func isOrdinalType(typ TypeKind) bool {
+	return typ == TypeInteger || typ == TypeChar || typ == TypeBoolean || typ == TypeEnum
+}
+
 // Check if type is numeric
 func (sa *SemanticAnalyzer) isNumericType(typ TypeKind) bool {
 	return typ == TypeInteger || typ == TypeChar || typ == TypeReal
 }
 
 // Add error
-func (sa *SemanticAnalyzer) addError(message string) {
-	sa.Errors = append(sa.Errors, message)
+func (sa *SemanticAnalyzer) addError(code diagnostic.Code, format string, args ...interface{}) *diagnostic.Diagnostic {
+	sa.Errors = append(sa.Errors, *diagnostic.Errorf(code, sa.currentSpan(), format, args...))
+	return &sa.Errors[len(sa.Errors)-1]
 }
 
 // Add warning
-func (sa *SemanticAnalyzer) addWarning(message string) {
-	sa.Warnings = append(sa.Warnings, message)
+func (sa *SemanticAnalyzer) addWarning(code diagnostic.Code, format string, args ...interface{}) *diagnostic.Diagnostic {
+	sa.Warnings = append(sa.Warnings, *diagnostic.Warningf(code, sa.currentSpan(), format, args...))
+	return &sa.Warnings[len(sa.Warnings)-1]
 }
 
-// Extract value from token
-func extractValue(tokenValue string) string {
-	if start := strings.Index(tokenValue, "("); start >= 0 {
-		if end := strings.LastIndex(tokenValue, ")"); end >= 0 {
-			return tokenValue[start+1 : end]
-		}
+// Source span of the parse tree node being analyzed: from its first token to the end of its last token
+func (sa *SemanticAnalyzer) currentSpan() diagnostic.Span {
+	node := sa.current
+	if node == nil || node.Line == 0 {
+		return diagnostic.Span{}
 	}
-	return tokenValue
+	return node.Span()
+}
+
+// Make node the current source position; the returned func restores the previous one
+// Usage: defer sa.at(node)()
+func (sa *SemanticAnalyzer) at(node *milestone2.AbstractSyntaxTree) func() {
+	saved := sa.current
+	if node != nil && node.Line > 0 {
+		sa.current = node
+	}
+	return func() {
+		sa.current = saved
+	}
+}
+
+// Check if a parse tree node is a statement (body of if/while/for)
+func isStatementNode(node *milestone2.AbstractSyntaxTree) bool {
+	return node.Kind.IsStatement()
+}
+
+// Strip the quotes around a string/char literal
+func unquote(literal string) string {
+	if len(literal) >= 2 && literal[0] == '\'' && literal[len(literal)-1] == '\'' {
+		return literal[1 : len(literal)-1]
+	}
+	return literal
 }
 
 // Check if function body contains assignment to function name
@@ -1673,6 +2233,17 @@ func (sa *SemanticAnalyzer) checkStatementForReturnAssignment(stmt DecoratedNode
 		}
 	case *WhileNode:
 		return sa.checkStatementForReturnAssignment(node.Body, funcName)
+	case *RepeatNode:
+		return sa.checkStatementForReturnAssignment(node.Body, funcName)
+	case *CaseNode:
+		for _, arm := range node.Arms {
+			if sa.checkStatementForReturnAssignment(arm.Body, funcName) {
+				return true
+			}
+		}
+		if node.Else != nil {
+			return sa.checkStatementForReturnAssignment(node.Else, funcName)
+		}
 	case *ForNode:
 		return sa.checkStatementForReturnAssignment(node.Body, funcName)
 	}
@@ -0,0 +1,108 @@
+package milestone3_test
+
+import (
+	"bytes"
+	"compiler/internal/testprogram"
+	"compiler/milestone3"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// Every program in test/semantic must report exactly the messages in <name>.err (none without it)
+func TestSemanticPrograms(t *testing.T) {
+	for _, c := range testprogram.Cases(t, "semantic") {
+		t.Run(c.Name, func(t *testing.T) {
+			p := testprogram.Analyze(t, c.Source)
+			var messages []string
+			for _, d := range p.Analyzer.GetErrors() {
+				messages = append(messages, d.Message)
+			}
+			got := strings.Join(messages, "\n")
+			want, _ := c.Companion(t, ".err")
+			if want = strings.TrimSpace(want); got != want {
+				t.Errorf("errors:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+// Node of the decorated AST export, as far as the tests need it
+type exported struct {
+	Node     string      `json:"node"`
+	Name     string      `json:"name"`
+	Level    int         `json:"level"`
+	Address  int         `json:"address"`
+	Ref      int         `json:"ref"`
+	Children []*exported `json:"children"`
+}
+
+// Export the decorated AST of a test/semantic program and index its nodes by path
+// (node kinds and names from the root, e.g. /ProgramL/Block/Assign)
+func exportNodes(t *testing.T, name string) map[string]*exported {
+	t.Helper()
+	p := testprogram.Compile(t, testprogram.Load(t, "semantic", name).Source)
+	var out bytes.Buffer
+	if err := milestone3.WriteDecoratedJSON(&out, p.Decorated); err != nil {
+		t.Fatal(err)
+	}
+	var root exported
+	if err := json.Unmarshal(out.Bytes(), &root); err != nil {
+		t.Fatal(err)
+	}
+	nodes := make(map[string]*exported)
+	var walk func(node *exported, path string)
+	walk = func(node *exported, path string) {
+		path += "/" + node.Node + node.Name
+		nodes[path] = node
+		for _, child := range node.Children {
+			walk(child, path)
+		}
+	}
+	walk(&root, "")
+	return nodes
+}
+
+func TestExportedStatementLevels(t *testing.T) {
+	nodes := exportNodes(t, "nested_levels")
+	const dalam = "/ProgramL/Declarations/ProcedureDeclluar/Declarations/ProcedureDecldalam/Block"
+	const luar = "/ProgramL/Declarations/ProcedureDeclluar/Block"
+	for path, want := range map[string]int{
+		dalam + "/Assign":                   2,
+		dalam + "/Assign/BinOp":             2,
+		dalam + "/Assign/BinOp/Num":         2,
+		dalam + "/Assign/Varb":              1, // Variables keep the level they are declared at
+		luar + "/ProcedureCalldalam":        1,
+		luar + "/Assign":                    1,
+		luar + "/Assign/Varg":               0,
+		"/ProgramL/Block/ProcedureCallluar": 0,
+	} {
+		if node, ok := nodes[path]; !ok {
+			t.Errorf("no node %s", path)
+		} else if node.Level != want {
+			t.Errorf("%s: level %d, want %d", path, node.Level, want)
+		}
+	}
+}
+
+// Parameters and loop counters export the address and ref of their TAB entry; field accesses
+// add the field offset except through a var parameter
+func TestExportedAddresses(t *testing.T) {
+	nodes := exportNodes(t, "export_addresses")
+	const isi = "/ProgramA/Declarations/ProcedureDeclisi"
+	for path, want := range map[string][2]int{ // address, ref
+		isi + "/VarDeclarr":               {5, 0}, // var parameter: one slot holding the address, ATAB ref of Data
+		isi + "/VarDecln":                 {6, -1},
+		isi + "/VarDeclk":                 {7, -1},
+		isi + "/Block/For/Vark":           {7, -1},
+		"/ProgramA/Declarations/VarDecld": {5, 0},
+		"/ProgramA/Block/Assign/Vart.y":   {9, -1}, // t at 8, field offset 1
+		"/ProgramA/Declarations/ProcedureDeclgeser/Block/Assign/Varp.y": {5, -1}, // var parameter: offset applied at run time
+	} {
+		if node, ok := nodes[path]; !ok {
+			t.Errorf("no node %s", path)
+		} else if got := [2]int{node.Address, node.Ref}; got != want {
+			t.Errorf("%s: address, ref = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package milestone3
 
-import "fmt"
+import (
+	"compiler/milestone1"
+	"fmt"
+	"strings"
+)
 
 type ObjectClass int
 
@@ -45,6 +49,8 @@ const (
 	TypeReal
 	TypeArray
 	TypeRecord
+	TypeString
+	TypeEnum
 )
 
 func (t TypeKind) String() string {
@@ -61,6 +67,10 @@ func (t TypeKind) String() string {
 		return "array"
 	case TypeRecord:
 		return "record"
+	case TypeString:
+		return "string"
+	case TypeEnum:
+		return "enum"
 	case TypeNone:
 		return "void"
 	default:
@@ -78,11 +88,13 @@ type TabEntry struct {
 	Nrm        int         // Normal variable (1) atau var parameter (0)
 	Lev        int         // Lexical level (0=global, 1=prosedur level 1, dst)
 	Adr        int         // Address/offset atau nilai konstanta
+	Rref       int         // Fungsi: ref tipe hasil (rtab untuk enumerasi/subrange, -1 jika tidak ada)
 }
 
 // Menyimpan informasi detail array
 type AtabEntry struct {
 	Xtyp int // Tipe indeks array
+	Xref int // Pointer ke rtab jika indeks bertipe enumerasi/subrange (-1 jika tidak ada)
 	Etyp int // Tipe elemen array
 	Eref int // Pointer ke detail elemen jika elemen adalah tipe komposit (-1 jika tidak ada)
 	Low  int // Batas bawah indeks array
@@ -99,10 +111,21 @@ type BtabEntry struct {
 	Vsze int // Total ukuran variabel lokal
 }
 
+// Menyimpan informasi tipe enumerasi dan subrange
+type RtabEntry struct {
+	Rtyp TypeKind // Tipe dasar (integer, char, enum)
+	Eref int      // Untuk tipe enum: pointer ke rtab enumerasi asalnya (-1 untuk integer/char)
+	Low  int      // Nilai ordinal terkecil
+	High int      // Nilai ordinal terbesar
+}
+
 type SymbolTable struct {
-	Tab  []TabEntry
-	Btab []BtabEntry
-	Atab []AtabEntry
+	Tab    []TabEntry
+	Btab   []BtabEntry
+	Atab   []AtabEntry
+	Rtab   []RtabEntry // Tabel tipe enumerasi dan subrange (ref tipe tersebut = indeks ke sini)
+	Rconst []float64   // Tabel konstanta real (adr konstanta real = indeks ke sini)
+	Stab   []string    // Tabel konstanta string (adr konstanta string = indeks ke sini)
 
 	// Indeks pointer
 	TabIndex  int // Current index in Tab (start from 29 for reserved words)
@@ -116,9 +139,15 @@ type SymbolTable struct {
 
 	// Reserved words offset
 	ReservedWordsCount int
+	StandardCount      int // Jumlah entry bawaan (reserved words + fungsi/prosedur standar)
 }
 
 func NewSymbolTable() *SymbolTable {
+	return NewSymbolTableWithDialect(milestone1.Indonesian)
+}
+
+// NewSymbolTableWithDialect - reserved words dieja sesuai dialek source (urutan dan jumlah tetap)
+func NewSymbolTableWithDialect(dialect *milestone1.Dialect) *SymbolTable {
 	st := &SymbolTable{
 		Tab:  make([]TabEntry, 0, 1000),
 		Btab: make([]BtabEntry, 0, 100),
@@ -139,26 +168,98 @@ func NewSymbolTable() *SymbolTable {
 	st.CurrentLevel = 0
 	st.CurrentBlock = blockIndex
 
-	st.initReservedWords()
+	st.initReservedWords(dialect)
+	st.initStandardIdentifiers()
 
 	return st
 }
 
-func (st *SymbolTable) initReservedWords() {
-	reservedWords := []string{
-		"dan", "larik", "mulai", "kasus", "konstanta", "bagi", "turun_ke",
-		"lakukan", "selain_itu", "selesai", "untuk", "fungsi", "jika",
-		"mod", "tidak", "dari", "atau", "prosedur", "program", "rekaman",
-		"ulangi", "string", "maka", "ke", "tipe", "sampai", "variabel", "selama", "padat",
+func (st *SymbolTable) initReservedWords(dialect *milestone1.Dialect) {
+	reservedWords := []milestone1.Keyword{
+		milestone1.KwAnd, milestone1.KwArray, milestone1.KwBegin, milestone1.KwCase, milestone1.KwConst,
+		milestone1.KwDiv, milestone1.KwDownTo, milestone1.KwDo, milestone1.KwElse, milestone1.KwEnd,
+		milestone1.KwFor, milestone1.KwFunction, milestone1.KwIf, milestone1.KwMod, milestone1.KwNot,
+		milestone1.KwOf, milestone1.KwOr, milestone1.KwProcedure, milestone1.KwProgram, milestone1.KwRecord,
+		milestone1.KwRepeat, milestone1.KwString, milestone1.KwThen, milestone1.KwTo, milestone1.KwType,
+		milestone1.KwUntil, milestone1.KwVar, milestone1.KwWhile, milestone1.KwPacked,
 	}
 
 	for i, word := range reservedWords {
-		st.Enter(word, ObjConstant, TypeNone, 0, 1, i)
+		st.Enter(dialect.Spell(word), ObjConstant, TypeNone, 0, 1, i)
 	}
 
 	st.TabIndex = len(reservedWords)
 }
 
+// Fungsi standar Pascal-S; nomornya disimpan di adr dan jadi operand instruksi SFN
+// (abs dan sqr punya versi real di nomor+1, dipilih waktu analisis sesuai tipe argumen)
+type StdFunc int
+
+const (
+	StdAbs     StdFunc = 0
+	StdAbsReal StdFunc = 1
+	StdSqr     StdFunc = 2
+	StdSqrReal StdFunc = 3
+	StdOdd     StdFunc = 4
+	StdChr     StdFunc = 5
+	StdOrd     StdFunc = 6
+	StdSucc    StdFunc = 7
+	StdPred    StdFunc = 8
+	StdRound   StdFunc = 9
+	StdTrunc   StdFunc = 10
+	StdSin     StdFunc = 11
+	StdCos     StdFunc = 12
+	StdExp     StdFunc = 13
+	StdLn      StdFunc = 14
+	StdSqrt    StdFunc = 15
+	StdEof     StdFunc = 17
+	StdEoln    StdFunc = 18
+)
+
+// Fungsi standar: nama, nomor, dan tipe hasil (TypeNone = tergantung argumen)
+var standardFunctions = []struct {
+	name   string
+	fn     StdFunc
+	result TypeKind
+}{
+	{"abs", StdAbs, TypeNone},
+	{"sqr", StdSqr, TypeNone},
+	{"odd", StdOdd, TypeBoolean},
+	{"chr", StdChr, TypeChar},
+	{"ord", StdOrd, TypeInteger},
+	{"succ", StdSucc, TypeNone},
+	{"pred", StdPred, TypeNone},
+	{"round", StdRound, TypeInteger},
+	{"trunc", StdTrunc, TypeInteger},
+	{"sin", StdSin, TypeReal},
+	{"cos", StdCos, TypeReal},
+	{"exp", StdExp, TypeReal},
+	{"ln", StdLn, TypeReal},
+	{"sqrt", StdSqrt, TypeReal},
+	{"eof", StdEof, TypeBoolean},
+	{"eoln", StdEoln, TypeBoolean},
+}
+
+// Prosedur standar (adr = nomor urut)
+var standardProcedures = []string{"read", "readln", "write", "writeln"}
+
+// Fungsi dan prosedur standar dimasukkan ke level global (tanpa btab, ref = -1)
+// Program boleh mendeklarasikan ulang nama-nama ini (menutupi versi standar)
+func (st *SymbolTable) initStandardIdentifiers() {
+	for _, f := range standardFunctions {
+		st.Enter(f.name, ObjFunction, f.result, -1, 1, int(f.fn))
+	}
+	for i, name := range standardProcedures {
+		st.Enter(name, ObjProcedure, TypeNone, -1, 1, i+1)
+	}
+	st.StandardCount = len(st.Tab)
+}
+
+// Apakah entry TAB adalah fungsi/prosedur standar
+func (st *SymbolTable) IsStandard(index int) bool {
+	return index >= st.ReservedWordsCount && index < st.StandardCount
+}
+
 // Masuk ke block baru (prosedur, fungsi, atau program utama)
 func (st *SymbolTable) enterBlock() int {
 	blockIndex := st.BtabIndex
@@ -237,6 +338,7 @@ func (st *SymbolTable) Enter(
 		Nrm:        nrm,
 		Lev:        st.CurrentLevel,
 		Adr:        adr,
+		Rref:       -1,
 	}
 
 	st.Tab = append(st.Tab, entry)
@@ -246,7 +348,7 @@ func (st *SymbolTable) Enter(
 }
 
 // Menambahkan entry ke array table
-func (st *SymbolTable) EnterArray(xtyp, etyp, eref, low, high, elsz int) int {
+func (st *SymbolTable) EnterArray(xtyp, xref, etyp, eref, low, high, elsz int) int {
 	index := st.AtabIndex
 
 	// Check for potential overflow
@@ -257,6 +359,7 @@ func (st *SymbolTable) EnterArray(xtyp, etyp, eref, low, high, elsz int) int {
 
 	entry := AtabEntry{
 		Xtyp: xtyp,
+		Xref: xref,
 		Etyp: etyp,
 		Eref: eref,
 		Low:  low,
@@ -271,6 +374,91 @@ func (st *SymbolTable) EnterArray(xtyp, etyp, eref, low, high, elsz int) int {
 	return index
 }
 
+// Menambahkan entry ke range table, return indeksnya
+// Enumerasi baru menunjuk ke dirinya sendiri (eref = indeks entry)
+func (st *SymbolTable) EnterRange(rtyp TypeKind, eref, low, high int) int {
+	index := len(st.Rtab)
+	if rtyp == TypeEnum && eref < 0 {
+		eref = index
+	}
+	st.Rtab = append(st.Rtab, RtabEntry{Rtyp: rtyp, Eref: eref, Low: low, High: high})
+	return index
+}
+
+// Batas ordinal tipe dengan ref ke rtab (ok = false kalau tipe tidak punya batas)
+func (st *SymbolTable) RangeBounds(ref int) (low, high int, ok bool) {
+	if ref < 0 || ref >= len(st.Rtab) {
+		return 0, 0, false
+	}
+	return st.Rtab[ref].Low, st.Rtab[ref].High, true
+}
+
+// Batas subrange/enumerasi untuk range check saat runtime (ok = false kalau tipe tidak dibatasi)
+func (st *SymbolTable) OrdinalBounds(typ TypeKind, ref int) (low, high int, ok bool) {
+	switch typ {
+	case TypeInteger, TypeChar, TypeEnum:
+		return st.RangeBounds(ref)
+	}
+	return 0, 0, false
+}
+
+// Batas tipe asal untuk hasil succ/pred: seluruh enumerasi, 0..255 untuk char
+func (st *SymbolTable) HostBounds(typ TypeKind, ref int) (low, high int, ok bool) {
+	switch typ {
+	case TypeEnum:
+		return st.RangeBounds(st.EnumBase(ref))
+	case TypeChar:
+		return 0, 255, true
+	}
+	return 0, 0, false
+}
+
+// Enumerasi asal dari tipe enum/subrange-nya (-1 kalau bukan enum)
+func (st *SymbolTable) EnumBase(ref int) int {
+	if ref < 0 || ref >= len(st.Rtab) {
+		return -1
+	}
+	return st.Rtab[ref].Eref
+}
+
+// Menambahkan konstanta real ke tabel rconst, return indeksnya (nilai yang sama dipakai ulang)
+func (st *SymbolTable) EnterReal(value float64) int {
+	for i, r := range st.Rconst {
+		if r == value {
+			return i
+		}
+	}
+	st.Rconst = append(st.Rconst, value)
+	return len(st.Rconst) - 1
+}
+
+// Menambahkan konstanta string ke tabel stab, return indeksnya (nilai yang sama dipakai ulang)
+func (st *SymbolTable) EnterString(value string) int {
+	for i, s := range st.Stab {
+		if s == value {
+			return i
+		}
+	}
+	st.Stab = append(st.Stab, value)
+	return len(st.Stab) - 1
+}
+
+// Ambil nilai konstanta string dari tabel stab
+func (st *SymbolTable) GetString(index int) (string, error) {
+	if index < 0 || index >= len(st.Stab) {
+		return "", fmt.Errorf("invalid stab index: %d", index)
+	}
+	return st.Stab[index], nil
+}
+
+// Ambil nilai konstanta real dari tabel rconst
+func (st *SymbolTable) GetReal(index int) (float64, error) {
+	if index < 0 || index >= len(st.Rconst) {
+		return 0, fmt.Errorf("invalid rconst index: %d", index)
+	}
+	return st.Rconst[index], nil
+}
+
 // Menambahkan entry ke block table (sudah di-handle di enterBlock)
 func (st *SymbolTable) EnterBlock() int {
 	return st.enterBlock()
@@ -318,14 +506,35 @@ func (st *SymbolTable) Lookup(identifier string) (int, bool) {
 	return -1, false
 }
 
+// Cari prosedur/fungsi (melewati variabel hasil fungsi yang namanya sama)
+func (st *SymbolTable) LookupSubprogram(identifier string) (int, bool) {
+	for level := st.CurrentLevel; level >= 0; level-- {
+		blockIndex := st.Display[level]
+
+		if blockIndex >= 0 && blockIndex < len(st.Btab) {
+			idx := st.Btab[blockIndex].Last
+			for idx >= 0 && idx < len(st.Tab) {
+				entry := st.Tab[idx]
+				if entry.Identifier == identifier && (entry.Obj == ObjProcedure || entry.Obj == ObjFunction) {
+					return idx, true
+				}
+				idx = entry.Link
+			}
+		}
+	}
+
+	return -1, false
+}
+
 // Cari identifier hanya di scope saat ini
 func (st *SymbolTable) LookupInCurrentScope(identifier string) (int, bool) {
 	if st.CurrentBlock < 0 || st.CurrentBlock >= len(st.Btab) {
 		return -1, false
 	}
 
+	// Entry bawaan tidak dihitung (boleh dideklarasikan ulang)
 	idx := st.Btab[st.CurrentBlock].Last
-	for idx >= 0 && idx < len(st.Tab) {
+	for idx >= st.StandardCount && idx < len(st.Tab) {
 		if st.Tab[idx].Identifier == identifier {
 			return idx, true
 		}
@@ -362,8 +571,10 @@ func (st *SymbolTable) GetBlockEntry(index int) (*BtabEntry, error) {
 // Dapatkan ukuran tipe dalam byte/unit memori
 func (st *SymbolTable) getTypeSize(typ TypeKind, ref int) int {
 	switch typ {
-	case TypeInteger, TypeBoolean, TypeChar:
+	case TypeInteger, TypeBoolean, TypeChar, TypeEnum:
 		return 1
+	case TypeString:
+		return 1 // Satu sel berisi referensi string
 	case TypeReal:
 		return 8
 	case TypeArray:
@@ -381,6 +592,73 @@ func (st *SymbolTable) getTypeSize(typ TypeKind, ref int) int {
 	}
 }
 
+// Ukuran tipe untuk dipakai di luar package (interpreter, code generator)
+func (st *SymbolTable) GetTypeSize(typ TypeKind, ref int) int {
+	return st.getTypeSize(typ, ref)
+}
+
+// Nama tipe untuk pesan error, misal "array[1..10] of integer", "1..3", atau nama tipe record/enum
+func (st *SymbolTable) TypeName(typ TypeKind, ref int) string {
+	switch typ {
+	case TypeArray:
+		if ref < 0 || ref >= len(st.Atab) {
+			return typ.String()
+		}
+		a := st.Atab[ref]
+		return fmt.Sprintf("array[%s..%s] of %s", st.OrdinalName(a.Low, TypeKind(a.Xtyp), a.Xref),
+			st.OrdinalName(a.High, TypeKind(a.Xtyp), a.Xref), st.TypeName(TypeKind(a.Etyp), a.Eref))
+	case TypeRecord:
+		if name, ok := st.typeIdentifier(typ, ref); ok {
+			return "record " + name
+		}
+	case TypeInteger, TypeChar, TypeEnum:
+		if ref < 0 || ref >= len(st.Rtab) {
+			return typ.String()
+		}
+		if typ == TypeEnum && st.Rtab[ref].Eref == ref {
+			// Enumerasi itu sendiri, bukan subrange-nya
+			if name, ok := st.typeIdentifier(typ, ref); ok {
+				return name
+			}
+			names := []string{}
+			for ord := st.Rtab[ref].Low; ord <= st.Rtab[ref].High; ord++ {
+				names = append(names, st.OrdinalName(ord, typ, ref))
+			}
+			return "(" + strings.Join(names, ", ") + ")"
+		}
+		return st.OrdinalName(st.Rtab[ref].Low, typ, ref) + ".." + st.OrdinalName(st.Rtab[ref].High, typ, ref)
+	}
+	return typ.String()
+}
+
+// Nilai ordinal sebagai teks: angka, 'c' untuk char, nama konstanta untuk enum
+func (st *SymbolTable) OrdinalName(value int, typ TypeKind, ref int) string {
+	switch typ {
+	case TypeChar:
+		return fmt.Sprintf("'%c'", rune(value))
+	case TypeBoolean:
+		return fmt.Sprintf("%v", value != 0)
+	case TypeEnum:
+		base := st.EnumBase(ref)
+		for _, entry := range st.Tab {
+			if entry.Obj == ObjConstant && entry.Type == TypeEnum && entry.Ref == base && entry.Adr == value {
+				return entry.Identifier
+			}
+		}
+	}
+	return fmt.Sprintf("%d", value)
+}
+
+// Identifier tipe (deklarasi tipe) yang menunjuk ke ref ini, jika ada
+func (st *SymbolTable) typeIdentifier(typ TypeKind, ref int) (string, bool) {
+	for _, entry := range st.Tab {
+		if entry.Obj == ObjType && entry.Type == typ && entry.Ref == ref {
+			return entry.Identifier, true
+		}
+	}
+	return "", false
+}
+
 // Cek apakah identifier sudah dideklarasikan
 func (st *SymbolTable) IsDeclared(identifier string) bool {
 	_, found := st.Lookup(identifier)
@@ -400,7 +678,7 @@ func (st *SymbolTable) PrintSymbolTable() {
 		"idx", "id", "obj", "type", "ref", "nrm", "lev", "adr", "link")
 	fmt.Println("--------------------------------------------------------------------------------")
 
-	for i := st.ReservedWordsCount; i < len(st.Tab); i++ {
+	for i := st.StandardCount; i < len(st.Tab); i++ {
 		entry := st.Tab[i]
 		fmt.Printf("%-5d %-20s %-12s %-6d %-6d %-6d %-4d %-6d %-6d\n",
 			i, entry.Identifier, entry.Obj, entry.Type,
@@ -418,16 +696,46 @@ func (st *SymbolTable) PrintSymbolTable() {
 	}
 
 	fmt.Println("\n========== ARRAY TABLE (ATAB) ==========")
-	fmt.Printf("%-5s %-6s %-6s %-6s %-6s %-6s %-6s %-6s\n",
-		"idx", "xtyp", "etyp", "eref", "low", "high", "elsz", "size")
-	fmt.Println("----------------------------------------------------------------")
+	fmt.Printf("%-5s %-6s %-6s %-6s %-6s %-6s %-6s %-6s %-6s\n",
+		"idx", "xtyp", "xref", "etyp", "eref", "low", "high", "elsz", "size")
+	fmt.Println("-----------------------------------------------------------------------")
 
 	for i := 0; i < len(st.Atab); i++ {
 		entry := st.Atab[i]
-		fmt.Printf("%-5d %-6d %-6d %-6d %-6d %-6d %-6d %-6d\n",
-			i, entry.Xtyp, entry.Etyp, entry.Eref, entry.Low, entry.High,
+		fmt.Printf("%-5d %-6d %-6d %-6d %-6d %-6d %-6d %-6d %-6d\n",
+			i, entry.Xtyp, entry.Xref, entry.Etyp, entry.Eref, entry.Low, entry.High,
 			entry.Elsz, entry.Size)
 	}
 
+	if len(st.Rtab) > 0 {
+		fmt.Println("\n========== RANGE TABLE (RTAB) ==========")
+		fmt.Printf("%-5s %-6s %-6s %-6s %-6s\n", "idx", "rtyp", "eref", "low", "high")
+		fmt.Println("----------------------------------------")
+
+		for i, entry := range st.Rtab {
+			fmt.Printf("%-5d %-6d %-6d %-6d %-6d\n", i, entry.Rtyp, entry.Eref, entry.Low, entry.High)
+		}
+	}
+
+	if len(st.Rconst) > 0 {
+		fmt.Println("\n========== REAL CONSTANT TABLE (RCONST) ==========")
+		fmt.Printf("%-5s %s\n", "idx", "value")
+		fmt.Println("----------------------------------------")
+
+		for i, value := range st.Rconst {
+			fmt.Printf("%-5d %g\n", i, value)
+		}
+	}
+
+	if len(st.Stab) > 0 {
+		fmt.Println("\n========== STRING TABLE (STAB) ==========")
+		fmt.Printf("%-5s %s\n", "idx", "value")
+		fmt.Println("----------------------------------------")
+
+		for i, value := range st.Stab {
+			fmt.Printf("%-5d '%s'\n", i, value)
+		}
+	}
+
 	fmt.Println()
 }
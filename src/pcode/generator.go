@@ -0,0 +1,639 @@
+package pcode
+
+import (
+	"compiler/milestone3"
+	"fmt"
+)
+
+// CodeGenerator lowers a decorated AST into Pascal-S P-code
+// Addresses, levels and sizes come from the symbol table built by the semantic analyzer
+type CodeGenerator struct {
+	SymTable *milestone3.SymbolTable
+	Program  *Program
+	Errors   []string
+	level    int // Lexical level of the block whose code is being generated
+}
+
+// Create new code generator
+func NewCodeGenerator(symTable *milestone3.SymbolTable) *CodeGenerator {
+	return &CodeGenerator{
+		SymTable: symTable,
+		Program:  &Program{},
+		Errors:   make([]string, 0),
+	}
+}
+
+// Generate produces P-code for a program
+// Procedure/function entry points are stored in TAB.Adr, as in Wirth's compiler
+func (g *CodeGenerator) Generate(root milestone3.DecoratedNode) (*Program, error) {
+	program, ok := root.(*milestone3.ProgramNode)
+	if !ok {
+		return nil, fmt.Errorf("expected ProgramNode, got %T", root)
+	}
+
+	// Jump over subprogram bodies to the main program
+	jumpToMain := g.emit(JMP, 0, 0)
+
+	g.level = 0
+	g.genDeclarations(program.Declarations)
+
+	g.patch(jumpToMain, len(g.Program.Code))
+	g.genStatement(program.Block)
+	g.emit(HLT, 0, 0)
+
+	if len(g.Errors) > 0 {
+		return g.Program, fmt.Errorf("code generation failed with %d error(s)", len(g.Errors))
+	}
+	return g.Program, nil
+}
+
+// Get errors
+func (g *CodeGenerator) GetErrors() []string {
+	return g.Errors
+}
+
+func (g *CodeGenerator) addError(message string) {
+	g.Errors = append(g.Errors, message)
+}
+
+func (g *CodeGenerator) emit(op Opcode, x, y int) int {
+	g.Program.Code = append(g.Program.Code, Instruction{Op: op, X: x, Y: y})
+	return len(g.Program.Code) - 1
+}
+
+// Set the jump target of an already emitted instruction
+func (g *CodeGenerator) patch(pc, target int) {
+	g.Program.Code[pc].Y = target
+}
+
+// ========== DECLARATIONS ==========
+
+func (g *CodeGenerator) genDeclarations(node milestone3.DecoratedNode) {
+	switch n := node.(type) {
+	case *milestone3.DeclarationListNode:
+		for _, decl := range n.Declarations {
+			g.genDeclarations(decl)
+		}
+	case *milestone3.SubprogramDeclNode:
+		g.genSubprogram(n)
+	}
+}
+
+// Subprogram: nested subprograms first, then the body starting at the entry point
+func (g *CodeGenerator) genSubprogram(decl *milestone3.SubprogramDeclNode) {
+	entry, err := g.SymTable.GetEntry(decl.TabIndex)
+	if err != nil {
+		g.addError(fmt.Sprintf("Subprogram '%s' has no symbol table entry", decl.Name))
+		return
+	}
+
+	oldLevel := g.level
+	g.level = entry.Lev + 1
+
+	g.genDeclarations(decl.Declarations)
+
+	entry.Adr = len(g.Program.Code)
+	g.genStatement(decl.Body)
+	if decl.IsFunction {
+		g.emit(EXF, 0, 0)
+	} else {
+		g.emit(EXP, 0, 0)
+	}
+
+	g.level = oldLevel
+}
+
+// ========== STATEMENTS ==========
+
+func (g *CodeGenerator) genStatement(node milestone3.DecoratedNode) {
+	switch n := node.(type) {
+	case nil:
+		return
+	case *milestone3.BlockNode:
+		for _, stmt := range n.Statements {
+			g.genStatement(stmt)
+		}
+	case *milestone3.AssignNode:
+		g.genAssign(n)
+	case *milestone3.ProcCallNode:
+		if n.IsBuiltIn {
+			g.genBuiltIn(n)
+			return
+		}
+		if n.Type != milestone3.TypeNone {
+			g.addError(fmt.Sprintf("Function '%s' cannot be called as a statement", n.Name))
+			return
+		}
+		g.genCall(n)
+	case *milestone3.IfNode:
+		g.genExpression(n.Condition)
+		jumpToElse := g.emit(JPC, 0, 0)
+		g.genStatement(n.ThenStmt)
+		if n.ElseStmt != nil {
+			jumpToEnd := g.emit(JMP, 0, 0)
+			g.patch(jumpToElse, len(g.Program.Code))
+			g.genStatement(n.ElseStmt)
+			g.patch(jumpToEnd, len(g.Program.Code))
+		} else {
+			g.patch(jumpToElse, len(g.Program.Code))
+		}
+	case *milestone3.WhileNode:
+		loopStart := len(g.Program.Code)
+		g.genExpression(n.Condition)
+		jumpToEnd := g.emit(JPC, 0, 0)
+		g.genStatement(n.Body)
+		g.emit(JMP, 0, loopStart)
+		g.patch(jumpToEnd, len(g.Program.Code))
+	case *milestone3.RepeatNode:
+		loopStart := len(g.Program.Code)
+		g.genStatement(n.Body)
+		g.genExpression(n.Condition)
+		g.emit(JPC, 0, loopStart)
+	case *milestone3.CaseNode:
+		g.genCase(n)
+	case *milestone3.ForNode:
+		g.genFor(n)
+	default:
+		g.addError(fmt.Sprintf("Unsupported statement %T", node))
+	}
+}
+
+// Assignment: STO for scalars, CPB for arrays/records
+func (g *CodeGenerator) genAssign(n *milestone3.AssignNode) {
+	target, ok := n.Target.(*milestone3.VarNode)
+	if !ok {
+		g.addError("Invalid assignment target")
+		return
+	}
+
+	g.genAddress(target)
+
+	if isComposite(target.Type) {
+		source, ok := n.Value.(*milestone3.VarNode)
+		if !ok {
+			g.addError(fmt.Sprintf("Cannot assign expression to %s '%s'", target.Type, target.Name))
+			return
+		}
+		g.genAddress(source)
+		g.emit(CPB, 0, g.SymTable.GetTypeSize(target.Type, target.Ref))
+		return
+	}
+
+	valueType := g.genExpression(n.Value)
+	g.convert(valueType, target.Type, 0)
+	g.checkRange(target.Type, target.Ref)
+	g.emit(STO, 0, 0)
+}
+
+// Case (Wirth): selector; SWT table; arms each ending in JMP exit; table of CAS label, JMP arm pairs
+// The table ends with JMP 0, else-arm (JMP 0, 0 when there is no selain_itu)
+func (g *CodeGenerator) genCase(n *milestone3.CaseNode) {
+	g.genExpression(n.Selector)
+	switchAt := g.emit(SWT, 0, 0)
+
+	type entry struct{ label, addr int }
+	table := make([]entry, 0)
+	exits := make([]int, 0)
+	for _, arm := range n.Arms {
+		for _, label := range arm.Labels {
+			table = append(table, entry{label, len(g.Program.Code)})
+		}
+		g.genStatement(arm.Body)
+		exits = append(exits, g.emit(JMP, 0, 0))
+	}
+
+	elseAddr := 0
+	if n.Else != nil {
+		elseAddr = len(g.Program.Code)
+		g.genStatement(n.Else)
+		exits = append(exits, g.emit(JMP, 0, 0))
+	}
+
+	g.patch(switchAt, len(g.Program.Code))
+	for _, e := range table {
+		g.emit(CAS, 0, e.label)
+		g.emit(JMP, 0, e.addr)
+	}
+	g.emit(JMP, 0, elseAddr)
+
+	for _, exit := range exits {
+		g.patch(exit, len(g.Program.Code))
+	}
+}
+
+// For loop (Wirth): [address, start, end] F1x exit; body; F2x body
+func (g *CodeGenerator) genFor(n *milestone3.ForNode) {
+	counter, ok := n.Variable.(*milestone3.VarNode)
+	if !ok {
+		g.addError("Invalid for loop counter")
+		return
+	}
+
+	entryOp, stepOp := F1U, F2U
+	if n.IsDownTo {
+		entryOp, stepOp = F1D, F2D
+	}
+
+	g.genAddress(counter)
+	g.genExpression(n.StartValue)
+	g.genExpression(n.EndValue)
+	loopEntry := g.emit(entryOp, 0, 0)
+	// The loop runs: every counter value lies between start and end, so checking both once is enough
+	if low, high, ok := g.SymTable.OrdinalBounds(counter.Type, counter.Ref); ok {
+		g.emit(CHF, low, high)
+	}
+	bodyStart := len(g.Program.Code)
+	g.genStatement(n.Body)
+	g.emit(stepOp, 0, bodyStart)
+	g.patch(loopEntry, len(g.Program.Code))
+}
+
+// Call to a user-defined procedure/function
+// MKS reserves the frame header, arguments fill the parameter area, CAL enters the block
+func (g *CodeGenerator) genCall(n *milestone3.ProcCallNode) {
+	entry, err := g.SymTable.GetEntry(n.TabIndex)
+	if err != nil {
+		g.addError(fmt.Sprintf("Undefined subprogram '%s'", n.Name))
+		return
+	}
+	params := g.parameters(entry)
+	if len(params) != len(n.Arguments) {
+		g.addError(fmt.Sprintf("'%s' expects %d argument(s) but got %d", n.Name, len(params), len(n.Arguments)))
+		return
+	}
+
+	g.emit(MKS, 0, n.TabIndex)
+
+	paramSize := 0
+	for i, param := range params {
+		// A var parameter occupies one slot (the address), whatever its type
+		size := 1
+		if !n.IsByRef(i) {
+			size = g.SymTable.GetTypeSize(param.Type, param.Ref)
+		}
+		pushed := 1
+		arg := n.Arguments[i]
+
+		switch {
+		case n.IsByRef(i):
+			// var parameter: pass the address of the argument
+			argVar, ok := arg.(*milestone3.VarNode)
+			if !ok {
+				g.addError(fmt.Sprintf("Argument %d of '%s' must be a variable", i+1, n.Name))
+				return
+			}
+			g.genAddress(argVar)
+		case isComposite(param.Type):
+			// value parameter of composite type: copy the whole block
+			argVar, ok := arg.(*milestone3.VarNode)
+			if !ok {
+				g.addError(fmt.Sprintf("Argument %d of '%s' must be a variable", i+1, n.Name))
+				return
+			}
+			g.genAddress(argVar)
+			g.emit(LDB, 0, size)
+			pushed = size
+		default:
+			argType := g.genExpression(arg)
+			g.convert(argType, param.Type, 0)
+			g.checkRange(param.Type, param.Ref)
+		}
+
+		// Keep the parameter area aligned with the offsets chosen by the analyzer
+		for ; pushed < size; pushed++ {
+			g.emit(LDC, 0, 0)
+		}
+		paramSize += pushed
+	}
+
+	g.emit(CAL, 0, 4+paramSize)
+
+	// Restore display entries overwritten by a call to a shallower subprogram
+	if entry.Lev < g.level {
+		g.emit(DIS, entry.Lev, g.level)
+	}
+}
+
+// Parameters of a subprogram in declaration order (from the BTAB parameter chain)
+func (g *CodeGenerator) parameters(entry *milestone3.TabEntry) []milestone3.TabEntry {
+	params := []milestone3.TabEntry{}
+	btab, err := g.SymTable.GetBlockEntry(entry.Ref)
+	if err != nil {
+		return params
+	}
+
+	idx := btab.Lpar
+	for idx >= 0 && idx <= btab.Last && idx < len(g.SymTable.Tab) {
+		param := g.SymTable.Tab[idx]
+		if param.Obj == milestone3.ObjVariable && param.Lev == entry.Lev+1 {
+			params = append([]milestone3.TabEntry{param}, params...)
+		}
+		idx = param.Link
+	}
+	return params
+}
+
+// write/writeln/read/readln
+func (g *CodeGenerator) genBuiltIn(n *milestone3.ProcCallNode) {
+	switch n.Name {
+	case "write", "writeln":
+		for _, arg := range n.Arguments {
+			if str, ok := arg.(*milestone3.StringNode); ok {
+				g.emit(WRS, len(str.Value), g.addString(str.Value))
+				continue
+			}
+			typ := g.genExpression(arg)
+			g.emit(WRW, 0, int(typ))
+		}
+		if n.Name == "writeln" {
+			g.emit(WRL, 0, 0)
+		}
+	case "read", "readln":
+		for i, arg := range n.Arguments {
+			target, ok := arg.(*milestone3.VarNode)
+			if !ok {
+				g.addError(fmt.Sprintf("Argument %d of '%s' must be a variable", i+1, n.Name))
+				continue
+			}
+			g.genAddress(target)
+			if _, _, ok := g.SymTable.OrdinalBounds(target.Type, target.Ref); ok {
+				g.emit(RDV, 0, int(target.Type))
+				g.checkRange(target.Type, target.Ref)
+				g.emit(STO, 0, 0)
+				continue
+			}
+			g.emit(RED, 0, int(target.Type))
+		}
+		if n.Name == "readln" {
+			g.emit(RDL, 0, 0)
+		}
+	default:
+		g.addError(fmt.Sprintf("Unknown built-in procedure '%s'", n.Name))
+	}
+}
+
+// Append a string to the string table and return its start index
+func (g *CodeGenerator) addString(s string) int {
+	start := len(g.Program.Strings)
+	g.Program.Strings += s
+	return start
+}
+
+// ========== EXPRESSIONS ==========
+
+// Push a real constant (LDR into the program's real table)
+func (g *CodeGenerator) emitReal(value float64) {
+	g.Program.Reals = append(g.Program.Reals, value)
+	g.emit(LDR, 0, len(g.Program.Reals)-1)
+}
+
+// Generate code that pushes the value of an expression; returns its type
+func (g *CodeGenerator) genExpression(node milestone3.DecoratedNode) milestone3.TypeKind {
+	switch n := node.(type) {
+	case *milestone3.NumberNode:
+		g.emit(LDC, 0, n.Value)
+		return milestone3.TypeInteger
+	case *milestone3.RealNode:
+		g.emitReal(n.Value)
+		return milestone3.TypeReal
+	case *milestone3.StringNode:
+		g.emit(LDS, len(n.Value), g.addString(n.Value))
+		return milestone3.TypeString
+	case *milestone3.CharNode:
+		g.emit(LDC, 0, int(n.Value))
+		return milestone3.TypeChar
+	case *milestone3.BooleanNode:
+		if n.Value {
+			g.emit(LDC, 0, 1)
+		} else {
+			g.emit(LDC, 0, 0)
+		}
+		return milestone3.TypeBoolean
+	case *milestone3.VarNode:
+		return g.genVarValue(n)
+	case *milestone3.UnaryOpNode:
+		typ := g.genExpression(n.Operand)
+		switch n.Operator {
+		case "tidak", "not":
+			g.emit(NOT, 0, 0)
+			return milestone3.TypeBoolean
+		case "-":
+			if typ == milestone3.TypeReal {
+				g.emit(MUS, 0, 1)
+			} else {
+				g.emit(MUS, 0, 0)
+			}
+		}
+		return typ
+	case *milestone3.BinOpNode:
+		return g.genBinOp(n)
+	case *milestone3.ProcCallNode:
+		if n.IsBuiltIn {
+			return g.genStdFunction(n)
+		}
+		g.genCall(n)
+		return n.Type
+	default:
+		g.addError(fmt.Sprintf("Unsupported expression %T", node))
+		return milestone3.TypeNone
+	}
+}
+
+// Standard function: argument on the stack (widened to real where needed), then SFN
+func (g *CodeGenerator) genStdFunction(n *milestone3.ProcCallNode) milestone3.TypeKind {
+	if len(n.Arguments) == 1 {
+		argType := g.genExpression(n.Arguments[0])
+		switch n.Func {
+		case milestone3.StdRound, milestone3.StdTrunc, milestone3.StdSin, milestone3.StdCos,
+			milestone3.StdExp, milestone3.StdLn, milestone3.StdSqrt:
+			g.convert(argType, milestone3.TypeReal, 0)
+		}
+	}
+	g.emit(SFN, 0, int(n.Func))
+	if n.Func == milestone3.StdSucc || n.Func == milestone3.StdPred {
+		// The result must still be a value of the enumeration (or a character)
+		if low, high, ok := g.SymTable.HostBounds(n.Type, n.Ref); ok {
+			g.emit(CHK, low, high)
+		}
+	}
+	return n.Type
+}
+
+// CHK before storing into a subrange/enumeration variable or parameter
+func (g *CodeGenerator) checkRange(typ milestone3.TypeKind, ref int) {
+	if low, high, ok := g.SymTable.OrdinalBounds(typ, ref); ok {
+		g.emit(CHK, low, high)
+	}
+}
+
+// Value of a variable, parameter or constant
+func (g *CodeGenerator) genVarValue(v *milestone3.VarNode) milestone3.TypeKind {
+	entry, err := g.SymTable.GetEntry(v.TabIndex)
+	if err != nil {
+		g.addError(fmt.Sprintf("Undefined identifier '%s'", v.Name))
+		return milestone3.TypeNone
+	}
+
+	if entry.Obj == milestone3.ObjConstant {
+		if entry.Type == milestone3.TypeReal {
+			// Real constants keep an RCONST index in adr
+			value, err := g.SymTable.GetReal(entry.Adr)
+			if err != nil {
+				g.addError(fmt.Sprintf("Invalid real constant '%s'", v.Name))
+			}
+			g.emitReal(value)
+			return entry.Type
+		}
+		if entry.Type == milestone3.TypeString {
+			value, err := g.SymTable.GetString(entry.Adr)
+			if err != nil {
+				g.addError(fmt.Sprintf("Invalid string constant '%s'", v.Name))
+			}
+			g.emit(LDS, len(value), g.addString(value))
+			return entry.Type
+		}
+		g.emit(LDC, 0, entry.Adr)
+		return entry.Type
+	}
+
+	// Scalar at a static offset: load directly (indirectly for var parameters without selectors)
+	if len(v.DynamicSelectors()) == 0 && !isComposite(v.Type) {
+		switch {
+		case entry.Nrm != 0:
+			g.emit(LOD, entry.Lev, entry.Adr+v.StaticOffset())
+			return v.Type
+		case len(v.Selectors) == 0:
+			g.emit(LDI, entry.Lev, entry.Adr)
+			return v.Type
+		}
+	}
+
+	g.genAddress(v)
+	if isComposite(v.Type) {
+		g.emit(LDB, 0, g.SymTable.GetTypeSize(v.Type, v.Ref))
+	} else {
+		g.emit(LDT, 0, 0)
+	}
+	return v.Type
+}
+
+// Generate code that pushes the address of a variable (following var parameters and selectors)
+// Leading field offsets are folded into LDA; fields after an index use INC
+func (g *CodeGenerator) genAddress(v *milestone3.VarNode) {
+	entry, err := g.SymTable.GetEntry(v.TabIndex)
+	if err != nil || entry.Obj != milestone3.ObjVariable {
+		g.addError(fmt.Sprintf("'%s' is not a variable", v.Name))
+		return
+	}
+
+	if entry.Nrm == 0 {
+		g.emit(LOD, entry.Lev, entry.Adr)
+		if offset := v.StaticOffset(); offset != 0 {
+			g.emit(INC, 0, offset)
+		}
+	} else {
+		g.emit(LDA, entry.Lev, entry.Adr+v.StaticOffset())
+	}
+
+	for _, sel := range v.DynamicSelectors() {
+		if sel.Index == nil {
+			if sel.Offset != 0 {
+				g.emit(INC, 0, sel.Offset)
+			}
+			continue
+		}
+		atab, err := g.SymTable.GetArrayEntry(sel.Ref)
+		if err != nil {
+			g.addError(fmt.Sprintf("'%s' is not an array", v.Name))
+			return
+		}
+		g.genExpression(sel.Index)
+		if atab.Elsz == 1 {
+			g.emit(IDX, 0, sel.Ref)
+		} else {
+			g.emit(IXX, 0, sel.Ref)
+		}
+	}
+}
+
+func (g *CodeGenerator) genBinOp(n *milestone3.BinOpNode) milestone3.TypeKind {
+	leftType := g.genExpression(n.Left)
+	rightType := g.genExpression(n.Right)
+	isReal := leftType == milestone3.TypeReal || rightType == milestone3.TypeReal
+	isString := leftType == milestone3.TypeString || rightType == milestone3.TypeString
+
+	// Widen integer operands when the operation is done in real arithmetic (chars when done on strings)
+	widen := func() {
+		target := milestone3.TypeReal
+		if isString {
+			target = milestone3.TypeString
+		}
+		g.convert(leftType, target, 1)
+		g.convert(rightType, target, 0)
+	}
+
+	switch n.Operator {
+	case "dan", "and":
+		g.emit(AND, 0, 0)
+		return milestone3.TypeBoolean
+	case "atau", "or":
+		g.emit(ORR, 0, 0)
+		return milestone3.TypeBoolean
+	case "/":
+		widen()
+		g.emit(DIR, 0, 0)
+		return milestone3.TypeReal
+	case "bagi", "div":
+		g.emit(DIV, 0, 0)
+		return milestone3.TypeInteger
+	case "mod":
+		g.emit(MOD, 0, 0)
+		return milestone3.TypeInteger
+	case "+", "-", "*":
+		intOps := map[string]Opcode{"+": ADD, "-": SUB, "*": MUL}
+		realOps := map[string]Opcode{"+": ADR, "-": SUR, "*": MUR}
+		if isString && n.Operator == "+" {
+			widen()
+			g.emit(CAT, 0, 0)
+			return milestone3.TypeString
+		}
+		if isReal {
+			widen()
+			g.emit(realOps[n.Operator], 0, 0)
+			return milestone3.TypeReal
+		}
+		g.emit(intOps[n.Operator], 0, 0)
+		return milestone3.TypeInteger
+	case "=", "<>", "<", ">", "<=", ">=":
+		intOps := map[string]Opcode{"=": EQL, "<>": NEQ, "<": LSS, "<=": LEQ, ">": GRT, ">=": GEQ}
+		realOps := map[string]Opcode{"=": EQR, "<>": NER, "<": LSR, "<=": LER, ">": GTR, ">=": GER}
+		stringOps := map[string]Opcode{"=": EQS, "<>": NES, "<": LTS, "<=": LES, ">": GTS, ">=": GES}
+		if isString {
+			widen()
+			g.emit(stringOps[n.Operator], 0, 0)
+		} else if isReal {
+			widen()
+			g.emit(realOps[n.Operator], 0, 0)
+		} else {
+			g.emit(intOps[n.Operator], 0, 0)
+		}
+		return milestone3.TypeBoolean
+	}
+
+	g.addError(fmt.Sprintf("Unknown operator '%s'", n.Operator))
+	return milestone3.TypeNone
+}
+
+// Convert the value at s[t-offset] from type from to type to (FLT for integer -> real, CHS for char -> string)
+func (g *CodeGenerator) convert(from, to milestone3.TypeKind, offset int) {
+	switch {
+	case to == milestone3.TypeReal && from != milestone3.TypeReal:
+		g.emit(FLT, 0, offset)
+	case to == milestone3.TypeString && from == milestone3.TypeChar:
+		g.emit(CHS, 0, offset)
+	}
+}
+
+func isComposite(typ milestone3.TypeKind) bool {
+	return typ == milestone3.TypeArray || typ == milestone3.TypeRecord
+}
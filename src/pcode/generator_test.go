@@ -0,0 +1,18 @@
+package pcode
+
+import (
+	"compiler/internal/testprogram"
+	"io"
+	"testing"
+)
+
+func TestRuntimePrograms(t *testing.T) {
+	testprogram.RunCases(t, func(p *testprogram.Program, input io.Reader, output io.Writer) error {
+		symbols := p.Analyzer.GetSymbolTable()
+		program, err := NewCodeGenerator(symbols).Generate(p.Decorated)
+		if err != nil {
+			return err
+		}
+		return NewMachine(program, symbols, input, output).Run()
+	})
+}
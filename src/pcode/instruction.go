@@ -0,0 +1,133 @@
+package pcode
+
+import (
+	"fmt"
+	"io"
+)
+
+// Opcode - P-code operation (numbering follows Wirth's Pascal-S)
+type Opcode int
+
+const (
+	LDA Opcode = 0  // Load address: push display[x] + y
+	LOD Opcode = 1  // Load value: push s[display[x] + y]
+	LDI Opcode = 2  // Load indirect: push s[s[display[x] + y]]
+	DIS Opcode = 3  // Update display after returning to a deeper level (x = callee level, y = current level)
+	SFN Opcode = 8  // Standard function y (milestone3.StdFunc) applied to the top
+	INC Opcode = 9  // Add y to the address on top (record field offset)
+	JMP Opcode = 10 // Unconditional jump to y
+	JPC Opcode = 11 // Jump to y if top is false (pops)
+	SWT Opcode = 12 // Switch: pop selector, search case table at y
+	CAS Opcode = 13 // Case table entry for label y (followed by JMP to its arm)
+	F1U Opcode = 14 // For loop entry (ke)
+	F2U Opcode = 15 // For loop step (ke)
+	F1D Opcode = 16 // For loop entry (turun_ke)
+	F2D Opcode = 17 // For loop step (turun_ke)
+	MKS Opcode = 18 // Mark stack: reserve frame header for call to TAB index y
+	CAL Opcode = 19 // Call: y = number of words above the frame base (4 + parameter size)
+	IDX Opcode = 20 // Index with element size 1 (y = ATAB index)
+	IXX Opcode = 21 // Index with element size ATAB[y].Elsz
+	LDB Opcode = 22 // Load block of y words from address on top
+	CPB Opcode = 23 // Copy block of y words: s[t-1] <- s[t]
+	LDC Opcode = 24 // Load constant y
+	LDR Opcode = 25 // Load real constant REALS[y]
+	FLT Opcode = 26 // Convert integer at s[t-y] to real
+	RED Opcode = 27 // Read value of type y into address on top
+	WRS Opcode = 28 // Write string STRINGS[y:y+x]
+	WRW Opcode = 29 // Write value of type y
+	HLT Opcode = 31 // Halt
+	EXP Opcode = 32 // Exit procedure
+	EXF Opcode = 33 // Exit function (result stays on top)
+	LDT Opcode = 34 // Load top indirect: s[t] <- s[s[t]]
+	NOT Opcode = 35 // Boolean not
+	MUS Opcode = 36 // Negate (y = 1 for real)
+	STO Opcode = 38 // Store: s[s[t-1]] <- s[t]
+	EQR Opcode = 39 // Real =
+	NER Opcode = 40 // Real <>
+	LSR Opcode = 41 // Real <
+	LER Opcode = 42 // Real <=
+	GTR Opcode = 43 // Real >
+	GER Opcode = 44 // Real >=
+	EQL Opcode = 45 // Integer =
+	NEQ Opcode = 46 // Integer <>
+	LSS Opcode = 47 // Integer <
+	LEQ Opcode = 48 // Integer <=
+	GRT Opcode = 49 // Integer >
+	GEQ Opcode = 50 // Integer >=
+	ORR Opcode = 51 // Boolean or
+	ADD Opcode = 52 // Integer +
+	SUB Opcode = 53 // Integer -
+	ADR Opcode = 54 // Real +
+	SUR Opcode = 55 // Real -
+	AND Opcode = 56 // Boolean and
+	MUL Opcode = 57 // Integer *
+	DIV Opcode = 58 // Integer bagi
+	MOD Opcode = 59 // Integer mod
+	MUR Opcode = 60 // Real *
+	DIR Opcode = 61 // Real /
+	RDL Opcode = 62 // Skip rest of input line (readln)
+	WRL Opcode = 63 // Write newline (writeln)
+
+	// String extension (not in Pascal-S)
+	LDS Opcode = 64 // Load string constant STRINGS[y:y+x]
+	CAT Opcode = 65 // String concatenation
+	CHS Opcode = 66 // Convert char at s[t-y] to string
+	EQS Opcode = 67 // String =
+	NES Opcode = 68 // String <>
+	LTS Opcode = 69 // String <
+	LES Opcode = 70 // String <=
+	GTS Opcode = 71 // String >
+	GES Opcode = 72 // String >=
+
+	// Range check extension (not in Pascal-S)
+	CHK Opcode = 73 // Check x <= s[t] <= y (value stays on top)
+	CHF Opcode = 74 // Check x <= s[t-1] <= y and x <= s[t] <= y (for loop start and end after F1U/F1D)
+	RDV Opcode = 75 // Read value of type y and push it (checked read: RDV, CHK, STO)
+)
+
+var mnemonics = map[Opcode]string{
+	LDA: "LDA", LOD: "LOD", LDI: "LDI", DIS: "DIS", SFN: "SFN", INC: "INC", JMP: "JMP", JPC: "JPC", SWT: "SWT", CAS: "CAS",
+	F1U: "F1U", F2U: "F2U", F1D: "F1D", F2D: "F2D", MKS: "MKS", CAL: "CAL",
+	IDX: "IDX", IXX: "IXX", LDB: "LDB", CPB: "CPB", LDC: "LDC", LDR: "LDR",
+	FLT: "FLT", RED: "RED", WRS: "WRS", WRW: "WRW", HLT: "HLT", EXP: "EXP",
+	EXF: "EXF", LDT: "LDT", NOT: "NOT", MUS: "MUS", STO: "STO",
+	EQR: "EQR", NER: "NER", LSR: "LSR", LER: "LER", GTR: "GTR", GER: "GER",
+	EQL: "EQL", NEQ: "NEQ", LSS: "LSS", LEQ: "LEQ", GRT: "GRT", GEQ: "GEQ",
+	ORR: "ORR", ADD: "ADD", SUB: "SUB", ADR: "ADR", SUR: "SUR", AND: "AND",
+	MUL: "MUL", DIV: "DIV", MOD: "MOD", MUR: "MUR", DIR: "DIR", RDL: "RDL", WRL: "WRL",
+	LDS: "LDS", CAT: "CAT", CHS: "CHS", EQS: "EQS", NES: "NES", LTS: "LTS", LES: "LES", GTS: "GTS", GES: "GES",
+	CHK: "CHK", CHF: "CHF", RDV: "RDV",
+}
+
+func (op Opcode) String() string {
+	if name, ok := mnemonics[op]; ok {
+		return name
+	}
+	return fmt.Sprintf("OP%d", int(op))
+}
+
+// Instruction - single P-code instruction (f x, y)
+type Instruction struct {
+	Op Opcode
+	X  int
+	Y  int
+}
+
+func (ins Instruction) String() string {
+	return fmt.Sprintf("%-4s %d, %d", ins.Op, ins.X, ins.Y)
+}
+
+// Program - result of code generation
+type Program struct {
+	Code    []Instruction
+	Strings string    // String table (WRS/LDS operands point into it)
+	Reals   []float64 // Real constant table (LDR operands)
+	Block   int       // BTAB index of the main program block
+}
+
+// PrintCode writes a numbered listing of the generated code
+func (p *Program) PrintCode(writer io.Writer) {
+	for pc, ins := range p.Code {
+		fmt.Fprintf(writer, "%4d  %s\n", pc, ins)
+	}
+}
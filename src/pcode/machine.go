@@ -0,0 +1,625 @@
+package pcode
+
+import (
+	"bufio"
+	"compiler/milestone3"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Number of cells in the run-time stack
+const stackSize = 100000
+
+// Size of the frame header: function result, return address, static link, dynamic link, TAB index
+const frameHeaderSize = 5
+
+// Cell - single stack cell (integers, booleans, chars and addresses use I; reals use R)
+type Cell struct {
+	I int
+	R float64
+	S string
+}
+
+// Machine interprets P-code on a single run-time stack (Wirth's Pascal-S interpreter)
+//
+// Frame layout relative to the base b:
+//
+//	b+0  function result
+//	b+1  return address
+//	b+2  static link (base of the enclosing frame)
+//	b+3  dynamic link (base of the caller's frame); holds the frame size until CAL
+//	b+4  TAB index of the called subprogram
+//	b+5  parameters, then local variables
+type Machine struct {
+	program  *Program
+	symTable *milestone3.SymbolTable
+	in       *bufio.Reader
+	out      *bufio.Writer
+	s        []Cell
+	display  []int
+	pc       int // Program counter
+	b        int // Base of the current frame
+	t        int // Top of stack
+}
+
+// RuntimeError - error raised while executing P-code
+type RuntimeError struct {
+	PC      int
+	Message string
+}
+
+func (e *RuntimeError) Error() string {
+	return fmt.Sprintf("%s (pc = %d)", e.Message, e.PC)
+}
+
+// Create new machine
+func NewMachine(program *Program, symTable *milestone3.SymbolTable, in io.Reader, out io.Writer) *Machine {
+	return &Machine{
+		program:  program,
+		symTable: symTable,
+		in:       bufio.NewReader(in),
+		out:      bufio.NewWriter(out),
+		s:        make([]Cell, stackSize),
+		display:  make([]int, len(symTable.Display)),
+	}
+}
+
+// Run executes the program until HLT and returns the first runtime error (if any)
+func (m *Machine) Run() (err error) {
+	defer func() {
+		m.out.Flush()
+		if r := recover(); r != nil {
+			if rtErr, ok := r.(*RuntimeError); ok {
+				err = rtErr
+				return
+			}
+			panic(r)
+		}
+	}()
+
+	// Global frame: block 0 at level 0
+	m.pc = 0
+	m.b = 0
+	m.display[0] = 0
+	m.t = m.frameSize(m.program.Block) - 1
+	if m.t >= stackSize {
+		m.fail("stack overflow")
+	}
+
+	for {
+		if m.pc < 0 || m.pc >= len(m.program.Code) {
+			m.fail("program counter out of range")
+		}
+		ins := m.program.Code[m.pc]
+		m.pc++
+		if ins.Op == HLT {
+			return nil
+		}
+		m.execute(ins)
+	}
+}
+
+// Abort execution with a runtime error at the current instruction
+func (m *Machine) fail(format string, args ...interface{}) {
+	panic(&RuntimeError{PC: m.pc - 1, Message: fmt.Sprintf(format, args...)})
+}
+
+// Frame size of a block: header + parameters + local variables
+func (m *Machine) frameSize(block int) int {
+	btab, err := m.symTable.GetBlockEntry(block)
+	if err != nil {
+		m.fail("invalid block %d", block)
+	}
+	return frameHeaderSize + btab.Psze + btab.Vsze
+}
+
+func (m *Machine) push(c Cell) {
+	m.t++
+	if m.t >= stackSize {
+		m.fail("stack overflow")
+	}
+	m.s[m.t] = c
+}
+
+func (m *Machine) pop() Cell {
+	c := m.s[m.t]
+	m.t--
+	return c
+}
+
+// Check that a computed address points into the stack
+func (m *Machine) checkAddress(addr int) int {
+	if addr < 0 || addr >= stackSize {
+		m.fail("invalid address %d", addr)
+	}
+	return addr
+}
+
+func (m *Machine) execute(ins Instruction) {
+	switch ins.Op {
+	case LDA:
+		m.push(Cell{I: m.display[ins.X] + ins.Y})
+	case LOD:
+		m.push(m.s[m.display[ins.X]+ins.Y])
+	case LDI:
+		m.push(m.s[m.checkAddress(m.s[m.display[ins.X]+ins.Y].I)])
+	case DIS:
+		// Walk the static chain from the current frame to restore the display
+		h1 := ins.Y
+		h2 := ins.X
+		h3 := m.b
+		for h1 != h2 {
+			m.display[h1] = h3
+			h3 = m.s[h3+2].I
+			h1--
+		}
+	case INC:
+		m.s[m.t].I += ins.Y
+	case JMP:
+		m.pc = ins.Y
+	case JPC:
+		if m.pop().I == 0 {
+			m.pc = ins.Y
+		}
+	case SWT:
+		selector := m.pop().I
+		for at := ins.Y; ; at += 2 {
+			if m.program.Code[at].Op != CAS {
+				// End of table: JMP to selain_itu, or no default arm
+				if m.program.Code[at].Y == 0 {
+					m.fail("no case label matches selector value %d", selector)
+				}
+				m.pc = m.program.Code[at].Y
+				break
+			}
+			if m.program.Code[at].Y == selector {
+				m.pc = m.program.Code[at+1].Y
+				break
+			}
+		}
+	case F1U:
+		end := m.s[m.t].I
+		start := m.s[m.t-1].I
+		if start <= end {
+			m.s[m.checkAddress(m.s[m.t-2].I)] = Cell{I: start}
+		} else {
+			m.t -= 3
+			m.pc = ins.Y
+		}
+	case F2U:
+		addr := m.checkAddress(m.s[m.t-2].I)
+		counter := m.s[addr].I + 1
+		if counter <= m.s[m.t].I {
+			m.s[addr].I = counter
+			m.pc = ins.Y
+		} else {
+			m.t -= 3
+		}
+	case F1D:
+		end := m.s[m.t].I
+		start := m.s[m.t-1].I
+		if start >= end {
+			m.s[m.checkAddress(m.s[m.t-2].I)] = Cell{I: start}
+		} else {
+			m.t -= 3
+			m.pc = ins.Y
+		}
+	case F2D:
+		addr := m.checkAddress(m.s[m.t-2].I)
+		counter := m.s[addr].I - 1
+		if counter >= m.s[m.t].I {
+			m.s[addr].I = counter
+			m.pc = ins.Y
+		} else {
+			m.t -= 3
+		}
+	case MKS:
+		entry, err := m.symTable.GetEntry(ins.Y)
+		if err != nil {
+			m.fail("invalid subprogram %d", ins.Y)
+		}
+		size := m.frameSize(entry.Ref)
+		if m.t+frameHeaderSize >= stackSize {
+			m.fail("stack overflow")
+		}
+		m.t += frameHeaderSize
+		m.s[m.t-1] = Cell{I: size - 1}
+		m.s[m.t] = Cell{I: ins.Y}
+	case CAL:
+		h1 := m.t - ins.Y // Base of the new frame
+		entry, err := m.symTable.GetEntry(m.s[h1+4].I)
+		if err != nil {
+			m.fail("invalid subprogram %d", m.s[h1+4].I)
+		}
+		level := entry.Lev + 1
+		for level >= len(m.display) {
+			m.display = append(m.display, 0)
+		}
+		top := h1 + m.s[h1+3].I
+		if top >= stackSize {
+			m.fail("stack overflow in call to '%s'", entry.Identifier)
+		}
+		m.display[level] = h1
+		m.s[h1+1] = Cell{I: m.pc}
+		m.s[h1+2] = Cell{I: m.display[level-1]}
+		m.s[h1+3] = Cell{I: m.b}
+		for i := m.t + 1; i <= top; i++ {
+			m.s[i] = Cell{}
+		}
+		m.b = h1
+		m.t = top
+		m.pc = entry.Adr
+	case IDX, IXX:
+		atab, err := m.symTable.GetArrayEntry(ins.Y)
+		if err != nil {
+			m.fail("invalid array %d", ins.Y)
+		}
+		index := m.pop().I
+		if index < atab.Low || index > atab.High {
+			m.fail("index %d out of range [%d..%d]", index, atab.Low, atab.High)
+		}
+		elsz := 1
+		if ins.Op == IXX {
+			elsz = atab.Elsz
+		}
+		m.s[m.t].I += (index - atab.Low) * elsz
+	case LDB:
+		addr := m.checkAddress(m.pop().I)
+		if m.t+ins.Y >= stackSize {
+			m.fail("stack overflow")
+		}
+		for i := 0; i < ins.Y; i++ {
+			m.t++
+			m.s[m.t] = m.s[addr+i]
+		}
+	case CPB:
+		src := m.checkAddress(m.s[m.t].I)
+		dst := m.checkAddress(m.s[m.t-1].I)
+		copy(m.s[dst:dst+ins.Y], m.s[src:src+ins.Y])
+		m.t -= 2
+	case LDC:
+		m.push(Cell{I: ins.Y})
+	case LDR:
+		m.push(Cell{R: m.program.Reals[ins.Y]})
+	case SFN:
+		m.standardFunction(milestone3.StdFunc(ins.Y))
+	case CHK:
+		if value := m.s[m.t].I; value < ins.X || value > ins.Y {
+			m.fail("value %d out of range [%d..%d]", value, ins.X, ins.Y)
+		}
+	case CHF:
+		for _, value := range []int{m.s[m.t-1].I, m.s[m.t].I} {
+			if value < ins.X || value > ins.Y {
+				m.fail("for loop value %d out of range [%d..%d]", value, ins.X, ins.Y)
+			}
+		}
+	case FLT:
+		m.s[m.t-ins.Y] = Cell{R: float64(m.s[m.t-ins.Y].I)}
+	case RED:
+		m.out.Flush()
+		addr := m.checkAddress(m.pop().I)
+		m.s[addr] = m.readValue(milestone3.TypeKind(ins.Y))
+	case RDV:
+		m.out.Flush()
+		m.push(m.readValue(milestone3.TypeKind(ins.Y)))
+	case WRS:
+		m.out.WriteString(m.program.Strings[ins.Y : ins.Y+ins.X])
+	case WRW:
+		m.out.WriteString(formatCell(m.pop(), milestone3.TypeKind(ins.Y)))
+	case EXP:
+		m.t = m.b - 1
+		m.pc = m.s[m.b+1].I
+		m.b = m.s[m.b+3].I
+	case EXF:
+		m.t = m.b
+		m.pc = m.s[m.b+1].I
+		m.b = m.s[m.b+3].I
+	case LDT:
+		m.s[m.t] = m.s[m.checkAddress(m.s[m.t].I)]
+	case NOT:
+		m.s[m.t].I = boolInt(m.s[m.t].I == 0)
+	case MUS:
+		if ins.Y == 1 {
+			m.s[m.t].R = -m.s[m.t].R
+		} else {
+			m.s[m.t].I = -m.s[m.t].I
+		}
+	case STO:
+		value := m.pop()
+		addr := m.checkAddress(m.pop().I)
+		m.s[addr] = value
+	case EQR, NER, LSR, LER, GTR, GER:
+		right := m.pop().R
+		left := m.s[m.t].R
+		var result bool
+		switch ins.Op {
+		case EQR:
+			result = left == right
+		case NER:
+			result = left != right
+		case LSR:
+			result = left < right
+		case LER:
+			result = left <= right
+		case GTR:
+			result = left > right
+		default:
+			result = left >= right
+		}
+		m.s[m.t] = Cell{I: boolInt(result)}
+	case EQL, NEQ, LSS, LEQ, GRT, GEQ:
+		right := m.pop().I
+		left := m.s[m.t].I
+		var result bool
+		switch ins.Op {
+		case EQL:
+			result = left == right
+		case NEQ:
+			result = left != right
+		case LSS:
+			result = left < right
+		case LEQ:
+			result = left <= right
+		case GRT:
+			result = left > right
+		default:
+			result = left >= right
+		}
+		m.s[m.t] = Cell{I: boolInt(result)}
+	case EQS, NES, LTS, LES, GTS, GES:
+		right := m.pop().S
+		cmp := strings.Compare(m.s[m.t].S, right)
+		var result bool
+		switch ins.Op {
+		case EQS:
+			result = cmp == 0
+		case NES:
+			result = cmp != 0
+		case LTS:
+			result = cmp < 0
+		case LES:
+			result = cmp <= 0
+		case GTS:
+			result = cmp > 0
+		default:
+			result = cmp >= 0
+		}
+		m.s[m.t] = Cell{I: boolInt(result)}
+	case LDS:
+		m.push(Cell{S: m.program.Strings[ins.Y : ins.Y+ins.X]})
+	case CAT:
+		right := m.pop().S
+		m.s[m.t].S += right
+	case CHS:
+		m.s[m.t-ins.Y] = Cell{S: string(rune(m.s[m.t-ins.Y].I))}
+	case ORR:
+		right := m.pop().I
+		m.s[m.t].I = boolInt(m.s[m.t].I != 0 || right != 0)
+	case AND:
+		right := m.pop().I
+		m.s[m.t].I = boolInt(m.s[m.t].I != 0 && right != 0)
+	case ADD:
+		right := m.pop().I
+		m.s[m.t].I += right
+	case SUB:
+		right := m.pop().I
+		m.s[m.t].I -= right
+	case MUL:
+		right := m.pop().I
+		m.s[m.t].I *= right
+	case DIV, MOD:
+		right := m.pop().I
+		if right == 0 {
+			m.fail("division by zero")
+		}
+		if ins.Op == DIV {
+			m.s[m.t].I /= right
+		} else {
+			m.s[m.t].I %= right
+		}
+	case ADR:
+		right := m.pop().R
+		m.s[m.t].R += right
+	case SUR:
+		right := m.pop().R
+		m.s[m.t].R -= right
+	case MUR:
+		right := m.pop().R
+		m.s[m.t].R *= right
+	case DIR:
+		right := m.pop().R
+		if right == 0 {
+			m.fail("division by zero")
+		}
+		m.s[m.t].R /= right
+	case RDL:
+		m.skipLine()
+	case WRL:
+		m.out.WriteString("\n")
+	default:
+		m.fail("illegal instruction %s", ins)
+	}
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Text representation used by WRW (same as the tree-walking interpreter)
+func formatCell(c Cell, typ milestone3.TypeKind) string {
+	switch typ {
+	case milestone3.TypeReal:
+		return strconv.FormatFloat(c.R, 'f', -1, 64)
+	case milestone3.TypeBoolean:
+		if c.I != 0 {
+			return "true"
+		}
+		return "false"
+	case milestone3.TypeChar:
+		return string(rune(c.I))
+	case milestone3.TypeString:
+		return c.S
+	default:
+		return strconv.Itoa(c.I)
+	}
+}
+
+// Read one value of the given type from input
+func (m *Machine) readValue(typ milestone3.TypeKind) Cell {
+	switch typ {
+	case milestone3.TypeChar:
+		b, err := m.in.ReadByte()
+		if err != nil {
+			m.fail("unexpected end of input")
+		}
+		if b == '\r' || b == '\n' {
+			// End of line is read as a blank, like standard Pascal
+			if b == '\r' {
+				m.in.ReadByte()
+			}
+			b = ' '
+		}
+		return Cell{I: int(b)}
+	case milestone3.TypeInteger:
+		word := m.readWord()
+		value, err := strconv.Atoi(word)
+		if err != nil {
+			m.fail("invalid integer input '%s'", word)
+		}
+		return Cell{I: value}
+	case milestone3.TypeReal:
+		word := m.readWord()
+		value, err := strconv.ParseFloat(word, 64)
+		if err != nil {
+			m.fail("invalid real input '%s'", word)
+		}
+		return Cell{R: value}
+	case milestone3.TypeString:
+		return Cell{S: m.readRestOfLine()}
+	}
+
+	m.fail("cannot read a value of type %s", typ)
+	return Cell{}
+}
+
+// Read the characters up to (not including) the end of the current line
+func (m *Machine) readRestOfLine() string {
+	var sb strings.Builder
+	for {
+		b, err := m.in.ReadByte()
+		if err != nil {
+			break
+		}
+		if b == '\r' || b == '\n' {
+			m.in.UnreadByte()
+			break
+		}
+		sb.WriteByte(b)
+	}
+	return sb.String()
+}
+
+// Skip leading whitespace and read the next whitespace-delimited word
+func (m *Machine) readWord() string {
+	var sb strings.Builder
+	for {
+		b, err := m.in.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			m.fail("error reading input: %v", err)
+		}
+		if unicode.IsSpace(rune(b)) {
+			if sb.Len() == 0 {
+				continue
+			}
+			m.in.UnreadByte()
+			break
+		}
+		sb.WriteByte(b)
+	}
+	if sb.Len() == 0 {
+		m.fail("unexpected end of input")
+	}
+	return sb.String()
+}
+
+// Discard the rest of the current input line (readln)
+func (m *Machine) skipLine() {
+	for {
+		b, err := m.in.ReadByte()
+		if err != nil || b == '\n' {
+			return
+		}
+	}
+}
+
+// Apply a standard function to the top of the stack (eof/eoln push a new cell)
+func (m *Machine) standardFunction(fn milestone3.StdFunc) {
+	switch fn {
+	case milestone3.StdEof:
+		_, err := m.in.Peek(1)
+		m.push(Cell{I: boolInt(err != nil)})
+		return
+	case milestone3.StdEoln:
+		b, err := m.in.Peek(1)
+		m.push(Cell{I: boolInt(err != nil || b[0] == '\r' || b[0] == '\n')})
+		return
+	}
+
+	top := &m.s[m.t]
+	switch fn {
+	case milestone3.StdAbs:
+		if top.I < 0 {
+			top.I = -top.I
+		}
+	case milestone3.StdAbsReal:
+		top.R = math.Abs(top.R)
+	case milestone3.StdSqr:
+		top.I *= top.I
+	case milestone3.StdSqrReal:
+		top.R *= top.R
+	case milestone3.StdOdd:
+		top.I = boolInt(top.I%2 != 0)
+	case milestone3.StdChr:
+		if top.I < 0 || top.I > 255 {
+			m.fail("chr(%d): character code out of range [0..255]", top.I)
+		}
+	case milestone3.StdOrd:
+		// Ordinal values are already stored as integers
+	case milestone3.StdSucc:
+		top.I++
+	case milestone3.StdPred:
+		top.I--
+	case milestone3.StdRound:
+		*top = Cell{I: int(math.Round(top.R))}
+	case milestone3.StdTrunc:
+		*top = Cell{I: int(top.R)}
+	case milestone3.StdSin:
+		top.R = math.Sin(top.R)
+	case milestone3.StdCos:
+		top.R = math.Cos(top.R)
+	case milestone3.StdExp:
+		top.R = math.Exp(top.R)
+	case milestone3.StdLn:
+		if top.R <= 0 {
+			m.fail("ln(%s): argument must be positive", formatCell(*top, milestone3.TypeReal))
+		}
+		top.R = math.Log(top.R)
+	case milestone3.StdSqrt:
+		if top.R < 0 {
+			m.fail("sqrt(%s): argument must not be negative", formatCell(*top, milestone3.TypeReal))
+		}
+		top.R = math.Sqrt(top.R)
+	default:
+		m.fail("unknown standard function %d", int(fn))
+	}
+}
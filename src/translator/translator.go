@@ -0,0 +1,431 @@
+package translator
+
+import (
+	"compiler/diagnostic"
+	"compiler/milestone1"
+	"compiler/milestone2"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Source-to-source translation from Pascal-S (any keyword dialect) to ISO Pascal.
+// The original source is copied unchanged except for the tokens that have to be rewritten,
+// so identifiers, layout and comments survive the translation:
+//   - keywords and word operators get their English spelling (mulai -> begin, bagi -> div)
+//   - the program header gets the (input, output) program parameters
+//   - a case statement with a default branch is guarded by an if statement over its labels
+//   - enumeration values passed to write/writeln are written as ord(...), as Pascal-S prints them
+//   - empty parameter lists '()' are removed from declarations and calls
+//   - constant expressions in konstanta/array bounds/subranges are replaced by their folded value
+//   - identifiers that are reserved words in standard Pascal are renamed
+//
+// Programs that cannot be expressed in ISO Pascal (the string type, a default branch whose
+// selector calls a function) are not translated; see Diagnostics.
+
+// ErrNotStandard is returned by Translate when the program uses constructs without an ISO Pascal equivalent
+var ErrNotStandard = errors.New("program cannot be translated to ISO Pascal")
+
+// Analysis gives the translator what it needs from semantic analysis
+// (implemented by milestone3.SemanticAnalyzer after Analyze)
+type Analysis interface {
+	// Value of a constant expression as Pascal source text
+	ConstantValue(node *milestone2.AbstractSyntaxTree) (string, bool)
+	// Whether a write/writeln argument has an enumeration type
+	EnumArgument(node *milestone2.AbstractSyntaxTree) bool
+}
+
+// Reserved words of ISO Pascal plus the Turbo/Free Pascal ones that cannot be used as identifiers
+var reservedWords = map[string]bool{
+	"and": true, "array": true, "begin": true, "case": true, "const": true, "div": true,
+	"do": true, "downto": true, "else": true, "end": true, "file": true, "for": true,
+	"function": true, "goto": true, "if": true, "in": true, "label": true, "mod": true,
+	"nil": true, "not": true, "of": true, "or": true, "otherwise": true, "packed": true,
+	"procedure": true, "program": true, "record": true, "repeat": true, "set": true,
+	"then": true, "to": true, "type": true, "until": true, "var": true, "while": true, "with": true,
+	"asm": true, "constructor": true, "destructor": true, "implementation": true, "inherited": true,
+	"inline": true, "interface": true, "object": true, "operator": true, "shl": true, "shr": true,
+	"string": true, "unit": true, "uses": true, "xor": true,
+}
+
+// Replacement of source bytes [start, end) by text (start == end inserts)
+type edit struct {
+	start, end int
+	text       string
+}
+
+// Translator rewrites one source file; tokens must come from lexing that source
+type Translator struct {
+	source      []byte
+	lineStart   []int // Byte offset of every line (index 0 = line 1)
+	tokens      map[milestone1.Position]milestone1.Token
+	analysis    Analysis
+	names       map[string]bool   // Every identifier in the source (lower case)
+	renames     map[string]string // Reserved identifier (lower case) -> new name
+	functions   map[string]bool   // Functions declared by the program (lower case)
+	edits       []edit            // Structural edits (see visit)
+	tokenEdits  []edit            // Keyword spelling and renames (see translateTokens)
+	diagnostics []diagnostic.Diagnostic
+}
+
+// NewTranslator prepares a translation; analysis may be nil (constant expressions are then kept
+// and enumeration values are written unchanged)
+func NewTranslator(source []byte, tokens []milestone1.Token, analysis Analysis) *Translator {
+	t := &Translator{
+		source:    source,
+		lineStart: []int{0},
+		tokens:    make(map[milestone1.Position]milestone1.Token),
+		analysis:  analysis,
+		names:     make(map[string]bool),
+		renames:   make(map[string]string),
+	}
+	for i, c := range source {
+		if c == '\n' {
+			t.lineStart = append(t.lineStart, i+1)
+		}
+	}
+	for _, token := range tokens {
+		if token.Kind == milestone1.KindComment {
+			continue
+		}
+		t.tokens[token.Pos] = token
+		if token.Kind == milestone1.KindIdentifier {
+			t.names[strings.ToLower(token.Lexeme)] = true
+		}
+	}
+	return t
+}
+
+// Translate writes tree (parsed from the translator's source) as ISO Pascal.
+// Nothing is written if the program cannot be expressed in ISO Pascal: the error is then
+// ErrNotStandard and Diagnostics tells why.
+func (t *Translator) Translate(w io.Writer, tree *milestone2.AbstractSyntaxTree) error {
+	t.edits = nil
+	t.tokenEdits = nil
+	t.diagnostics = nil
+	t.functions = make(map[string]bool)
+	t.collectFunctions(tree)
+	t.translateTokens() // Needed by visit to copy translated expressions
+	t.visit(tree)
+	if len(t.diagnostics) > 0 {
+		return ErrNotStandard
+	}
+	// Structural edits first: they win over token edits of the same span
+	edits := append(t.edits, t.tokenEdits...)
+	_, err := io.WriteString(w, t.render(edits, 0, len(t.source)))
+	return err
+}
+
+// Diagnostics - constructs of the last Translate call that have no ISO Pascal equivalent
+func (t *Translator) Diagnostics() []diagnostic.Diagnostic {
+	return t.diagnostics
+}
+
+func (t *Translator) report(node *milestone2.AbstractSyntaxTree, format string, args ...interface{}) {
+	t.diagnostics = append(t.diagnostics, *diagnostic.Errorf(diagnostic.NotStandardPascal, node.Span(), format, args...))
+}
+
+// Keyword spelling and reserved identifiers, token by token
+func (t *Translator) translateTokens() {
+	for _, token := range t.tokens {
+		text := token.Lexeme
+		switch {
+		case token.Keyword != "":
+			text = milestone1.English.Spell(token.Keyword)
+		case token.Kind == milestone1.KindIdentifier && reservedWords[strings.ToLower(token.Lexeme)]:
+			text = t.rename(token.Lexeme)
+		}
+		if !strings.EqualFold(text, token.Lexeme) {
+			start, end := t.tokenSpan(token)
+			t.tokenEdits = append(t.tokenEdits, edit{start, end, text})
+		}
+	}
+}
+
+// New name for an identifier that is reserved in standard Pascal: first free name+1, name+2, ...
+func (t *Translator) rename(name string) string {
+	key := strings.ToLower(name)
+	if renamed, ok := t.renames[key]; ok {
+		return renamed
+	}
+	for i := 1; ; i++ {
+		candidate := name + strconv.Itoa(i)
+		lower := strings.ToLower(candidate)
+		if !t.names[lower] && !reservedWords[lower] {
+			t.names[lower] = true
+			t.renames[key] = candidate
+			return candidate
+		}
+	}
+}
+
+// Structural rewrites that need the parse tree
+func (t *Translator) visit(node *milestone2.AbstractSyntaxTree) {
+	if node == nil {
+		return
+	}
+	switch node.Kind {
+	case milestone2.NodeProgramHeader:
+		// program Name; -> program Name(input, output);
+		if len(node.Children) >= 2 {
+			if _, end, ok := t.span(node.Children[1]); ok {
+				t.edits = append(t.edits, edit{end, end, "(input, output)"})
+			}
+		}
+
+	case milestone2.NodeToken:
+		if node.IsKeyword(milestone1.KwString) {
+			t.report(node, "type '%s' has no ISO Pascal equivalent; use a packed array of char instead", node.Text)
+		}
+
+	case milestone2.NodeCaseStatement:
+		t.guardCase(node)
+
+	case milestone2.NodeProcedureCall:
+		t.removeEmptyParentheses(node)
+		t.writeOrdinals(node)
+
+	case milestone2.NodeSubprogramDeclaration, milestone2.NodeFunctionCall:
+		t.removeEmptyParentheses(node)
+
+	case milestone2.NodeConstDef:
+		if len(node.Children) >= 3 {
+			t.foldConstant(node.Children[2])
+		}
+
+	case milestone2.NodeArrayType, milestone2.NodeSubrangeType:
+		for _, child := range node.Children {
+			if child.Kind == milestone2.NodeExpression {
+				t.foldConstant(child)
+			}
+		}
+	}
+
+	for _, child := range node.Children {
+		t.visit(child)
+	}
+}
+
+// ISO Pascal has no default branch in case, and a selector without a matching label is an error:
+// 'kasus e dari l1: s1; ... selain_itu s selesai' becomes
+// 'if (e = l1) or ... then case e of l1: s1; ... end else begin s end'
+func (t *Translator) guardCase(node *milestone2.AbstractSyntaxTree) {
+	var elseKw *milestone2.AbstractSyntaxTree
+	var labels []*milestone2.AbstractSyntaxTree
+	for _, child := range node.Children {
+		switch {
+		case child.IsKeyword(milestone1.KwElse):
+			elseKw = child
+		case child.Kind == milestone2.NodeCaseElement && len(child.Children) > 0:
+			for _, label := range child.Children[0].Children {
+				if label.Kind == milestone2.NodeCaseLabel {
+					labels = append(labels, label)
+				}
+			}
+		}
+	}
+	if elseKw == nil || len(node.Children) < 2 {
+		return
+	}
+
+	// The guard evaluates the selector a second time
+	selector := node.Children[1]
+	if name, ok := t.callsFunction(selector); ok {
+		t.report(selector, "case selector calls function '%s'; the default branch needs the selector twice in ISO Pascal, assign it to a variable first", name)
+		return
+	}
+	selectorText, ok := t.translated(selector)
+	if !ok {
+		return
+	}
+	var leaves []*milestone2.AbstractSyntaxTree
+	collectLeaves(selector, &leaves)
+	if len(leaves) > 1 {
+		selectorText = "(" + selectorText + ")"
+	}
+	tests := make([]string, 0, len(labels))
+	for _, label := range labels {
+		labelText, ok := t.translated(label)
+		if !ok {
+			return
+		}
+		tests = append(tests, fmt.Sprintf("(%s = %s)", selectorText, labelText))
+	}
+
+	caseStart, _, ok := t.span(node)
+	elseStart, elseEnd, ok2 := t.span(elseKw)
+	if !ok || !ok2 {
+		return
+	}
+	t.edits = append(t.edits,
+		edit{caseStart, caseStart, "if " + strings.Join(tests, " or ") + " then "},
+		edit{elseStart, elseEnd, "end else begin"}) // The closing 'selesai' ends the begin
+}
+
+// Name of a function declared by the program that expr calls (with or without parentheses)
+func (t *Translator) callsFunction(expr *milestone2.AbstractSyntaxTree) (string, bool) {
+	var leaves []*milestone2.AbstractSyntaxTree
+	collectLeaves(expr, &leaves)
+	for _, leaf := range leaves {
+		if leaf.IsToken(milestone1.KindIdentifier) && t.functions[strings.ToLower(leaf.Text)] {
+			return leaf.Text, true
+		}
+	}
+	return "", false
+}
+
+func (t *Translator) collectFunctions(node *milestone2.AbstractSyntaxTree) {
+	if node == nil {
+		return
+	}
+	if node.Kind == milestone2.NodeSubprogramDeclaration {
+		for i, child := range node.Children {
+			if child.IsKeyword(milestone1.KwFunction) && i+1 < len(node.Children) && node.Children[i+1].IsToken(milestone1.KindIdentifier) {
+				t.functions[strings.ToLower(node.Children[i+1].Text)] = true
+			}
+		}
+	}
+	for _, child := range node.Children {
+		t.collectFunctions(child)
+	}
+}
+
+// Pascal-S writes an enumeration value as its ordinal; ISO Pascal cannot write it at all
+func (t *Translator) writeOrdinals(node *milestone2.AbstractSyntaxTree) {
+	if t.analysis == nil {
+		return
+	}
+	for _, child := range node.Children {
+		if child.Kind != milestone2.NodeParameterList {
+			continue
+		}
+		for _, arg := range child.Children {
+			if arg.Kind != milestone2.NodeExpression || !t.analysis.EnumArgument(arg) {
+				continue
+			}
+			if start, end, ok := t.span(arg); ok {
+				t.edits = append(t.edits, edit{start, start, "ord("}, edit{end, end, ")"})
+			}
+		}
+	}
+}
+
+// '(' directly followed by ')' is not allowed in standard Pascal
+func (t *Translator) removeEmptyParentheses(node *milestone2.AbstractSyntaxTree) {
+	for i := 0; i+1 < len(node.Children); i++ {
+		if !node.Children[i].IsToken(milestone1.KindLParenthesis) || !node.Children[i+1].IsToken(milestone1.KindRParenthesis) {
+			continue
+		}
+		start, _, ok := t.span(node.Children[i])
+		_, end, ok2 := t.span(node.Children[i+1])
+		if ok && ok2 {
+			t.edits = append(t.edits, edit{start, end, ""})
+		}
+		return
+	}
+}
+
+// Replace a constant expression by its value unless it already is a standard Pascal constant
+func (t *Translator) foldConstant(expr *milestone2.AbstractSyntaxTree) {
+	if t.analysis == nil || isSimpleConstant(expr) {
+		return
+	}
+	value, ok := t.analysis.ConstantValue(expr)
+	if !ok {
+		return
+	}
+	if start, end, ok := t.span(expr); ok {
+		t.edits = append(t.edits, edit{start, end, value})
+	}
+}
+
+// Standard Pascal constant: [sign] (number | constant identifier) | string | true | false
+func isSimpleConstant(expr *milestone2.AbstractSyntaxTree) bool {
+	var leaves []*milestone2.AbstractSyntaxTree
+	collectLeaves(expr, &leaves)
+	if len(leaves) == 2 && (leaves[0].IsText(milestone1.KindArithmeticOperator, "+") || leaves[0].IsText(milestone1.KindArithmeticOperator, "-")) {
+		leaves = leaves[1:]
+	}
+	if len(leaves) != 1 {
+		return false
+	}
+	leaf := leaves[0]
+	switch leaf.Token {
+	case milestone1.KindNumber, milestone1.KindReal, milestone1.KindIdentifier, milestone1.KindCharLiteral, milestone1.KindStringLiteral:
+		return true
+	}
+	return leaf.IsKeyword(milestone1.KwTrue) || leaf.IsKeyword(milestone1.KwFalse)
+}
+
+func collectLeaves(node *milestone2.AbstractSyntaxTree, leaves *[]*milestone2.AbstractSyntaxTree) {
+	if len(node.Children) == 0 {
+		if node.Kind == milestone2.NodeToken {
+			*leaves = append(*leaves, node)
+		}
+		return
+	}
+	for _, child := range node.Children {
+		collectLeaves(child, leaves)
+	}
+}
+
+// Byte range of a node in the source: first token start to last token end
+func (t *Translator) span(node *milestone2.AbstractSyntaxTree) (start, end int, ok bool) {
+	var leaves []*milestone2.AbstractSyntaxTree
+	collectLeaves(node, &leaves)
+	if len(leaves) == 0 {
+		return 0, 0, false
+	}
+	first, ok1 := t.tokens[milestone1.Position{Line: leaves[0].Line, Column: leaves[0].Column}]
+	last, ok2 := t.tokens[milestone1.Position{Line: leaves[len(leaves)-1].Line, Column: leaves[len(leaves)-1].Column}]
+	if !ok1 || !ok2 {
+		return 0, 0, false
+	}
+	start, _ = t.tokenSpan(first)
+	_, end = t.tokenSpan(last)
+	return start, end, true
+}
+
+func (t *Translator) tokenSpan(token milestone1.Token) (start, end int) {
+	start = t.lineStart[token.Pos.Line-1] + token.Pos.Column - 1
+	return start, start + len(token.Lexeme)
+}
+
+// Source text of a node with its keywords and identifiers translated
+func (t *Translator) translated(node *milestone2.AbstractSyntaxTree) (string, bool) {
+	start, end, ok := t.span(node)
+	if !ok {
+		return "", false
+	}
+	return t.render(t.tokenEdits, start, end), true
+}
+
+// Copy source bytes [from, to) with the edits inside that range applied; insertions come before
+// a replacement at the same offset, and an edit overlapping an earlier or larger edit is dropped
+func (t *Translator) render(edits []edit, from, to int) string {
+	sort.SliceStable(edits, func(i, j int) bool {
+		a, b := edits[i], edits[j]
+		if a.start != b.start {
+			return a.start < b.start
+		}
+		if (a.start == a.end) != (b.start == b.end) {
+			return a.start == a.end
+		}
+		return a.end > b.end
+	})
+	var sb strings.Builder
+	cursor := from
+	for _, e := range edits {
+		if e.start < cursor || e.end > to {
+			continue
+		}
+		sb.Write(t.source[cursor:e.start])
+		sb.WriteString(e.text)
+		cursor = e.end
+	}
+	sb.Write(t.source[cursor:to])
+	return sb.String()
+}
@@ -0,0 +1,44 @@
+package translator
+
+import (
+	"bytes"
+	"compiler/internal/testprogram"
+	"strings"
+	"testing"
+)
+
+// Every program in test/translate translates to <name>.pas, or is rejected with the
+// diagnostics in <name>.err (one "line:column message" per line) and nothing written
+func TestTranslatePrograms(t *testing.T) {
+	for _, c := range testprogram.Cases(t, "translate") {
+		t.Run(c.Name, func(t *testing.T) {
+			p := testprogram.Compile(t, c.Source)
+			translator := NewTranslator([]byte(c.Source), p.Tokens, p.Analyzer)
+			var out bytes.Buffer
+			err := translator.Translate(&out, p.Tree)
+
+			if want, ok := c.Companion(t, ".err"); ok {
+				if err != ErrNotStandard {
+					t.Fatalf("Translate error = %v, want ErrNotStandard", err)
+				}
+				if out.Len() > 0 {
+					t.Errorf("output written for a rejected program:\n%s", out.String())
+				}
+				var messages []string
+				for _, d := range translator.Diagnostics() {
+					messages = append(messages, d.Span.Start.String()+" "+d.Message)
+				}
+				if got := strings.Join(messages, "\n"); got != strings.TrimSpace(want) {
+					t.Errorf("diagnostics:\n%s\nwant:\n%s", got, want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("translate: %v", err)
+			}
+			if want, _ := c.Companion(t, ".pas"); out.String() != want {
+				t.Errorf("translation:\n%s\nwant:\n%s", out.String(), want)
+			}
+		})
+	}
+}
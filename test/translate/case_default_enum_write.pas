@@ -0,0 +1,14 @@
+program T(input, output);
+type
+  Warna = (merah, hijau);
+var
+  set1: integer;
+  w: Warna;
+begin
+  if ((set1 div 2) = 1) or ((set1 div 2) = 2) then case set1 div 2 of
+    1, 2: w := merah;
+  end else begin
+    w := hijau
+  end;
+  writeln('w = ', ord(w))
+end.